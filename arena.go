@@ -0,0 +1,79 @@
+package encode
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// An Arena hands out the byte slices and strings made while decoding from a few large chunks,
+// rather than allocating each one separately. Parsing millions of small request-scoped messages
+// through an Arena makes many fewer, larger allocations, and Reset recycles all of them at once.
+//
+// Everything decoded through an Arena shares its lifetime: after Reset, the arena reuses its
+// chunks, so any []byte or string decoded before then must no longer be used. An Arena is not safe
+// for concurrent use.
+type Arena struct {
+	chunkSize int
+	chunks    [][]byte
+	// The index into chunks of the chunk currently being handed out from.
+	i int
+	// The unused remainder of chunks[i].
+	free []byte
+}
+
+// Returns an Arena that allocates chunkSize bytes at a time. Requests larger than a quarter of
+// chunkSize are allocated separately, so that they don't waste the rest of a chunk. Panics if
+// chunkSize is not positive.
+func NewArena(chunkSize int) *Arena {
+	if chunkSize <= 0 {
+		panic(fmt.Sprintf("invalid chunkSize=%d, must be positive", chunkSize))
+	}
+	return &Arena{chunkSize: chunkSize, i: -1}
+}
+
+// Make everything that a has handed out available for reuse.
+func (a *Arena) Reset() {
+	a.i = -1
+	a.free = nil
+}
+
+// Returns a zeroed slice of length n, whose capacity is also n so that appending to it doesn't
+// clobber whatever follows it in the chunk.
+func (a *Arena) bytes(n int) []byte {
+	if n > a.chunkSize/4 {
+		return make([]byte, n)
+	}
+	if len(a.free) < n {
+		a.i++
+		if a.i == len(a.chunks) {
+			a.chunks = append(a.chunks, make([]byte, a.chunkSize))
+		} else {
+			chunk := a.chunks[a.i]
+			for j := range chunk {
+				chunk[j] = 0
+			}
+		}
+		a.free = a.chunks[a.i]
+	}
+	b := a.free[:n:n]
+	a.free = a.free[n:]
+	return b
+}
+
+// Returns a copy of b as a string backed by a.
+func (a *Arena) string(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	c := a.bytes(len(b))
+	copy(c, b)
+	return unsafe.String(&c[0], len(c))
+}
+
+// Returns a zeroed slice of length n, from s's Arena if it has one.
+func (s *decodeState) bytes(n int) []byte {
+	if s == nil || s.arena == nil {
+		return make([]byte, n)
+	}
+	return s.arena.bytes(n)
+}
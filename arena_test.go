@@ -0,0 +1,50 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArena(t *testing.T) {
+	b := []byte("hello")
+	s := "world"
+	big := make([]byte, 40)
+	enc := func(b *[]byte, s *string, big *[]byte) Encoding {
+		return New(LengthDelimBytes(b), LengthDelimString(s), LengthDelimBytes(big))
+	}
+	buf := enc(&b, &s, &big).Encode()
+
+	a := NewArena(64)
+	d := Decoder{Arena: a}
+	var b1, b2, big1 []byte
+	var s1, s2 string
+	require.NoError(t, d.Decode(enc(&b1, &s1, &big1), buf))
+	require.NoError(t, d.Decode(enc(&b2, &s2, &big1), buf))
+	require.Equal(t, b, b1)
+	require.Equal(t, s, s1)
+	require.Equal(t, b, b2)
+	require.Equal(t, s, s2)
+	require.Equal(t, big, big1)
+	require.Len(t, a.chunks, 1)
+
+	// Doesn't alias buf, and appending doesn't clobber neighbours in the chunk.
+	buf[1] = 'j'
+	require.Equal(t, []byte("hello"), b1)
+	_ = append(b1, '!')
+	require.Equal(t, "world", s1)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, d.Decode(enc(&b1, &s1, &big1), buf))
+	}
+	require.Len(t, a.chunks, 2)
+
+	// Chunks are reused after Reset.
+	a.Reset()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, d.Decode(enc(&b1, &s1, &big1), buf))
+	}
+	require.Len(t, a.chunks, 2)
+	require.Equal(t, []byte("jello"), b1)
+	require.Equal(t, "world", s1)
+}
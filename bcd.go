@@ -0,0 +1,86 @@
+package encode
+
+import "fmt"
+
+var ErrInvalidBCD = NewInvalidValueError("encode: invalid binary-coded decimal")
+
+// Encode v, a string of exactly digits decimal digits, as packed binary-coded decimal: two digits
+// per byte, the first in the high-order nibble, taking (digits+1)/2 bytes. If digits is odd, the
+// first nibble is a zero pad, so that the number is right-justified as in ISO 8583:
+//
+//   "12345", 5
+//   01 23 45
+//
+// Panics on encode if v isn't digits decimal digits. Decode returns ErrInvalidBCD if any nibble
+// isn't a decimal digit or the pad nibble isn't zero. Panics if digits is negative.
+func BCD(v *string, digits int) Item {
+	if digits < 0 {
+		panic(fmt.Sprintf("invalid digits=%d, must be non-negative", digits))
+	}
+	return bcd{v: v, digits: digits}
+}
+
+type bcd struct {
+	v      *string
+	digits int
+}
+
+func (e bcd) Encode(buf []byte) {
+	s := *e.v
+	if len(s) != e.digits {
+		panic(fmt.Sprintf("BCD: %q has %d digits, must have %d", s, len(s), e.digits))
+	}
+	// Nibble index of the first digit, skipping the pad if there is one.
+	offset := e.digits % 2
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			panic(fmt.Sprintf("BCD: %q contains non-digit %q", s, c))
+		}
+		j := offset + i
+		if j%2 == 0 {
+			buf[j/2] = (c - '0') << 4
+		} else {
+			buf[j/2] |= c - '0'
+		}
+	}
+	if offset == 1 {
+		buf[0] &= 0x0f
+	}
+}
+func (e bcd) Size() int {
+	return (e.digits + 1) / 2
+}
+func (e bcd) kind() string {
+	return "BCD"
+}
+func (e bcd) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e bcd) fixedSize() int {
+	return e.Size()
+}
+func (e bcd) Decode(buf []byte) error {
+	size := e.Size()
+	if len(buf) < size {
+		return ErrShortBuffer
+	}
+	offset := e.digits % 2
+	if offset == 1 && buf[0]>>4 != 0 {
+		return ErrInvalidBCD
+	}
+	out := make([]byte, e.digits)
+	for i := range out {
+		j := offset + i
+		nibble := buf[j/2] >> 4
+		if j%2 == 1 {
+			nibble = buf[j/2] & 0x0f
+		}
+		if nibble > 9 {
+			return ErrInvalidBCD
+		}
+		out[i] = '0' + nibble
+	}
+	*e.v = string(out)
+	return nil
+}
@@ -0,0 +1,33 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBCD(t *testing.T) {
+	check := func(s string, digits int, expected []byte) {
+		require.Equal(t, expected, New(BCD(&s, digits)).Encode())
+		var s2 string
+		require.NoError(t, New(BCD(&s2, digits)).Decode(expected))
+		require.Equal(t, s, s2)
+	}
+	check("", 0, nil)
+	check("7", 1, []byte{0x07})
+	check("12345", 5, []byte{0x01, 0x23, 0x45})
+	check("123456", 6, []byte{0x12, 0x34, 0x56})
+	check("0099", 4, []byte{0x00, 0x99})
+
+	var s string
+	require.ErrorIs(t, New(BCD(&s, 4)).Decode([]byte{0x12}), io.ErrUnexpectedEOF)
+	require.ErrorIs(t, New(BCD(&s, 4)).Decode([]byte{0x12, 0x3a}), ErrInvalidBCD)
+	require.ErrorIs(t, New(BCD(&s, 3)).Decode([]byte{0x11, 0x23}), ErrInvalidBCD)
+
+	s = "12a4"
+	require.Panics(t, func() { New(BCD(&s, 4)).Encode() })
+	s = "123"
+	require.Panics(t, func() { New(BCD(&s, 4)).Encode() })
+	require.Panics(t, func() { BCD(&s, -1) })
+}
@@ -51,4 +51,32 @@ func TestBitbufferBasic(t *testing.T) {
 	check([]string{"1", "0", "1"})
 	check([]string{"11", "01", "10", "1"})
 	check([]string{"0100", "1", "110101", "1011", "00110000", "1"})
+	check([]string{
+		"101",
+		"1100000000000000000000000000000000000000000000000000000000000011",
+		"1",
+		"011111111111111111111111111111111111111111111111111111111110",
+	})
+}
+
+func TestBitWriterReader(t *testing.T) {
+	buf := make([]byte, 3)
+	w := NewBitWriter(buf)
+	require.NoError(t, w.WriteBits(0x5, 3))
+	require.NoError(t, w.WriteBits(0x1FFF, 13))
+	require.Equal(t, ErrBufferOverrun, w.WriteBits(0, 9))
+	require.NoError(t, w.WriteBits(0x1, 8))
+	require.Equal(t, 24, w.Len())
+	require.Equal(t, []byte{0xBF, 0xFF, 0x01}, buf)
+
+	r := NewBitReader(buf)
+	x, err := r.ReadBits(3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x5), x)
+	x, err = r.ReadBits(13)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x1FFF), x)
+	_, err = r.ReadBits(9)
+	require.Error(t, err)
+	require.Equal(t, 16, r.Len())
 }
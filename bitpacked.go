@@ -3,10 +3,9 @@ package encode
 import (
 	"errors"
 	"fmt"
-	"io"
 )
 
-var errBufferOverrun = errors.New("encode: buffer overrun")
+var ErrBufferOverrun = errors.New("encode: buffer overrun")
 
 // See Bitpacked() for usage.
 type BitpackItem interface {
@@ -47,13 +46,19 @@ func (b bitpacked) Decode(buf []byte) error {
 		}
 	}
 	if bitBuf.i != b.sizeBits() {
-		return errors.New("encode: unconsumed bytes")
+		return ErrTrailingBytes
 	}
 	return nil
 }
 func (b bitpacked) Size() int {
 	return (b.sizeBits() + 7) / 8
 }
+func (b bitpacked) kind() string {
+	return "Bitpacked"
+}
+func (b bitpacked) fixedSize() int {
+	return b.Size()
+}
 func (b bitpacked) sizeBits() int {
 	sizeBits := 0
 	for _, item := range b.items {
@@ -248,6 +253,56 @@ func (e bits64) size() int {
 	return e.n
 }
 
+// BitWriter writes values of arbitrary bit widths into a byte slice, packed directly next to each
+// other from high-order to low-order, in the same way as Bitpacked. It's useful for hand-written
+// encoders of bit-oriented formats that can't be declared with BitpackItems.
+type BitWriter struct{ b bitBuffer }
+
+// Returns a BitWriter that writes into buf, which must start out zeroed.
+func NewBitWriter(buf []byte) *BitWriter {
+	return &BitWriter{bitBuffer{b: buf}}
+}
+
+// Write the n low-order bits of x. Returns ErrBufferOverrun if there isn't room for n more bits,
+// in which case nothing is written. Panics if n is not in [0, 64].
+func (w *BitWriter) WriteBits(x uint64, n int) error {
+	if n < 0 || n > 64 {
+		panic(fmt.Sprintf("invalid n=%d, must be in [0, 64]", n))
+	}
+	if w.b.i+n > w.b.lenBits() {
+		return ErrBufferOverrun
+	}
+	w.b.writeBits(x, n)
+	return nil
+}
+
+// The number of bits written so far.
+func (w *BitWriter) Len() int {
+	return w.b.i
+}
+
+// BitReader reads values of arbitrary bit widths written by BitWriter or Bitpacked.
+type BitReader struct{ b bitBuffer }
+
+// Returns a BitReader that reads from buf.
+func NewBitReader(buf []byte) *BitReader {
+	return &BitReader{bitBuffer{b: buf}}
+}
+
+// Read n bits, returning them as the low-order bits of the result. Returns ErrShortBuffer if
+// fewer than n bits remain. Panics if n is not in [0, 64].
+func (r *BitReader) ReadBits(n int) (uint64, error) {
+	if n < 0 || n > 64 {
+		panic(fmt.Sprintf("invalid n=%d, must be in [0, 64]", n))
+	}
+	return r.b.readBits(n)
+}
+
+// The number of bits read so far.
+func (r *BitReader) Len() int {
+	return r.b.i
+}
+
 type bitBuffer struct {
 	b []byte
 	// The current bit index, where the next bit will be read or written from.
@@ -257,7 +312,14 @@ type bitBuffer struct {
 // Write the n lowest-order bits from x into b. High order bits come first.
 func (b *bitBuffer) writeBits(x uint64, n int) {
 	if b.i+n > b.lenBits() {
-		panic(errBufferOverrun)
+		panic(ErrBufferOverrun)
+	}
+	// The shifting below only works if n and the offset into the current byte fit in 64 bits
+	// together, so split up wide writes.
+	if n > 56 {
+		b.writeBits(x>>32, n-32)
+		b.writeBits(x, 32)
+		return
 	}
 
 	shiftedX := x << uint(64-n) >> uint(b.i%8)
@@ -272,7 +334,12 @@ func (b *bitBuffer) writeBits(x uint64, n int) {
 // Read n bits from the buffer and return them as the low order bits of the result.
 func (b *bitBuffer) readBits(n int) (uint64, error) {
 	if b.i+n > b.lenBits() {
-		return 0, io.ErrUnexpectedEOF
+		return 0, ErrShortBuffer
+	}
+	if n > 56 {
+		high, _ := b.readBits(n - 32)
+		low, _ := b.readBits(32)
+		return high<<32 | low, nil
 	}
 
 	shift := uint(64 - n - b.i%8)
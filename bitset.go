@@ -0,0 +1,219 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+var ErrInvalidBitSet = NewInvalidValueError("encode: invalid bit set")
+
+// Encode the set of small integers v as a bitmap of (maxN+7)/8 bytes, where i is a member if bit
+// i%8 of byte i/8 is set. For maxN up to 8, this is the same as Flags with v[i] for member i.
+//
+// Encode panics if v has a member that isn't less than maxN. Decode returns ErrInvalidBitSet if a
+// bit for a number that isn't less than maxN is set. Panics if maxN is not in [1, 65536].
+func BitSet(v *map[uint16]struct{}, maxN int) Item {
+	return newBitSet(mapMembers{v}, maxN, false)
+}
+
+// BitSet, for a set given as the bools of v, where i is a member if (*v)[i] is true. On decode, v
+// is set to maxN bools. Encode panics if any of v from index maxN on is true.
+func BoolBitSet(v *[]bool, maxN int) Item {
+	return newBitSet(boolMembers{v}, maxN, false)
+}
+
+// BitSet, except that the bitmap is written as a uvarint of its length in bytes followed by only
+// as many bytes as it takes to hold the largest member, so that sets of small numbers take less
+// space. Decode returns ErrInvalidBitSet if the bitmap ends with a zero byte.
+func LengthDelimBitSet(v *map[uint16]struct{}, maxN int) Item {
+	return newBitSet(mapMembers{v}, maxN, true)
+}
+
+// LengthDelimBitSet, for a set given as bools as in BoolBitSet.
+func LengthDelimBoolBitSet(v *[]bool, maxN int) Item {
+	return newBitSet(boolMembers{v}, maxN, true)
+}
+
+func newBitSet(members bitSetMembers, maxN int, lengthDelim bool) Item {
+	if maxN < 1 || maxN > 1<<16 {
+		panic(fmt.Sprintf("invalid maxN=%d, must be in [1, 65536]", maxN))
+	}
+	return bitSet{members: members, maxN: maxN, lengthDelim: lengthDelim}
+}
+
+// The members of a set, however they're stored.
+type bitSetMembers interface {
+	// Calls f with each member.
+	each(f func(i int))
+	// Returns the largest member plus one, or 0 if there are none.
+	end() int
+	// Removes every member, to prepare for adding members less than maxN.
+	reset(maxN int)
+	add(i int)
+}
+
+type bitSet struct {
+	members     bitSetMembers
+	maxN        int
+	lengthDelim bool
+}
+
+// Returns the length of the bitmap in bytes.
+func (e bitSet) bitmapSize() int {
+	end := e.members.end()
+	if end > e.maxN {
+		panic(fmt.Sprintf("encode: bit set has member %d, must be less than %d", end-1, e.maxN))
+	}
+	if e.lengthDelim {
+		return (end + 7) / 8
+	}
+	return (e.maxN + 7) / 8
+}
+func (e bitSet) Encode(buf []byte) {
+	n := e.bitmapSize()
+	if e.lengthDelim {
+		buf = buf[binary.PutUvarint(buf, uint64(n)):]
+	}
+	e.members.each(func(i int) {
+		buf[i/8] |= 1 << uint(i%8)
+	})
+}
+func (e bitSet) Size() int {
+	n := e.bitmapSize()
+	if e.lengthDelim {
+		return uvarintSize(uint64(n)) + n
+	}
+	return n
+}
+func (e bitSet) kind() string {
+	_, bools := e.members.(boolMembers)
+	switch {
+	case bools && e.lengthDelim:
+		return "LengthDelimBoolBitSet"
+	case bools:
+		return "BoolBitSet"
+	case e.lengthDelim:
+		return "LengthDelimBitSet"
+	default:
+		return "BitSet"
+	}
+}
+func (e bitSet) sizeBounds() (min, max int) {
+	n := (e.maxN + 7) / 8
+	if e.lengthDelim {
+		return 1, uvarintSize(uint64(n)) + n
+	}
+	return n, n
+}
+func (e bitSet) skip(buf []byte) (int, error) {
+	bitmap, n, err := e.readBitmap(buf)
+	if err != nil {
+		return 0, err
+	}
+	return n + len(bitmap), nil
+}
+func (e bitSet) Decode(buf []byte) error {
+	bitmap, _, err := e.readBitmap(buf)
+	if err != nil {
+		return err
+	}
+	e.members.reset(e.maxN)
+	for j, b := range bitmap {
+		for ; b != 0; b &= b - 1 {
+			e.members.add(j*8 + bits.TrailingZeros8(b))
+		}
+	}
+	return nil
+}
+
+// Returns the bitmap at the start of buf and the size of its length prefix, after checking that it
+// doesn't hold any numbers that aren't less than maxN.
+func (e bitSet) readBitmap(buf []byte) ([]byte, int, error) {
+	n := (e.maxN + 7) / 8
+	prefix := 0
+	if e.lengthDelim {
+		l, m := binary.Uvarint(buf)
+		if m == 0 {
+			return nil, 0, ErrShortBuffer
+		}
+		if m < 0 {
+			return nil, 0, ErrVarintOverflow
+		}
+		if l > uint64(n) {
+			return nil, 0, ErrInvalidBitSet
+		}
+		n, prefix = int(l), m
+	}
+	if len(buf[prefix:]) < n {
+		return nil, 0, ErrShortBuffer
+	}
+	bitmap := buf[prefix : prefix+n]
+	if n == 0 {
+		return bitmap, prefix, nil
+	}
+	last := bitmap[n-1]
+	if e.lengthDelim && last == 0 {
+		return nil, 0, ErrInvalidBitSet
+	}
+	if n*8 > e.maxN && last>>uint(e.maxN%8) != 0 {
+		return nil, 0, ErrInvalidBitSet
+	}
+	return bitmap, prefix, nil
+}
+
+type mapMembers struct{ v *map[uint16]struct{} }
+
+func (m mapMembers) each(f func(i int)) {
+	for i := range *m.v {
+		f(int(i))
+	}
+}
+func (m mapMembers) end() int {
+	end := 0
+	for i := range *m.v {
+		if int(i) >= end {
+			end = int(i) + 1
+		}
+	}
+	return end
+}
+func (m mapMembers) reset(maxN int) {
+	if *m.v == nil {
+		*m.v = make(map[uint16]struct{})
+		return
+	}
+	clear(*m.v)
+}
+func (m mapMembers) add(i int) {
+	(*m.v)[uint16(i)] = struct{}{}
+}
+
+type boolMembers struct{ v *[]bool }
+
+func (m boolMembers) each(f func(i int)) {
+	for i, member := range *m.v {
+		if member {
+			f(i)
+		}
+	}
+}
+func (m boolMembers) end() int {
+	for i := len(*m.v) - 1; i >= 0; i-- {
+		if (*m.v)[i] {
+			return i + 1
+		}
+	}
+	return 0
+}
+func (m boolMembers) reset(maxN int) {
+	if cap(*m.v) < maxN {
+		*m.v = make([]bool, maxN)
+		return
+	}
+	*m.v = (*m.v)[:maxN]
+	clear(*m.v)
+}
+func (m boolMembers) add(i int) {
+	(*m.v)[i] = true
+}
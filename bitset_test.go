@@ -0,0 +1,82 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitSet(t *testing.T) {
+	v := map[uint16]struct{}{0: {}, 3: {}, 9: {}}
+	enc := New(BitSet(&v, 12))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x09, 0x02}, buf)
+
+	v = nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, map[uint16]struct{}{0: {}, 3: {}, 9: {}}, v)
+	require.NoError(t, enc.Decode([]byte{0x00, 0x00}))
+	require.Len(t, v, 0)
+	require.ErrorIs(t, enc.Decode([]byte{0x00, 0x10}), ErrInvalidBitSet)
+	require.ErrorIs(t, enc.Decode([]byte{0x00}), ErrShortBuffer)
+
+	v = map[uint16]struct{}{12: {}}
+	require.Panics(t, func() { enc.Encode() })
+	require.Panics(t, func() { BitSet(&v, 0) })
+
+	// The same bits as Flags.
+	a, b, c := true, false, true
+	flags := New(Flags(&a, &b, &c)).Encode()
+	v = map[uint16]struct{}{0: {}, 2: {}}
+	require.Equal(t, flags, New(BitSet(&v, 3)).Encode())
+}
+
+func TestBoolBitSet(t *testing.T) {
+	v := []bool{false, true, false, false, false, false, false, false, true}
+	enc := New(BoolBitSet(&v, 16))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x02, 0x01}, buf)
+
+	v = []bool{true}
+	require.NoError(t, enc.Decode(buf))
+	require.Len(t, v, 16)
+	require.Equal(t, []int{1, 8}, trueIndexes(v))
+}
+
+func TestLengthDelimBitSet(t *testing.T) {
+	v := map[uint16]struct{}{1: {}, 900: {}}
+	var b bool
+	enc := New(LengthDelimBitSet(&v, 1000), Bool(&b))
+	buf := enc.Encode()
+	require.Len(t, buf, 1+113+1)
+	require.Equal(t, []byte{0x71, 0x02}, buf[:2])
+
+	v = nil
+	b = true
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, map[uint16]struct{}{1: {}, 900: {}}, v)
+	require.False(t, b)
+
+	v = map[uint16]struct{}{}
+	require.Equal(t, []byte{0x00, 0x00}, enc.Encode())
+	require.NoError(t, enc.Decode([]byte{0x00, 0x01}))
+	require.Len(t, v, 0)
+
+	require.ErrorIs(t, enc.Decode([]byte{0x02, 0x01, 0x00, 0x00}), ErrInvalidBitSet)
+	require.ErrorIs(t, enc.Decode([]byte{0x7E}), ErrInvalidBitSet)
+
+	bools := []bool{false, false, true}
+	require.Equal(t, []byte{0x01, 0x04}, New(LengthDelimBoolBitSet(&bools, 8)).Encode())
+	require.NoError(t, New(LengthDelimBoolBitSet(&bools, 8)).Decode([]byte{0x01, 0x80}))
+	require.Equal(t, []int{7}, trueIndexes(bools))
+}
+
+func trueIndexes(v []bool) []int {
+	var idxs []int
+	for i, b := range v {
+		if b {
+			idxs = append(idxs, i)
+		}
+	}
+	return idxs
+}
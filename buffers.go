@@ -0,0 +1,107 @@
+package encode
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// Byte fields shorter than this are copied into the surrounding segment by EncodeBuffers, since a
+// separate segment costs more than copying them.
+const minBufferRef = 256
+
+// EncodeBuffers is Encode, except that it returns the encoding split into segments rather than as
+// one contiguous buffer. The contents of large LengthDelimBytes and BytesView fields appear as
+// their own segments that refer to the field rather than copies of it, so passing the result to
+// net.Buffers.WriteTo sends them with vectored writes (writev) and never copies them.
+//
+// The result aliases those fields, so they must not be modified until it has been written.
+func (enc Encoding) EncodeBuffers() net.Buffers {
+	w := bufferWriter{}
+	// Sizing first lets items that depend on later ones, like Pointer, see where they are.
+	sizeItems(enc.items, 0)
+	encodeItemsBuffers(enc.items, &w)
+	return w.finish()
+}
+
+// Implemented by items that can hand parts of their encoding to EncodeBuffers by reference.
+type buffersItem interface {
+	encodeBuffers(w *bufferWriter)
+}
+
+// Builds the segments for EncodeBuffers. Items without a reference to offer are encoded into
+// scratch, which becomes a segment of its own whenever a reference is added.
+type bufferWriter struct {
+	bufs    net.Buffers
+	scratch []byte
+	// The total length of bufs.
+	n int
+}
+
+// The number of bytes written so far.
+func (w *bufferWriter) len() int {
+	return w.n + len(w.scratch)
+}
+
+// Encode item into the current segment.
+func (w *bufferWriter) encode(item Item) {
+	size := item.Size()
+	l := len(w.scratch)
+	// Items only set the bits they need, so this must start out zeroed.
+	w.scratch = append(w.scratch, make([]byte, size)...)
+	item.Encode(w.scratch[l : l+size])
+}
+
+func (w *bufferWriter) putUvarint(x uint64) {
+	w.scratch = binary.AppendUvarint(w.scratch, x)
+}
+
+// Add b, as its own segment if it's large enough to be worth it.
+func (w *bufferWriter) ref(b []byte) {
+	if len(b) < minBufferRef {
+		w.scratch = append(w.scratch, b...)
+		return
+	}
+	w.flush()
+	w.bufs = append(w.bufs, b)
+	w.n += len(b)
+}
+
+func (w *bufferWriter) flush() {
+	if len(w.scratch) == 0 {
+		return
+	}
+	w.bufs = append(w.bufs, w.scratch)
+	w.n += len(w.scratch)
+	// Later segments can't share an array with this one, or appending would overwrite it.
+	w.scratch = nil
+}
+
+func (w *bufferWriter) finish() net.Buffers {
+	w.flush()
+	return w.bufs
+}
+
+func encodeItemsBuffers(items []Item, w *bufferWriter) {
+	for _, item := range items {
+		setOffset(item, w.len())
+		if bi, ok := item.(buffersItem); ok {
+			bi.encodeBuffers(w)
+		} else {
+			w.encode(item)
+		}
+	}
+}
+
+func (e group) encodeBuffers(w *bufferWriter) {
+	encodeItemsBuffers(e.items, w)
+}
+
+func (e lengthDelimBytes) encodeBuffers(w *bufferWriter) {
+	w.putUvarint(uint64(len(*e.v)))
+	w.ref(*e.v)
+}
+
+func (e bytesView) encodeBuffers(w *bufferWriter) {
+	w.putUvarint(uint64(len(*e.v)))
+	w.ref(*e.v)
+}
@@ -0,0 +1,35 @@
+package encode
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeBuffers(t *testing.T) {
+	x := uint32(7)
+	small := []byte("small")
+	large := bytes.Repeat([]byte{'x'}, 1000)
+	large2 := bytes.Repeat([]byte{'y'}, 300)
+	y := uint16(3)
+	enc := New(
+		FixedUint32(&x),
+		LengthDelimBytes(&small),
+		BytesView(&large),
+		Group(Align(4), LengthDelimBytes(&large2), FixedUint16(&y)),
+		Align(8),
+	)
+
+	bufs := enc.EncodeBuffers()
+	require.Len(t, bufs, 5)
+	require.True(t, &bufs[1][0] == &large[0])
+	require.True(t, &bufs[3][0] == &large2[0])
+
+	var b bytes.Buffer
+	_, err := bufs.WriteTo(&b)
+	require.NoError(t, err)
+	require.Equal(t, enc.Encode(), b.Bytes())
+
+	require.Len(t, New().EncodeBuffers(), 0)
+}
@@ -0,0 +1,156 @@
+package encode
+
+import "encoding/binary"
+
+// Encode v in the byte order of the machine the program is running on, taking 2 bytes. This avoids
+// byte-swapping when exchanging structures with local C code or persisting data that's only read
+// on the same machine, but the encoding is not portable between machines.
+func NativeUint16(v *uint16) Item {
+	return uint16Order{v: v, order: binary.NativeEndian}
+}
+
+// Encode v in the byte order of the machine the program is running on, taking 4 bytes. See
+// NativeUint16.
+func NativeUint32(v *uint32) Item {
+	return uint32Order{v: v, order: binary.NativeEndian}
+}
+
+// Encode v in the byte order of the machine the program is running on, taking 8 bytes. See
+// NativeUint16.
+func NativeUint64(v *uint64) Item {
+	return uint64Order{v: v, order: binary.NativeEndian}
+}
+
+type uint16Order struct {
+	v     *uint16
+	order binary.ByteOrder
+}
+
+func (e uint16Order) Encode(buf []byte) {
+	e.order.PutUint16(buf, *e.v)
+}
+func (e uint16Order) Size() int {
+	return 2
+}
+func (e uint16Order) kind() string {
+	if e.order == binary.NativeEndian {
+		return "NativeUint16"
+	}
+	// From WithByteOrder.
+	return "FixedUint16"
+}
+func (e uint16Order) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e uint16Order) fixedSize() int {
+	return e.Size()
+}
+func (e uint16Order) Decode(buf []byte) error {
+	if len(buf) < 2 {
+		return ErrShortBuffer
+	}
+	*e.v = e.order.Uint16(buf)
+	return nil
+}
+
+type uint32Order struct {
+	v     *uint32
+	order binary.ByteOrder
+}
+
+func (e uint32Order) Encode(buf []byte) {
+	e.order.PutUint32(buf, *e.v)
+}
+func (e uint32Order) Size() int {
+	return 4
+}
+func (e uint32Order) kind() string {
+	if e.order == binary.NativeEndian {
+		return "NativeUint32"
+	}
+	// From WithByteOrder.
+	return "FixedUint32"
+}
+func (e uint32Order) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e uint32Order) fixedSize() int {
+	return e.Size()
+}
+func (e uint32Order) Decode(buf []byte) error {
+	if len(buf) < 4 {
+		return ErrShortBuffer
+	}
+	*e.v = e.order.Uint32(buf)
+	return nil
+}
+
+type uint64Order struct {
+	v     *uint64
+	order binary.ByteOrder
+}
+
+func (e uint64Order) Encode(buf []byte) {
+	e.order.PutUint64(buf, *e.v)
+}
+func (e uint64Order) Size() int {
+	return 8
+}
+func (e uint64Order) kind() string {
+	if e.order == binary.NativeEndian {
+		return "NativeUint64"
+	}
+	// From WithByteOrder.
+	return "FixedUint64"
+}
+func (e uint64Order) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e uint64Order) fixedSize() int {
+	return e.Size()
+}
+func (e uint64Order) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return ErrShortBuffer
+	}
+	*e.v = e.order.Uint64(buf)
+	return nil
+}
+
+// WithByteOrder returns an Encoding of the same items as enc, except that fixed-width integer items
+// like FixedUint32, Flags32, and Enum16 use order instead of big endian, for formats that are
+// specified entirely in one byte order:
+//
+//   enc := encode.New(
+//       encode.FixedUint16(&h.Magic),
+//       encode.FixedUint32(&h.Length),
+//   ).WithByteOrder(binary.LittleEndian)
+//
+// Items inside Group, Named, If, Switch, Trailing, Transform, Versioned, Compressed, and Sealed are
+// changed too. The resulting items no longer preserve order, so they aren't TupleItems.
+//
+// This is a method rather than an option to New, since every argument to New is an Item.
+func (enc Encoding) WithByteOrder(order binary.ByteOrder) Encoding {
+	return Encoding{items: itemsWithByteOrder(enc.items, order)}
+}
+
+// Implemented by items that encode fixed-width integers, or that contain other items, so that
+// WithByteOrder can change them.
+type byteOrderItem interface {
+	withByteOrder(order binary.ByteOrder) Item
+}
+
+func withByteOrder(item Item, order binary.ByteOrder) Item {
+	if b, ok := item.(byteOrderItem); ok {
+		return b.withByteOrder(order)
+	}
+	return item
+}
+
+func itemsWithByteOrder(items []Item, order binary.ByteOrder) []Item {
+	result := make([]Item, len(items))
+	for i, item := range items {
+		result[i] = withByteOrder(item, order)
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNativeEndian(t *testing.T) {
+	a := uint16(0x0102)
+	b := uint32(0x01020304)
+	c := uint64(0x0102030405060708)
+	buf := New(NativeUint16(&a), NativeUint32(&b), NativeUint64(&c)).Encode()
+
+	expected := binary.NativeEndian.AppendUint16(nil, a)
+	expected = binary.NativeEndian.AppendUint32(expected, b)
+	expected = binary.NativeEndian.AppendUint64(expected, c)
+	require.Equal(t, expected, buf)
+
+	var a2 uint16
+	var b2 uint32
+	var c2 uint64
+	enc2 := New(NativeUint16(&a2), NativeUint32(&b2), NativeUint64(&c2))
+	require.NoError(t, enc2.Decode(buf))
+	require.Equal(t, a, a2)
+	require.Equal(t, b, b2)
+	require.Equal(t, c, c2)
+	require.ErrorIs(t, enc2.Decode(buf[:13]), io.ErrUnexpectedEOF)
+	require.True(t, enc2.IsFixedSize())
+}
+
+func TestWithByteOrder(t *testing.T) {
+	a := uint16(0x0102)
+	b := uint32(0x01020304)
+	c := uint64(0x0102030405060708)
+	d := byte(0xff)
+	cond := true
+	enc := New(
+		FixedUint16(&a),
+		Group(Byte(&d), FixedUint32(&b)),
+		If(&cond, Named("c", FixedUint64(&c))),
+	).WithByteOrder(binary.LittleEndian)
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{
+			0x02, 0x01,
+			0xff, 0x04, 0x03, 0x02, 0x01,
+			0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01,
+		},
+		buf,
+	)
+
+	a, b, c, d = 0, 0, 0, 0
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint16(0x0102), a)
+	require.Equal(t, uint32(0x01020304), b)
+	require.Equal(t, uint64(0x0102030405060708), c)
+	require.Equal(t, byte(0xff), d)
+
+	require.Equal(
+		t,
+		[]byte{0x01, 0x02},
+		New(FixedUint16(&a)).WithByteOrder(binary.BigEndian).Encode(),
+	)
+
+	// Flags and enums are fixed-width integers too.
+	flagBits := make([]bool, 32)
+	flagPtrs := make([]*bool, 32)
+	for i := range flagBits {
+		flagPtrs[i] = &flagBits[i]
+	}
+	flagBits[0], flagBits[9], flagBits[17] = true, true, true
+	e := uint16(0x0102)
+	enc = New(Flags16(flagPtrs[:16]...), Enum16(&e, 0x0102), Flags32(flagPtrs...)).
+		WithByteOrder(binary.LittleEndian)
+	buf = enc.Encode()
+	require.Equal(t, []byte{0x01, 0x02, 0x02, 0x01, 0x01, 0x02, 0x02, 0x00}, buf)
+	flagBits[0], flagBits[9], flagBits[17], e = false, false, false, 0
+	require.NoError(t, enc.Decode(buf))
+	require.True(t, flagBits[0])
+	require.True(t, flagBits[9])
+	require.True(t, flagBits[17])
+	require.Equal(t, uint16(0x0102), e)
+}
@@ -0,0 +1,85 @@
+package encode
+
+import (
+	"bytes"
+	"sync"
+)
+
+var ErrNotCanonical = NewInvalidValueError("encode: not in canonical form")
+
+// DecodeCanonical is Decode, except that it only accepts buf if it's the canonical encoding of
+// what it decodes to, which is the one that Encode produces. Decode accepts some other encodings of
+// the same values, for example varints with redundant continuation bytes, padding that isn't
+// zeroes, or extra bytes after the end of the encoding. Where encoded bytes are hashed, signed, or
+// compared, accepting those lets different bytes stand for the same record, so use
+// DecodeCanonical instead.
+//
+// Returns ErrTrailingBytes if buf holds a canonical encoding followed by more bytes, and
+// ErrNotCanonical for any other non-canonical encoding. enc's values are left decoded either way.
+//
+// Encode is deterministic, producing the same bytes for the same values, for all of the items in
+// this package except Sealed, whose nonce is random, and Gob, Marshaler, and TextMarshaler, which
+// are only as deterministic as what they wrap; gob for example writes maps in random order. This
+// package has no map items of its own: the order of the entries of a map, like those written with
+// cbor.MapHeader, is the order of the items, which must be sorted by the caller to be canonical.
+func (enc Encoding) DecodeCanonical(buf []byte) error {
+	err := enc.Decode(buf)
+	if err != nil {
+		return err
+	}
+	return checkCanonical(enc, buf)
+}
+
+// Returns an error if buf isn't the encoding of enc's current values.
+func checkCanonical(enc Encoding, buf []byte) error {
+	canonical := enc.Encode()
+	if bytes.Equal(canonical, buf) {
+		return nil
+	}
+	if len(canonical) < len(buf) && bytes.Equal(canonical, buf[:len(canonical)]) {
+		return ErrTrailingBytes
+	}
+	return ErrNotCanonical
+}
+
+var equalsScratch = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 64)
+		return &b
+	},
+}
+
+// EqualsEncoded returns true if buf is exactly what Encode would produce for enc's current values,
+// for example to check whether a stored record needs to be rewritten. Each item is encoded into a
+// scratch buffer, which is reused between calls, and compared against buf in turn, so this usually
+// doesn't allocate, and returns as soon as an item differs.
+func (enc Encoding) EqualsEncoded(buf []byte) bool {
+	scratchPtr := equalsScratch.Get().(*[]byte)
+	defer equalsScratch.Put(scratchPtr)
+	// Sizing first lets items that depend on later ones, like Pointer, see where they are.
+	if sizeItems(enc.items, 0) != len(buf) {
+		return false
+	}
+	i := 0
+	for _, item := range enc.items {
+		setOffset(item, i)
+		size := item.Size()
+		if len(buf)-i < size {
+			return false
+		}
+		if size > len(*scratchPtr) {
+			*scratchPtr = make([]byte, size)
+		}
+		itemBuf := (*scratchPtr)[:size]
+		// Items only set the bits they need, so itemBuf must start out zeroed.
+		for j := range itemBuf {
+			itemBuf[j] = 0
+		}
+		item.Encode(itemBuf)
+		if !bytes.Equal(itemBuf, buf[i:i+size]) {
+			return false
+		}
+		i += size
+	}
+	return i == len(buf)
+}
@@ -0,0 +1,56 @@
+package encode
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCanonical(t *testing.T) {
+	var x uint64
+	var b []byte
+	enc := New(Uvarint64(&x), Padding(1), LengthDelimBytes(&b))
+
+	require.NoError(t, enc.DecodeCanonical([]byte{0x05, 0x00, 0x01, 'a'}))
+	require.Equal(t, uint64(5), x)
+	require.Equal(t, []byte("a"), b)
+
+	// Non-minimal varints, for the value and for the length.
+	require.NoError(t, enc.Decode([]byte{0x85, 0x00, 0x00, 0x01, 'a'}))
+	require.ErrorIs(t, enc.DecodeCanonical([]byte{0x85, 0x00, 0x00, 0x01, 'a'}), ErrNotCanonical)
+	require.ErrorIs(t, enc.DecodeCanonical([]byte{0x05, 0x00, 0x81, 0x00, 'a'}), ErrNotCanonical)
+	// Padding that isn't zero.
+	require.ErrorIs(t, enc.DecodeCanonical([]byte{0x05, 0xFF, 0x01, 'a'}), ErrNotCanonical)
+	require.ErrorIs(t, enc.DecodeCanonical([]byte{0x05, 0x00, 0x01, 'a', 'b'}), ErrTrailingBytes)
+	require.ErrorIs(t, enc.DecodeCanonical([]byte{0x05, 0x00, 0x02, 'a'}), ErrShortBuffer)
+
+	d := Decoder{Canonical: true}
+	require.NoError(t, d.Decode(enc, []byte{0x05, 0x00, 0x01, 'a'}))
+	require.ErrorIs(t, d.Decode(enc, []byte{0x85, 0x00, 0x00, 0x01, 'a'}), ErrInvalidValue)
+}
+
+func TestEqualsEncoded(t *testing.T) {
+	var x uint64
+	var b []byte
+	var y uint32
+	enc := New(Uvarint64(&x), LengthDelimBytes(&b), Align(4), FixedUint32(&y))
+
+	x, b, y = 300, bytes.Repeat([]byte{'a'}, 100), 7
+	buf := enc.Encode()
+	require.True(t, enc.EqualsEncoded(buf))
+	require.False(t, enc.EqualsEncoded(buf[:len(buf)-1]))
+	require.False(t, enc.EqualsEncoded(append(buf, 0)))
+	y = 8
+	require.False(t, enc.EqualsEncoded(buf))
+	y = 7
+	b[50] = 'b'
+	require.False(t, enc.EqualsEncoded(buf))
+
+	b = []byte("short")
+	buf = enc.Encode()
+	allocs := testing.AllocsPerRun(100, func() {
+		require.True(t, enc.EqualsEncoded(buf))
+	})
+	require.Equal(t, float64(0), allocs)
+}
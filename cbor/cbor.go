@@ -0,0 +1,312 @@
+// Package cbor provides items that read and write CBOR (RFC 8949) data items, so that encodings
+// built with github.com/bradenaw/encode can interoperate with CBOR-based protocols like COSE and
+// CoAP.
+//
+// Only definite lengths and the preferred (shortest) serialization of each argument are supported.
+// Decoding anything else returns an error rather than silently re-encoding it differently.
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"unicode/utf8"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrMajorType = encode.NewInvalidValueError("cbor: unexpected major type")
+var ErrNotShortest = encode.NewInvalidValueError("cbor: argument not in shortest form")
+var ErrIndefiniteLength = errors.New("cbor: indefinite lengths are not supported")
+var ErrInvalidUTF8 = encode.NewInvalidValueError("cbor: text string is not valid UTF-8")
+var ErrOverflow = encode.NewInvalidValueError("cbor: integer overflows int64")
+
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorSimple   = 7
+)
+
+// Encode v as an unsigned integer (major type 0).
+func Uint(v *uint64) encode.Item {
+	return uintItem{v}
+}
+
+type uintItem struct{ v *uint64 }
+
+func (e uintItem) Encode(buf []byte) { putHead(buf, majorUnsigned, *e.v) }
+func (e uintItem) Size() int         { return headSize(*e.v) }
+func (e uintItem) Decode(buf []byte) error {
+	arg, _, err := readHeadOf(buf, majorUnsigned)
+	if err != nil {
+		return err
+	}
+	*e.v = arg
+	return nil
+}
+
+// Encode v as an unsigned integer (major type 0) if it's non-negative, or a negative integer (major
+// type 1) otherwise.
+func Int(v *int64) encode.Item {
+	return intItem{v}
+}
+
+type intItem struct{ v *int64 }
+
+func (e intItem) head() (int, uint64) {
+	if *e.v < 0 {
+		return majorNegative, uint64(-(*e.v + 1))
+	}
+	return majorUnsigned, uint64(*e.v)
+}
+func (e intItem) Encode(buf []byte) {
+	major, arg := e.head()
+	putHead(buf, major, arg)
+}
+func (e intItem) Size() int {
+	_, arg := e.head()
+	return headSize(arg)
+}
+func (e intItem) Decode(buf []byte) error {
+	major, arg, _, err := readHead(buf)
+	if err != nil {
+		return err
+	}
+	if arg > math.MaxInt64 {
+		return ErrOverflow
+	}
+	switch major {
+	case majorUnsigned:
+		*e.v = int64(arg)
+	case majorNegative:
+		*e.v = -1 - int64(arg)
+	default:
+		return ErrMajorType
+	}
+	return nil
+}
+
+// Encode v as a byte string (major type 2).
+func Bytes(v *[]byte) encode.Item {
+	return bytesItem{v}
+}
+
+type bytesItem struct{ v *[]byte }
+
+func (e bytesItem) Encode(buf []byte) {
+	n := putHead(buf, majorBytes, uint64(len(*e.v)))
+	copy(buf[n:], *e.v)
+}
+func (e bytesItem) Size() int {
+	return headSize(uint64(len(*e.v))) + len(*e.v)
+}
+func (e bytesItem) Decode(buf []byte) error {
+	b, err := readString(buf, majorBytes)
+	if err != nil {
+		return err
+	}
+	*e.v = append([]byte(nil), b...)
+	return nil
+}
+
+// Encode v as a text string (major type 3). Decode returns ErrInvalidUTF8 if the text isn't valid
+// UTF-8, as the spec requires.
+func Text(v *string) encode.Item {
+	return textItem{v}
+}
+
+type textItem struct{ v *string }
+
+func (e textItem) Encode(buf []byte) {
+	n := putHead(buf, majorText, uint64(len(*e.v)))
+	copy(buf[n:], *e.v)
+}
+func (e textItem) Size() int {
+	return headSize(uint64(len(*e.v))) + len(*e.v)
+}
+func (e textItem) Decode(buf []byte) error {
+	b, err := readString(buf, majorText)
+	if err != nil {
+		return err
+	}
+	if !utf8.Valid(b) {
+		return ErrInvalidUTF8
+	}
+	*e.v = string(b)
+	return nil
+}
+
+// Encode the header of an array (major type 4) of n elements. The elements themselves are the
+// items that follow.
+func ArrayHeader(n *uint64) encode.Item {
+	return headerItem{majorArray, n}
+}
+
+// Encode the header of a map (major type 5) of n key-value pairs. The keys and values themselves
+// are the items that follow, alternating.
+func MapHeader(n *uint64) encode.Item {
+	return headerItem{majorMap, n}
+}
+
+type headerItem struct {
+	major int
+	n     *uint64
+}
+
+func (e headerItem) Encode(buf []byte) { putHead(buf, e.major, *e.n) }
+func (e headerItem) Size() int         { return headSize(*e.n) }
+func (e headerItem) Decode(buf []byte) error {
+	arg, _, err := readHeadOf(buf, e.major)
+	if err != nil {
+		return err
+	}
+	*e.n = arg
+	return nil
+}
+
+// Encode v as the simple value true or false (major type 7).
+func Bool(v *bool) encode.Item {
+	return boolItem{v}
+}
+
+type boolItem struct{ v *bool }
+
+const (
+	simpleFalse = majorSimple<<5 | 20
+	simpleTrue  = majorSimple<<5 | 21
+)
+
+func (e boolItem) Encode(buf []byte) {
+	if *e.v {
+		buf[0] = simpleTrue
+	} else {
+		buf[0] = simpleFalse
+	}
+}
+func (e boolItem) Size() int { return 1 }
+func (e boolItem) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return encode.ErrShortBuffer
+	}
+	switch buf[0] {
+	case simpleFalse:
+		*e.v = false
+	case simpleTrue:
+		*e.v = true
+	default:
+		return ErrMajorType
+	}
+	return nil
+}
+
+func headSize(arg uint64) int {
+	switch {
+	case arg < 24:
+		return 1
+	case arg <= math.MaxUint8:
+		return 2
+	case arg <= math.MaxUint16:
+		return 3
+	case arg <= math.MaxUint32:
+		return 5
+	default:
+		return 9
+	}
+}
+
+// Write the initial byte and argument of a data item, returning the number of bytes written.
+func putHead(buf []byte, major int, arg uint64) int {
+	m := byte(major << 5)
+	switch headSize(arg) {
+	case 1:
+		buf[0] = m | byte(arg)
+		return 1
+	case 2:
+		buf[0] = m | 24
+		buf[1] = byte(arg)
+		return 2
+	case 3:
+		buf[0] = m | 25
+		binary.BigEndian.PutUint16(buf[1:], uint16(arg))
+		return 3
+	case 5:
+		buf[0] = m | 26
+		binary.BigEndian.PutUint32(buf[1:], uint32(arg))
+		return 5
+	default:
+		buf[0] = m | 27
+		binary.BigEndian.PutUint64(buf[1:], arg)
+		return 9
+	}
+}
+
+// Read the initial byte and argument of a data item, returning its major type, its argument, and
+// the number of bytes read.
+func readHead(buf []byte) (int, uint64, int, error) {
+	if len(buf) < 1 {
+		return 0, 0, 0, encode.ErrShortBuffer
+	}
+	major := int(buf[0] >> 5)
+	info := buf[0] & 0x1F
+	var arg uint64
+	var n int
+	switch {
+	case info < 24:
+		return major, uint64(info), 1, nil
+	case info == 24:
+		n = 2
+	case info == 25:
+		n = 3
+	case info == 26:
+		n = 5
+	case info == 27:
+		n = 9
+	case info == 31:
+		return 0, 0, 0, ErrIndefiniteLength
+	default:
+		return 0, 0, 0, ErrMajorType
+	}
+	if len(buf) < n {
+		return 0, 0, 0, encode.ErrShortBuffer
+	}
+	switch n {
+	case 2:
+		arg = uint64(buf[1])
+	case 3:
+		arg = uint64(binary.BigEndian.Uint16(buf[1:]))
+	case 5:
+		arg = uint64(binary.BigEndian.Uint32(buf[1:]))
+	case 9:
+		arg = binary.BigEndian.Uint64(buf[1:])
+	}
+	if headSize(arg) != n {
+		return 0, 0, 0, ErrNotShortest
+	}
+	return major, arg, n, nil
+}
+
+func readHeadOf(buf []byte, major int) (uint64, int, error) {
+	actual, arg, n, err := readHead(buf)
+	if err != nil {
+		return 0, 0, err
+	}
+	if actual != major {
+		return 0, 0, ErrMajorType
+	}
+	return arg, n, nil
+}
+
+func readString(buf []byte, major int) ([]byte, error) {
+	l, n, err := readHeadOf(buf, major)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(buf[n:])) < l {
+		return nil, encode.ErrShortBuffer
+	}
+	return buf[n : n+int(l)], nil
+}
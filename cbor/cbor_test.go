@@ -0,0 +1,57 @@
+package cbor
+
+import (
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoding(t *testing.T) {
+	// Examples from RFC 8949 Appendix A.
+	checkUint := func(x uint64, expected []byte) {
+		require.Equal(t, expected, encode.New(Uint(&x)).Encode())
+		var x2 uint64
+		require.NoError(t, encode.New(Uint(&x2)).Decode(expected))
+		require.Equal(t, x, x2)
+	}
+	checkInt := func(x int64, expected []byte) {
+		require.Equal(t, expected, encode.New(Int(&x)).Encode())
+		var x2 int64
+		require.NoError(t, encode.New(Int(&x2)).Decode(expected))
+		require.Equal(t, x, x2)
+	}
+
+	checkUint(0, []byte{0x00})
+	checkUint(23, []byte{0x17})
+	checkUint(24, []byte{0x18, 0x18})
+	checkUint(1000, []byte{0x19, 0x03, 0xe8})
+	checkUint(1000000, []byte{0x1a, 0x00, 0x0f, 0x42, 0x40})
+	checkUint(18446744073709551615, []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+	checkInt(-1, []byte{0x20})
+	checkInt(-100, []byte{0x38, 0x63})
+	checkInt(-1000, []byte{0x39, 0x03, 0xe7})
+	checkInt(500, []byte{0x19, 0x01, 0xf4})
+
+	s := "IETF"
+	b := []byte{1, 2, 3, 4}
+	n := uint64(2)
+	ok := true
+	buf := encode.New(ArrayHeader(&n), Text(&s), Bytes(&b), Bool(&ok)).Encode()
+	require.Equal(t, []byte{0x82, 0x64, 0x49, 0x45, 0x54, 0x46, 0x44, 1, 2, 3, 4, 0xf5}, buf)
+
+	var s2 string
+	var b2 []byte
+	var n2 uint64
+	var ok2 bool
+	require.NoError(t, encode.New(ArrayHeader(&n2), Text(&s2), Bytes(&b2), Bool(&ok2)).Decode(buf))
+	require.Equal(t, n, n2)
+	require.Equal(t, s, s2)
+	require.Equal(t, b, b2)
+	require.Equal(t, ok, ok2)
+
+	var x uint64
+	require.ErrorIs(t, encode.New(Uint(&x)).Decode([]byte{0x18, 0x01}), ErrNotShortest)
+	require.ErrorIs(t, encode.New(Uint(&x)).Decode([]byte{0x20}), ErrMajorType)
+	require.ErrorIs(t, encode.New(Text(&s2)).Decode([]byte{0x61, 0xff}), ErrInvalidUTF8)
+}
@@ -0,0 +1,215 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Encode v as a series of chunks of at most chunkSize bytes, each written as a uvarint of its
+// length followed by its bytes, and ended by an empty chunk. Since nothing needs the total length
+// up front, this format can also be written and read a chunk at a time with WriteChunked and
+// ReadChunked, so that fields too large to hold in memory can be streamed. Decode accepts chunks of
+// any size, like those written with a different chunkSize, and Encode keeps the same chunks for as
+// long as v's length doesn't change. Panics if chunkSize is not positive.
+func Chunked(v *[]byte, chunkSize int) Item {
+	if chunkSize <= 0 {
+		panic(fmt.Sprintf("invalid chunkSize=%d, must be positive", chunkSize))
+	}
+	return chunked{v: v, chunkSize: chunkSize, decoded: &chunkedState{}}
+}
+
+type chunked struct {
+	v         *[]byte
+	chunkSize int
+	decoded   *chunkedState
+}
+
+// The lengths of the chunks last decoded, if they aren't the ones chunkSize gives, as when they
+// were written with a different chunkSize. These are used for as long as the value's length stays
+// the same, so that Size agrees with what Decode consumed.
+type chunkedState struct {
+	lengths []int
+	total   int
+}
+
+// Calls f with each chunk of v, not including the empty one at the end.
+func (e chunked) chunks(f func(chunk []byte)) {
+	if e.decoded.lengths != nil {
+		if e.decoded.total == len(*e.v) {
+			b := *e.v
+			for _, l := range e.decoded.lengths {
+				f(b[:l])
+				b = b[l:]
+			}
+			return
+		}
+		e.decoded.lengths = nil
+	}
+	for b := *e.v; len(b) > 0; {
+		n := minInt(len(b), e.chunkSize)
+		f(b[:n])
+		b = b[n:]
+	}
+}
+
+func (e chunked) Encode(buf []byte) {
+	i := 0
+	e.chunks(func(chunk []byte) {
+		i += binary.PutUvarint(buf[i:], uint64(len(chunk)))
+		i += copy(buf[i:], chunk)
+	})
+	buf[i] = 0
+}
+func (e chunked) Size() int {
+	size := 1
+	e.chunks(func(chunk []byte) {
+		size += uvarintSize(uint64(len(chunk))) + len(chunk)
+	})
+	return size
+}
+func (e chunked) kind() string {
+	return "Chunked"
+}
+func (e chunked) encodeBuffers(w *bufferWriter) {
+	e.chunks(func(chunk []byte) {
+		w.putUvarint(uint64(len(chunk)))
+		w.ref(chunk)
+	})
+	w.putUvarint(0)
+}
+func (e chunked) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e chunked) skip(buf []byte) (int, error) {
+	n, _, err := scanChunks(buf)
+	return n, err
+}
+func (e chunked) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e chunked) decodeLimited(buf []byte, s *decodeState) error {
+	_, total, err := scanChunks(buf)
+	if err != nil {
+		return err
+	}
+	err = s.checkLength(total)
+	if err != nil {
+		return err
+	}
+	*e.v = s.bytes(int(total))
+	e.decoded.lengths = nil
+	i, j := 0, 0
+	for {
+		l, n := binary.Uvarint(buf[i:])
+		i += n
+		if l == 0 {
+			return nil
+		}
+		if e.decoded.lengths == nil && int(l) != minInt(e.chunkSize, int(total)-j) {
+			// Only remembered once they differ, so that the chunks before this one were all
+			// chunkSize long.
+			e.decoded.lengths = make([]int, 0, j/e.chunkSize+1)
+			for k := 0; k < j/e.chunkSize; k++ {
+				e.decoded.lengths = append(e.decoded.lengths, e.chunkSize)
+			}
+			e.decoded.total = int(total)
+		}
+		if e.decoded.lengths != nil {
+			e.decoded.lengths = append(e.decoded.lengths, int(l))
+		}
+		j += copy((*e.v)[j:], buf[i:i+int(l)])
+		i += int(l)
+	}
+}
+
+// Returns the encoded size of the chunks at the start of buf, and the total length of their
+// contents.
+func scanChunks(buf []byte) (int, uint64, error) {
+	i := 0
+	total := uint64(0)
+	for {
+		l, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return 0, 0, ErrShortBuffer
+		}
+		if n < 0 {
+			return 0, 0, ErrVarintOverflow
+		}
+		i += n
+		if l == 0 {
+			return i, total, nil
+		}
+		if uint64(len(buf[i:])) < l {
+			return 0, 0, ErrShortBuffer
+		}
+		i += int(l)
+		total += l
+	}
+}
+
+// Write everything read from r to w in the format of Chunked, until r returns io.EOF. At most one
+// chunk is held in memory at a time. Returns the number of bytes written to w.
+func WriteChunked(w io.Writer, r io.Reader, chunkSize int) (int64, error) {
+	if chunkSize <= 0 {
+		panic(fmt.Sprintf("invalid chunkSize=%d, must be positive", chunkSize))
+	}
+	buf := make([]byte, binary.MaxVarintLen64+chunkSize)
+	written := int64(0)
+	for {
+		l, readErr := io.ReadFull(r, buf[binary.MaxVarintLen64:])
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return written, readErr
+		}
+		// Put the length directly before the chunk, so that each takes only one write.
+		start := binary.MaxVarintLen64 - uvarintSize(uint64(l))
+		binary.PutUvarint(buf[start:], uint64(l))
+		m, err := w.Write(buf[start : binary.MaxVarintLen64+l])
+		written += int64(m)
+		if err != nil {
+			return written, err
+		}
+		if l == 0 {
+			return written, nil
+		}
+		if readErr != nil {
+			// r is done, so only the empty chunk is left.
+			m, err := w.Write([]byte{0})
+			return written + int64(m), err
+		}
+	}
+}
+
+// Read a value in the format of Chunked from r, writing its contents to w as each chunk is read,
+// so that at most one chunk is held in memory at a time. Nothing past the end of the value is
+// consumed from r. Returns the number of bytes written to w.
+//
+// Returns ErrFrameTooLarge as soon as the contents are found to be longer than maxLength bytes. If
+// maxLength is 0, values of any length are allowed. Returns io.EOF only if r ends before the value.
+func ReadChunked(w io.Writer, r io.Reader, maxLength int) (int64, error) {
+	br := asByteReader(r)
+	written := int64(0)
+	for {
+		l, err := ReadUvarint(br)
+		if err == io.EOF && written > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return written, err
+		}
+		if l == 0 {
+			return written, nil
+		}
+		if maxLength > 0 && uint64(written)+l > uint64(maxLength) {
+			return written, ErrFrameTooLarge
+		}
+		n, err := io.CopyN(w, r, int64(l))
+		written += n
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunked(t *testing.T) {
+	var v []byte
+	var b bool
+	enc := New(Chunked(&v, 3), Bool(&b))
+
+	v, b = []byte("abcdefg"), true
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{0x03, 'a', 'b', 'c', 0x03, 'd', 'e', 'f', 0x01, 'g', 0x00, 0x01},
+		buf,
+	)
+	require.Equal(t, buf, bytes.Join(enc.EncodeBuffers(), nil))
+
+	v, b = nil, false
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, []byte("abcdefg"), v)
+	require.True(t, b)
+	require.ErrorIs(t, enc.DecodeLimited(buf, Limits{MaxLength: 6}), ErrLimitExceeded)
+	require.ErrorIs(t, enc.Decode(buf[:9]), ErrShortBuffer)
+
+	v = nil
+	require.Equal(t, []byte{0x00, 0x01}, enc.Encode())
+	require.NoError(t, enc.Decode([]byte{0x00, 0x01}))
+	require.Len(t, v, 0)
+
+	// The streaming functions read and write the same format.
+	var w bytes.Buffer
+	n, err := WriteChunked(&w, bytes.NewReader([]byte("abcdefg")), 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(11), n)
+	require.Equal(t, buf[:11], w.Bytes())
+
+	w.Reset()
+	n, err = WriteChunked(&w, bytes.NewReader([]byte("abcdef")), 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(9), n)
+	require.Equal(t, []byte{0x03, 'a', 'b', 'c', 0x03, 'd', 'e', 'f', 0x00}, w.Bytes())
+
+	r := bytes.NewReader(buf)
+	var out bytes.Buffer
+	n, err = ReadChunked(&out, r, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), n)
+	require.Equal(t, "abcdefg", out.String())
+	require.Equal(t, 1, r.Len())
+
+	_, err = ReadChunked(io.Discard, bytes.NewReader(buf), 5)
+	require.ErrorIs(t, err, ErrFrameTooLarge)
+	_, err = ReadChunked(io.Discard, bytes.NewReader(buf[:6]), 0)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ReadChunked(io.Discard, bytes.NewReader(nil), 0)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestChunkedOtherChunkSize(t *testing.T) {
+	var v []byte
+	var id uint16
+	enc := New(Chunked(&v, 4), FixedUint16(&id))
+	buf := []byte{2, 'a', 'b', 2, 'c', 'd', 0, 0x01, 0x02}
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, []byte("abcd"), v)
+	require.Equal(t, uint16(0x0102), id)
+	require.Equal(t, buf, enc.Encode())
+
+	v = append(v, 'e')
+	require.Equal(t, []byte{4, 'a', 'b', 'c', 'd', 1, 'e', 0, 0x01, 0x02}, enc.Encode())
+
+	var written bytes.Buffer
+	_, err := WriteChunked(&written, bytes.NewReader([]byte("abcdefghij")), 3)
+	require.NoError(t, err)
+	buf = append(written.Bytes(), 0x03, 0x04)
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, []byte("abcdefghij"), v)
+	require.Equal(t, uint16(0x0304), id)
+	require.Equal(t, buf, enc.Encode())
+}
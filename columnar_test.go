@@ -0,0 +1,74 @@
+package encode
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnar(t *testing.T) {
+	type point struct {
+		X    uint16
+		Name string
+	}
+	v := []point{{1, "a"}, {2, "bc"}, {3, ""}}
+	item := func(v *[]point) Item {
+		return Columnar(
+			v,
+			func(p *point) Item { return FixedUint16(&p.X) },
+			func(p *point) Item { return LengthDelimString(&p.Name) },
+		)
+	}
+	buf := New(item(&v)).Encode()
+	require.Equal(
+		t,
+		[]byte{
+			0x03,
+			0x00, 0x01, 0x00, 0x02, 0x00, 0x03,
+			0x01, 'a', 0x02, 'b', 'c', 0x00,
+		},
+		buf,
+	)
+
+	var v2 []point
+	require.NoError(t, New(item(&v2)).Decode(buf))
+	require.Equal(t, v, v2)
+	for i := 0; i < len(buf); i++ {
+		require.ErrorIs(t, New(item(&v2)).Decode(buf[:i]), io.ErrUnexpectedEOF)
+	}
+
+	require.ErrorIs(t, New(item(&v2)).DecodeLimited(buf, Limits{MaxElements: 2}), ErrLimitExceeded)
+	require.ErrorIs(t, New(item(&v2)).DecodeLimited(buf, Limits{MaxLength: 1}), ErrLimitExceeded)
+}
+
+func TestParallelColumnar(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	type point struct {
+		X    uint64
+		Name string
+	}
+	for _, l := range []int{0, 3, 1000} {
+		v := make([]point, l)
+		for i := range v {
+			v[i] = point{uint64(i * i), strings.Repeat("x", i%7)}
+		}
+		fields := []func(p *point) Item{
+			func(p *point) Item { return Uvarint64(&p.X) },
+			func(p *point) Item { return LengthDelimString(&p.Name) },
+		}
+		expected := New(Columnar(&v, fields...)).Encode()
+		buf := New(ParallelColumnar(&v, 2, fields...)).Encode()
+		require.Equal(t, expected, buf)
+
+		var v2 []point
+		require.NoError(t, New(ParallelColumnar(&v2, 2, fields...)).Decode(buf))
+		require.Equal(t, len(v), len(v2))
+		for i := range v {
+			require.Equal(t, v[i], v2[i])
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package encode
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// A Codec compresses and decompresses the blocks written by Compressed.
+type Codec interface {
+	// Compress src, appending the result to dst and returning the extended buffer.
+	Compress(dst []byte, src []byte) []byte
+	// Decompress src, returning the original bytes.
+	Decompress(src []byte) ([]byte, error)
+}
+
+// A Codec using DEFLATE (RFC 1951) at the given compression level from compress/flate.
+func Flate(level int) Codec {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		panic(fmt.Sprintf("invalid flate level=%d, must be in [%d, %d]",
+			level, flate.HuffmanOnly, flate.BestCompression))
+	}
+	return flateCodec{level}
+}
+
+type flateCodec struct{ level int }
+
+func (c flateCodec) Compress(dst []byte, src []byte) []byte {
+	buf := bytes.NewBuffer(dst)
+	// The level is validated in Flate(), and writes to a bytes.Buffer can't fail.
+	w, _ := flate.NewWriter(buf, c.level)
+	_, _ = w.Write(src)
+	_ = w.Close()
+	return buf.Bytes()
+}
+func (c flateCodec) Decompress(src []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(src))
+	defer r.Close()
+	return io.ReadAll(r)
+}
+func (c flateCodec) decompressLimited(src []byte, maxSize int) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(src))
+	defer r.Close()
+	b, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxSize {
+		return nil, ErrLimitExceeded
+	}
+	return b, nil
+}
+
+// Implemented by Codecs that can stop decompressing as soon as the output exceeds maxSize, rather
+// than decompressing everything and then checking.
+type limitedCodec interface {
+	decompressLimited(src []byte, maxSize int) ([]byte, error)
+}
+
+// Encode item, compress it using codec, and write the compressed block as a uvarint of its length
+// followed by the block itself.
+//
+// The compressed block is remembered, so that Size() and Encode() only compress once, and so that
+// Size() after Decode() reports the size of the block that was actually read.
+func Compressed(item Item, codec Codec) Item {
+	return compressed{item: item, codec: codec, state: &compressedState{}}
+}
+
+type compressed struct {
+	item  Item
+	codec Codec
+	state *compressedState
+}
+
+type compressedState struct {
+	valid bool
+	// The encoding of item that block was produced from.
+	raw   []byte
+	block []byte
+}
+
+func (e compressed) setOffset(offset int) {
+	// item is encoded on its own before compressing.
+	setOffset(e.item, 0)
+}
+func (e compressed) compress() []byte {
+	raw := make([]byte, e.item.Size())
+	e.item.Encode(raw)
+	if !e.state.valid || !bytes.Equal(raw, e.state.raw) {
+		e.state.valid = true
+		e.state.raw = raw
+		e.state.block = e.codec.Compress(nil, raw)
+	}
+	return e.state.block
+}
+func (e compressed) Encode(buf []byte) {
+	block := e.compress()
+	n := binary.PutUvarint(buf, uint64(len(block)))
+	copy(buf[n:], block)
+}
+func (e compressed) Size() int {
+	block := e.compress()
+	return uvarintSize(uint64(len(block))) + len(block)
+}
+func (e compressed) kind() string {
+	return "Compressed"
+}
+func (e compressed) withByteOrder(order binary.ByteOrder) Item {
+	return Compressed(withByteOrder(e.item, order), e.codec)
+}
+func (e compressed) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e compressed) dependsOnEarlier() bool {
+	return dependsOnEarlier(e.item)
+}
+func (e compressed) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e compressed) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e compressed) decodeLimited(buf []byte, s *decodeState) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	if uint64(len(buf[n:])) < l {
+		return ErrShortBuffer
+	}
+	return e.decodeBlock(buf[n:n+int(l)], s)
+}
+
+// Decompresses block and decodes item from the result.
+func (e compressed) decodeBlock(block []byte, s *decodeState) error {
+	var raw []byte
+	var err error
+	if lc, ok := e.codec.(limitedCodec); ok && s != nil && s.limits.MaxLength > 0 {
+		raw, err = lc.decompressLimited(block, s.limits.MaxLength)
+	} else {
+		raw, err = e.codec.Decompress(block)
+	}
+	if err != nil {
+		return err
+	}
+	err = s.checkLength(uint64(len(raw)))
+	if err != nil {
+		return err
+	}
+	err = decodeItem(e.item, raw, s)
+	if err != nil {
+		return err
+	}
+	e.state.valid = true
+	e.state.raw = raw
+	e.state.block = append([]byte(nil), block...)
+	return nil
+}
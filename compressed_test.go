@@ -0,0 +1,38 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressed(t *testing.T) {
+	a := uint16(1234)
+	blocks := make([][32]byte, 64)
+	items := make([]Item, len(blocks))
+	for i := range blocks {
+		blocks[i] = [32]byte{1, 2, 3, 4, 5, 6, 7, 8}
+		items[i] = Bytes32(&blocks[i])
+	}
+
+	enc := New(
+		FixedUint16(&a),
+		Compressed(Group(items...), Flate(9)),
+	)
+	b := enc.Encode()
+	require.Less(t, len(b), 32*len(blocks)/4)
+
+	var a2 uint16
+	blocks2 := make([][32]byte, len(blocks))
+	items2 := make([]Item, len(blocks2))
+	for i := range blocks2 {
+		items2[i] = Bytes32(&blocks2[i])
+	}
+	enc2 := New(
+		FixedUint16(&a2),
+		Compressed(Group(items2...), Flate(9)),
+	)
+	require.NoError(t, enc2.Decode(b))
+	require.Equal(t, a, a2)
+	require.Equal(t, blocks, blocks2)
+}
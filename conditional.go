@@ -0,0 +1,138 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var ErrUnknownCase = NewInvalidValueError("encode: no case for tag")
+
+// Encode item only if *cond is true, and otherwise take up no space at all. cond is checked at the
+// time of each call, so it's usually bound to an earlier item in the same Encoding, such as one of
+// several Flags that say which optional sections follow.
+func If(cond *bool, item Item) Item {
+	return ifItem{cond: cond, item: item}
+}
+
+type ifItem struct {
+	cond *bool
+	item Item
+}
+
+func (e ifItem) setOffset(offset int) {
+	setOffset(e.item, offset)
+}
+func (e ifItem) Encode(buf []byte) {
+	if *e.cond {
+		e.item.Encode(buf)
+	}
+}
+func (e ifItem) Size() int {
+	if *e.cond {
+		return e.item.Size()
+	}
+	return 0
+}
+func (e ifItem) kind() string {
+	return "If"
+}
+func (e ifItem) withByteOrder(order binary.ByteOrder) Item {
+	return ifItem{cond: e.cond, item: withByteOrder(e.item, order)}
+}
+func (e ifItem) dependsOnEarlier() bool {
+	return true
+}
+func (e ifItem) skip(buf []byte) (int, error) {
+	if *e.cond {
+		return skipItem(e.item, buf)
+	}
+	return 0, nil
+}
+func (e ifItem) sizeBounds() (int, int) {
+	_, max := sizeBounds(e.item)
+	return 0, max
+}
+func (e ifItem) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e ifItem) decodeLimited(buf []byte, s *decodeState) error {
+	if *e.cond {
+		return decodeItem(e.item, buf, s)
+	}
+	return nil
+}
+
+// Encode cases[*tag], choosing the body by a tag that's bound to an earlier item in the same
+// Encoding, as in type-length-value and opcode-style protocols. The tag itself is not encoded by
+// Switch. Use Group to give a case several items.
+//
+// Decode returns ErrUnknownCase if *tag has no case. Encode panics if *tag has no case.
+func Switch(tag *uint8, cases map[uint8]Item) Item {
+	return switchItem{tag: tag, cases: cases}
+}
+
+type switchItem struct {
+	tag   *uint8
+	cases map[uint8]Item
+}
+
+func (e switchItem) item() Item {
+	item, ok := e.cases[*e.tag]
+	if !ok {
+		panic(fmt.Sprintf("encode: no case for tag %d", *e.tag))
+	}
+	return item
+}
+func (e switchItem) setOffset(offset int) {
+	// *tag is set before each call, but may not have a case until Decode checks it.
+	if item, ok := e.cases[*e.tag]; ok {
+		setOffset(item, offset)
+	}
+}
+func (e switchItem) Encode(buf []byte) {
+	e.item().Encode(buf)
+}
+func (e switchItem) Size() int {
+	return e.item().Size()
+}
+func (e switchItem) kind() string {
+	return "Switch"
+}
+func (e switchItem) withByteOrder(order binary.ByteOrder) Item {
+	cases := make(map[uint8]Item, len(e.cases))
+	for tag, item := range e.cases {
+		cases[tag] = withByteOrder(item, order)
+	}
+	return switchItem{tag: e.tag, cases: cases}
+}
+func (e switchItem) dependsOnEarlier() bool {
+	return true
+}
+func (e switchItem) skip(buf []byte) (int, error) {
+	item, ok := e.cases[*e.tag]
+	if !ok {
+		return 0, ErrUnknownCase
+	}
+	return skipItem(item, buf)
+}
+func (e switchItem) sizeBounds() (int, int) {
+	min, max := -1, 0
+	for _, item := range e.cases {
+		itemMin, itemMax := sizeBounds(item)
+		min, max = unionSizeBounds(min, max, itemMin, itemMax)
+	}
+	if min < 0 {
+		return 0, 0
+	}
+	return min, max
+}
+func (e switchItem) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e switchItem) decodeLimited(buf []byte, s *decodeState) error {
+	item, ok := e.cases[*e.tag]
+	if !ok {
+		return ErrUnknownCase
+	}
+	return decodeItem(item, buf, s)
+}
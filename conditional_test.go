@@ -0,0 +1,71 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIf(t *testing.T) {
+	type record struct {
+		hasA bool
+		hasB bool
+		a    uint16
+		b    uint32
+	}
+	encoding := func(r *record) Encoding {
+		return New(
+			Flags(&r.hasA, &r.hasB),
+			If(&r.hasA, FixedUint16(&r.a)),
+			If(&r.hasB, FixedUint32(&r.b)),
+		)
+	}
+
+	r := record{hasB: true, a: 5, b: 6}
+	buf := encoding(&r).Encode()
+	require.Equal(t, []byte{0x02, 0x00, 0x00, 0x00, 0x06}, buf)
+
+	var r2 record
+	require.NoError(t, encoding(&r2).Decode(buf))
+	require.Equal(t, record{hasB: true, b: 6}, r2)
+
+	r.hasA = true
+	buf = encoding(&r).Encode()
+	require.NoError(t, encoding(&r2).Decode(buf))
+	require.Equal(t, r, r2)
+}
+
+func TestSwitch(t *testing.T) {
+	type message struct {
+		op   uint8
+		key  uint32
+		data [16]byte
+	}
+	encoding := func(m *message) Encoding {
+		return New(
+			Byte(&m.op),
+			Switch(&m.op, map[uint8]Item{
+				1: FixedUint32(&m.key),
+				2: Group(FixedUint32(&m.key), Bytes16(&m.data)),
+				3: Group(),
+			}),
+		)
+	}
+
+	m := message{op: 2, key: 7, data: [16]byte{1}}
+	buf := encoding(&m).Encode()
+	require.Len(t, buf, 21)
+
+	var m2 message
+	require.NoError(t, encoding(&m2).Decode(buf))
+	require.Equal(t, m, m2)
+
+	m = message{op: 1, key: 8}
+	buf = encoding(&m).Encode()
+	require.Equal(t, []byte{0x01, 0x00, 0x00, 0x00, 0x08}, buf)
+	m2 = message{}
+	require.NoError(t, encoding(&m2).Decode(buf))
+	require.Equal(t, m, m2)
+
+	require.ErrorIs(t, encoding(&m2).Decode([]byte{0x04}), ErrUnknownCase)
+}
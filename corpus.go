@@ -0,0 +1,68 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// SeedCorpus returns a diverse set of valid and nearly-valid buffers for enc, meant to be added to
+// a fuzzer's seed corpus (for example with testing.F.Add) to kickstart fuzzing of a decoder.
+//
+// The first buffer is always enc.Encode() using the values currently bound to enc's items. The
+// rest are derived from it: truncations at and just before every item boundary, every item's
+// bytes replaced with all 0x00 and all 0xFF, a maximum-length uvarint inserted at every item
+// boundary, and trailing garbage.
+func SeedCorpus(enc Encoding) [][]byte {
+	valid := enc.Encode()
+
+	boundaries := make([]int, 0, len(enc.items)+1)
+	boundaries = append(boundaries, 0)
+	i := 0
+	for _, item := range enc.items {
+		setOffset(item, i)
+		i += item.Size()
+		boundaries = append(boundaries, i)
+	}
+
+	var maxUvarint [binary.MaxVarintLen64]byte
+	binary.PutUvarint(maxUvarint[:], math.MaxUint64)
+
+	var corpus [][]byte
+	add := func(b []byte) {
+		for _, existing := range corpus {
+			if bytes.Equal(existing, b) {
+				return
+			}
+		}
+		corpus = append(corpus, b)
+	}
+
+	add(valid)
+	for _, boundary := range boundaries {
+		add(append([]byte(nil), valid[:boundary]...))
+		if boundary > 0 {
+			add(append([]byte(nil), valid[:boundary-1]...))
+		}
+	}
+	for j := 1; j < len(boundaries); j++ {
+		start, end := boundaries[j-1], boundaries[j]
+		for _, fill := range []byte{0x00, 0xFF} {
+			b := append([]byte(nil), valid...)
+			for k := start; k < end; k++ {
+				b[k] = fill
+			}
+			add(b)
+		}
+	}
+	for _, boundary := range boundaries {
+		b := make([]byte, 0, len(valid)+len(maxUvarint))
+		b = append(b, valid[:boundary]...)
+		b = append(b, maxUvarint[:]...)
+		b = append(b, valid[boundary:]...)
+		add(b)
+	}
+	add(append(append([]byte(nil), valid...), 0x00))
+	add(append(append([]byte(nil), valid...), 0xFF))
+	return corpus
+}
@@ -0,0 +1,30 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedCorpus(t *testing.T) {
+	a := uint16(0x1234)
+	b := uint64(300)
+	c := true
+	enc := New(FixedUint16(&a), Uvarint64(&b), Bool(&c))
+
+	corpus := SeedCorpus(enc)
+	require.Equal(t, enc.Encode(), corpus[0])
+
+	sawTruncation := false
+	for _, buf := range corpus {
+		var a2 uint16
+		var b2 uint64
+		var c2 bool
+		// Only needs to not panic.
+		err := New(FixedUint16(&a2), Uvarint64(&b2), Bool(&c2)).Decode(buf)
+		if len(buf) < len(corpus[0]) && err != nil {
+			sawTruncation = true
+		}
+	}
+	require.True(t, sawTruncation)
+}
@@ -0,0 +1,85 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Encode a sorted slice of uint64s as a uvarint of its length, followed by the first value as a
+// uvarint, followed by the difference between each value and the one before it as a uvarint. For
+// sorted IDs and timestamps that are close together this is much smaller than encoding each value
+// on its own:
+//
+//   []uint64{1000000, 1000003, 1000010}
+//   03 c0843d 03 07
+//
+// v must be sorted in ascending order, Size and Encode panic otherwise. Decode returns
+// ErrVarintOverflow if the deltas sum to more than fits in a uint64.
+func DeltaUvarints(v *[]uint64) Item {
+	return deltaUvarints{v}
+}
+
+type deltaUvarints struct{ v *[]uint64 }
+
+func (e deltaUvarints) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	prev := uint64(0)
+	for _, x := range *e.v {
+		n += binary.PutUvarint(buf[n:], x-prev)
+		prev = x
+	}
+}
+func (e deltaUvarints) Size() int {
+	size := uvarintSize(uint64(len(*e.v)))
+	prev := uint64(0)
+	for i, x := range *e.v {
+		if x < prev {
+			panic(fmt.Sprintf("DeltaUvarints: v is not sorted, v[%d] < v[%d]", i, i-1))
+		}
+		size += uvarintSize(x - prev)
+		prev = x
+	}
+	return size
+}
+func (e deltaUvarints) kind() string {
+	return "DeltaUvarints"
+}
+func (e deltaUvarints) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e deltaUvarints) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e deltaUvarints) decodeLimited(buf []byte, s *decodeState) error {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	err := s.checkElements(l)
+	if err != nil {
+		return err
+	}
+	// Every element takes at least one byte, so don't trust a length that couldn't possibly fit.
+	if uint64(len(buf[n:])) < l {
+		return ErrShortBuffer
+	}
+	out := make([]uint64, l)
+	prev := uint64(0)
+	for i := range out {
+		d, m := binary.Uvarint(buf[n:])
+		if m == 0 {
+			return ErrShortBuffer
+		}
+		if m < 0 || prev+d < prev {
+			return ErrVarintOverflow
+		}
+		n += m
+		prev += d
+		out[i] = prev
+	}
+	*e.v = out
+	return nil
+}
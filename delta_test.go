@@ -0,0 +1,46 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeltaUvarints(t *testing.T) {
+	v := []uint64{1000000, 1000003, 1000010}
+	enc := New(DeltaUvarints(&v))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x03, 0xc0, 0x84, 0x3d, 0x03, 0x07}, buf)
+
+	var v2 []uint64
+	require.NoError(t, New(DeltaUvarints(&v2)).Decode(buf))
+	require.Equal(t, v, v2)
+
+	v = nil
+	require.Equal(t, []byte{0x00}, enc.Encode())
+
+	v = []uint64{0, 0, 1, ^uint64(0)}
+	buf = enc.Encode()
+	require.NoError(t, New(DeltaUvarints(&v2)).Decode(buf))
+	require.Equal(t, v, v2)
+
+	require.ErrorIs(t, New(DeltaUvarints(&v2)).Decode(buf[:len(buf)-1]), io.ErrUnexpectedEOF)
+	require.ErrorIs(
+		t,
+		New(DeltaUvarints(&v2)).Decode([]byte{
+			0x02,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01,
+			0x01,
+		}),
+		ErrOverflowVarint,
+	)
+	require.ErrorIs(
+		t,
+		New(DeltaUvarints(&v2)).DecodeLimited([]byte{0x03, 0x00, 0x00, 0x00}, Limits{MaxElements: 2}),
+		ErrLimitExceeded,
+	)
+
+	v = []uint64{2, 1}
+	require.Panics(t, func() { enc.Encode() })
+}
@@ -0,0 +1,391 @@
+// Package der provides items that read and write ASN.1 values in the Distinguished Encoding Rules
+// (DER), so that structures from certificates and other cryptographic formats can be declared with
+// github.com/bradenaw/encode rather than with encoding/asn1 struct tags.
+//
+// Every value is written as a tag byte, a definite length, and then its contents. A structure is
+// declared by nesting items in the same shape as its ASN.1 definition:
+//
+//   // AlgorithmIdentifier ::= SEQUENCE {
+//   //   algorithm   OBJECT IDENTIFIER,
+//   //   parameters  OCTET STRING
+//   // }
+//   func (a *algorithmIdentifier) encoding() encode.Encoding {
+//   	return encode.New(der.Sequence(
+//   		der.OID(&a.algorithm),
+//   		der.OctetString(&a.parameters),
+//   	))
+//   }
+//
+// Decoding is strict: anything that isn't the unique DER encoding of a value, like a length or
+// integer written in more bytes than necessary, is rejected with an error that matches
+// encode.ErrInvalidValue.
+//
+// See ITU-T X.690 for the encoding rules.
+package der
+
+import (
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrTag = encode.NewInvalidValueError("der: unexpected tag")
+var ErrLength = encode.NewInvalidValueError("der: invalid length")
+var ErrInteger = encode.NewInvalidValueError("der: invalid integer")
+var ErrOID = encode.NewInvalidValueError("der: invalid object identifier")
+
+// Tags of the universal types supported by this package.
+const (
+	TagInteger     = 0x02
+	TagOctetString = 0x04
+	TagOID         = 0x06
+	TagSequence    = 0x30
+)
+
+// Encode item as the contents of a value with the given tag byte, preceded by the tag and the
+// length of item's encoding. This is the building block for the other items in this package, and
+// can be used for types they don't cover, for example a context-specific explicit tag [0] is
+// TLV(0xA0, item). Only single-byte tags are supported.
+//
+// Decode returns ErrTag if the value has a different tag, and ErrLength if item doesn't decode
+// exactly the value's contents.
+func TLV(tag byte, item encode.Item) encode.Item {
+	return tlv{tag: tag, item: item}
+}
+
+// Encode items in order as the contents of a SEQUENCE.
+func Sequence(items ...encode.Item) encode.Item {
+	return TLV(TagSequence, group(items))
+}
+
+// An INTEGER that fits in an int64. Decode returns ErrInteger if the value doesn't fit.
+func Integer(v *int64) encode.Item {
+	return TLV(TagInteger, integer{v})
+}
+
+// An INTEGER of any size, like a certificate serial number. v must not be nil.
+func BigInteger(v *big.Int) encode.Item {
+	return TLV(TagInteger, bigInteger{v})
+}
+
+// An OCTET STRING.
+func OctetString(v *[]byte) encode.Item {
+	return TLV(TagOctetString, octetString{v})
+}
+
+// An OBJECT IDENTIFIER. Encode panics if *v isn't valid, meaning it has fewer than two arcs, the
+// first isn't 0, 1, or 2, the second is over 39 when the first is 0 or 1, or any is negative.
+func OID(v *asn1.ObjectIdentifier) encode.Item {
+	return TLV(TagOID, oid{v})
+}
+
+type tlv struct {
+	tag  byte
+	item encode.Item
+}
+
+func (e tlv) Encode(buf []byte) {
+	buf[0] = e.tag
+	n := 1 + putLength(buf[1:], e.item.Size())
+	e.item.Encode(buf[n:])
+}
+func (e tlv) Size() int {
+	size := e.item.Size()
+	return 1 + lengthSize(size) + size
+}
+func (e tlv) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return encode.ErrShortBuffer
+	}
+	if buf[0] != e.tag {
+		return ErrTag
+	}
+	l, n, err := readLength(buf[1:])
+	if err != nil {
+		return err
+	}
+	start := 1 + n
+	if len(buf[start:]) < l {
+		return encode.ErrShortBuffer
+	}
+	contents := buf[start : start+l]
+	err = e.item.Decode(contents)
+	if err != nil {
+		return err
+	}
+	if e.item.Size() != l {
+		return ErrLength
+	}
+	return nil
+}
+
+// Lengths under 128 are written in one byte. Longer ones are written as 0x80 plus the number of
+// bytes that follow, and then the length in big endian order in as few bytes as possible.
+func lengthSize(l int) int {
+	if l < 0x80 {
+		return 1
+	}
+	n := 1
+	for ; l > 0; l >>= 8 {
+		n++
+	}
+	return n
+}
+
+func putLength(buf []byte, l int) int {
+	n := lengthSize(l)
+	if n == 1 {
+		buf[0] = byte(l)
+		return 1
+	}
+	buf[0] = 0x80 | byte(n-1)
+	for i := n - 1; i > 0; i-- {
+		buf[i] = byte(l)
+		l >>= 8
+	}
+	return n
+}
+
+func readLength(buf []byte) (int, int, error) {
+	if len(buf) < 1 {
+		return 0, 0, encode.ErrShortBuffer
+	}
+	if buf[0] < 0x80 {
+		return int(buf[0]), 1, nil
+	}
+	// 0x80 is the indefinite length, which DER doesn't allow.
+	k := int(buf[0] & 0x7F)
+	if k == 0 || k > 4 {
+		return 0, 0, ErrLength
+	}
+	if len(buf) < 1+k {
+		return 0, 0, encode.ErrShortBuffer
+	}
+	if buf[1] == 0 {
+		return 0, 0, ErrLength
+	}
+	l := 0
+	for _, b := range buf[1 : 1+k] {
+		l = l<<8 | int(b)
+	}
+	if l < 0x80 {
+		return 0, 0, ErrLength
+	}
+	return l, 1 + k, nil
+}
+
+type group []encode.Item
+
+func (e group) Encode(buf []byte) {
+	i := 0
+	for _, item := range e {
+		size := item.Size()
+		item.Encode(buf[i : i+size])
+		i += size
+	}
+}
+func (e group) Size() int {
+	size := 0
+	for _, item := range e {
+		size += item.Size()
+	}
+	return size
+}
+func (e group) Decode(buf []byte) error {
+	i := 0
+	for _, item := range e {
+		err := item.Decode(buf[i:])
+		if err != nil {
+			return err
+		}
+		i += item.Size()
+	}
+	return nil
+}
+
+// INTEGER contents are the value in two's complement, big endian, in as few bytes as possible.
+type integer struct{ v *int64 }
+
+func (e integer) Encode(buf []byte) {
+	x := *e.v
+	for i := e.Size() - 1; i >= 0; i-- {
+		buf[i] = byte(x)
+		x >>= 8
+	}
+}
+func (e integer) Size() int {
+	n := 1
+	for x := *e.v; x > 127 || x < -128; x >>= 8 {
+		n++
+	}
+	return n
+}
+func (e integer) Decode(buf []byte) error {
+	err := checkInteger(buf)
+	if err != nil {
+		return err
+	}
+	if len(buf) > 8 {
+		return ErrInteger
+	}
+	// Sign-extend from the first byte.
+	x := int64(int8(buf[0]))
+	for _, b := range buf[1:] {
+		x = x<<8 | int64(b)
+	}
+	*e.v = x
+	return nil
+}
+
+type bigInteger struct{ v *big.Int }
+
+func (e bigInteger) Encode(buf []byte) {
+	copy(buf, bigIntegerBytes(e.v))
+}
+func (e bigInteger) Size() int {
+	return len(bigIntegerBytes(e.v))
+}
+func (e bigInteger) Decode(buf []byte) error {
+	err := checkInteger(buf)
+	if err != nil {
+		return err
+	}
+	e.v.SetBytes(buf)
+	if buf[0]&0x80 != 0 {
+		// Negative, so subtract 2^(8*len(buf)) to undo the two's complement.
+		e.v.Sub(e.v, new(big.Int).Lsh(big.NewInt(1), uint(8*len(buf))))
+	}
+	return nil
+}
+
+func bigIntegerBytes(v *big.Int) []byte {
+	if v.Sign() >= 0 {
+		b := v.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			// Add a zero byte so the value doesn't read as negative.
+			b = append([]byte{0}, b...)
+		}
+		return b
+	}
+	// The two's complement of a negative v is the bitwise complement of -v-1.
+	b := new(big.Int).Sub(new(big.Int).Neg(v), big.NewInt(1)).Bytes()
+	for i := range b {
+		b[i] = ^b[i]
+	}
+	if len(b) == 0 || b[0]&0x80 == 0 {
+		b = append([]byte{0xFF}, b...)
+	}
+	return b
+}
+
+// Returns ErrInteger if buf isn't the minimal encoding of an integer.
+func checkInteger(buf []byte) error {
+	if len(buf) == 0 {
+		return ErrInteger
+	}
+	if len(buf) > 1 &&
+		((buf[0] == 0x00 && buf[1]&0x80 == 0) || (buf[0] == 0xFF && buf[1]&0x80 != 0)) {
+		return ErrInteger
+	}
+	return nil
+}
+
+type octetString struct{ v *[]byte }
+
+func (e octetString) Encode(buf []byte) {
+	copy(buf, *e.v)
+}
+func (e octetString) Size() int {
+	return len(*e.v)
+}
+func (e octetString) Decode(buf []byte) error {
+	*e.v = append((*e.v)[:0], buf...)
+	return nil
+}
+
+// OBJECT IDENTIFIER contents are the first two arcs combined as 40*first+second, followed by the
+// rest of the arcs, each written in base 128, most significant group first, with the high bit set
+// on every byte but the last.
+type oid struct{ v *asn1.ObjectIdentifier }
+
+func (e oid) subidentifiers() []int {
+	arcs := *e.v
+	if len(arcs) < 2 || arcs[0] < 0 || arcs[0] > 2 || arcs[1] < 0 ||
+		(arcs[0] < 2 && arcs[1] > 39) {
+		panic("der: invalid object identifier")
+	}
+	subs := make([]int, 0, len(arcs)-1)
+	subs = append(subs, 40*arcs[0]+arcs[1])
+	for _, arc := range arcs[2:] {
+		if arc < 0 {
+			panic("der: invalid object identifier")
+		}
+		subs = append(subs, arc)
+	}
+	return subs
+}
+func (e oid) Encode(buf []byte) {
+	i := 0
+	for _, x := range e.subidentifiers() {
+		n := base128Size(x)
+		for j := n - 1; j >= 0; j-- {
+			buf[i+j] = byte(x&0x7F) | 0x80
+			x >>= 7
+		}
+		buf[i+n-1] &= 0x7F
+		i += n
+	}
+}
+func (e oid) Size() int {
+	size := 0
+	for _, x := range e.subidentifiers() {
+		size += base128Size(x)
+	}
+	return size
+}
+func (e oid) Decode(buf []byte) error {
+	var arcs asn1.ObjectIdentifier
+	for i := 0; i < len(buf); {
+		if buf[i] == 0x80 {
+			// A leading zero group, which makes the encoding non-minimal.
+			return ErrOID
+		}
+		x := 0
+		for ; ; i++ {
+			if i == len(buf) || x > (1<<31-1)>>7 {
+				return ErrOID
+			}
+			x = x<<7 | int(buf[i]&0x7F)
+			if buf[i]&0x80 == 0 {
+				i++
+				break
+			}
+		}
+		if arcs == nil {
+			switch {
+			case x < 40:
+				arcs = asn1.ObjectIdentifier{0, x}
+			case x < 80:
+				arcs = asn1.ObjectIdentifier{1, x - 40}
+			default:
+				arcs = asn1.ObjectIdentifier{2, x - 80}
+			}
+			continue
+		}
+		arcs = append(arcs, x)
+	}
+	if arcs == nil {
+		return ErrOID
+	}
+	*e.v = arcs
+	return nil
+}
+
+func base128Size(x int) int {
+	n := 1
+	for ; x > 0x7F; x >>= 7 {
+		n++
+	}
+	return n
+}
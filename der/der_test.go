@@ -0,0 +1,124 @@
+package der
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	version int64
+	serial  *big.Int
+	algo    asn1.ObjectIdentifier
+	key     []byte
+	extra   int64
+}
+
+func (r *record) encoding() encode.Encoding {
+	return encode.New(Sequence(
+		Integer(&r.version),
+		BigInteger(r.serial),
+		Sequence(OID(&r.algo)),
+		OctetString(&r.key),
+		TLV(0xA0, Integer(&r.extra)),
+	))
+}
+
+// The same structure, for encoding/asn1.
+type asn1Record struct {
+	Version int64
+	Serial  *big.Int
+	Algo    struct{ Algorithm asn1.ObjectIdentifier }
+	Key     []byte
+	Extra   int64 `asn1:"explicit,tag:0"`
+}
+
+func TestMatchesEncodingASN1(t *testing.T) {
+	serial, ok := new(big.Int).SetString("-1234567890123456789012345678901234567890", 10)
+	require.True(t, ok)
+	for _, r := range []record{
+		{
+			version: 2,
+			serial:  big.NewInt(1),
+			algo:    asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11},
+			key:     []byte{0x01, 0x02},
+			extra:   -129,
+		},
+		{
+			version: -1 << 63,
+			serial:  serial,
+			algo:    asn1.ObjectIdentifier{2, 999, 3},
+			key:     bytes.Repeat([]byte{0xAB}, 300),
+			extra:   128,
+		},
+		{
+			version: 0,
+			serial:  big.NewInt(-128),
+			algo:    asn1.ObjectIdentifier{0, 39},
+			key:     []byte{},
+			extra:   1<<63 - 1,
+		},
+	} {
+		var expected asn1Record
+		expected.Version = r.version
+		expected.Serial = r.serial
+		expected.Algo.Algorithm = r.algo
+		expected.Key = r.key
+		expected.Extra = r.extra
+		expectedBytes, err := asn1.Marshal(expected)
+		require.NoError(t, err)
+
+		buf := r.encoding().Encode()
+		require.Equal(t, expectedBytes, buf)
+
+		decoded := record{serial: new(big.Int)}
+		require.NoError(t, decoded.encoding().Decode(buf))
+		require.Equal(t, r.version, decoded.version)
+		require.Equal(t, 0, r.serial.Cmp(decoded.serial))
+		require.Equal(t, r.algo, decoded.algo)
+		require.Equal(t, r.key, decoded.key)
+		require.Equal(t, r.extra, decoded.extra)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	var x int64
+	var b []byte
+	var o asn1.ObjectIdentifier
+
+	require.NoError(t, encode.New(Integer(&x)).Decode([]byte{0x02, 0x02, 0x00, 0x80}))
+	require.Equal(t, int64(128), x)
+
+	for _, tc := range []struct {
+		item encode.Item
+		buf  []byte
+		err  error
+	}{
+		{Integer(&x), []byte{0x04, 0x01, 0x00}, ErrTag},
+		{Integer(&x), []byte{0x02, 0x02, 0x00, 0x7F}, ErrInteger},
+		{Integer(&x), []byte{0x02, 0x02, 0xFF, 0x80}, ErrInteger},
+		{Integer(&x), []byte{0x02, 0x00}, ErrInteger},
+		{Integer(&x), []byte{0x02, 0x09, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}, ErrInteger},
+		{Integer(&x), []byte{0x02, 0x81, 0x01, 0x00}, ErrLength},
+		{Integer(&x), []byte{0x02, 0x80, 0x00, 0x00}, ErrLength},
+		{Integer(&x), []byte{0x02, 0x02, 0x01}, encode.ErrShortBuffer},
+		{OctetString(&b), []byte{0x04, 0x82, 0x00, 0x80}, ErrLength},
+		{OID(&o), []byte{0x06, 0x02, 0x2A, 0x80}, ErrOID},
+		{OID(&o), []byte{0x06, 0x02, 0x2A, 0x80, 0x01}, ErrOID},
+		{OID(&o), []byte{0x06, 0x00}, ErrOID},
+		{Sequence(Integer(&x)), []byte{0x30, 0x04, 0x02, 0x01, 0x01, 0x00}, ErrLength},
+	} {
+		err := encode.New(tc.item).Decode(tc.buf)
+		require.ErrorIs(t, err, tc.err, "%x", tc.buf)
+		if tc.err != encode.ErrShortBuffer {
+			require.ErrorIs(t, err, encode.ErrInvalidValue)
+		}
+	}
+
+	o = asn1.ObjectIdentifier{1, 40}
+	require.Panics(t, func() { encode.New(OID(&o)).Encode() })
+}
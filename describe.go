@@ -0,0 +1,172 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// Named returns item with a name attached, which is used by Describe. It otherwise behaves exactly
+// like item.
+func Named(name string, item Item) Item {
+	return named{name: name, item: item}
+}
+
+type named struct {
+	name string
+	item Item
+	// Set by WithMetrics.
+	metrics Metrics
+	path    string
+}
+
+func (e named) Encode(buf []byte) {
+	e.item.Encode(buf)
+	if e.metrics != nil {
+		e.metrics.Encoded(e.path, len(buf))
+	}
+}
+func (e named) Size() int {
+	return e.item.Size()
+}
+func (e named) kind() string {
+	return "Named"
+}
+func (e named) withByteOrder(order binary.ByteOrder) Item {
+	return named{
+		name:    e.name,
+		item:    withByteOrder(e.item, order),
+		metrics: e.metrics,
+		path:    e.path,
+	}
+}
+func (e named) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e named) decodeLimited(buf []byte, s *decodeState) error {
+	err := decodeItem(e.item, buf, s)
+	if err != nil {
+		return err
+	}
+	if e.metrics != nil {
+		e.metrics.Decoded(e.path, minInt(e.item.Size(), len(buf)))
+	}
+	return nil
+}
+func (e named) setOffset(offset int) {
+	setOffset(e.item, offset)
+}
+func (e named) skip(buf []byte) (int, error) {
+	return skipItem(e.item, buf)
+}
+func (e named) dependsOnEarlier() bool {
+	return dependsOnEarlier(e.item)
+}
+func (e named) sizeBounds() (int, int) {
+	return sizeBounds(e.item)
+}
+
+// Describe returns a table of enc's items, one per line, with each item's index, name if it was
+// given one with Named, kind, and size in bytes, for example:
+//
+//	index  name     kind              size
+//	0      id       FixedUint64       8
+//	1      flags    Flags             1
+//	2      tags     Group             2-20
+//	2.0             Uvarint64         1-10
+//	2.1             Uvarint64         1-10
+//	3      payload  LengthDelimBytes  1+
+//
+// This is meant as documentation for the wire format that can't drift out of sync with the code.
+func (enc Encoding) Describe() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "index\tname\tkind\tsize")
+	describeItems(w, "", enc.Items())
+	w.Flush()
+	return sb.String()
+}
+
+func describeItems(w *tabwriter.Writer, prefix string, items []ItemInfo) {
+	for i, info := range items {
+		index := prefix + fmt.Sprint(i)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", index, info.Name, info.Kind, describeSize(info))
+		describeItems(w, index+".", info.Items)
+	}
+}
+
+// ItemInfo describes one item of an Encoding, for tools that work over arbitrary Encodings, like
+// loggers and schema registries.
+type ItemInfo struct {
+	// The name given to the item with Named, or "" if it wasn't given one.
+	Name string
+	// The name of the function that produced the item, like "LengthDelimBytes", or the type of the
+	// item if it's from outside this package.
+	Kind string
+	// Bounds on the item's encoded size, as in Encoding.MinSize and Encoding.MaxSize. MaxSize is -1
+	// if the item can be arbitrarily large.
+	MinSize int
+	MaxSize int
+	// For a Group, the items in it.
+	Items []ItemInfo
+	// The item itself, unwrapped from Named.
+	Item Item
+}
+
+// Returns true if the item always encodes to the same number of bytes.
+func (info ItemInfo) IsFixedSize() bool {
+	return info.MinSize == info.MaxSize
+}
+
+// Items returns a description of each of enc's items, in order.
+func (enc Encoding) Items() []ItemInfo {
+	return itemInfos(enc.items)
+}
+
+func itemInfos(items []Item) []ItemInfo {
+	infos := make([]ItemInfo, 0, len(items))
+	for _, item := range items {
+		info := ItemInfo{}
+		if n, ok := item.(named); ok {
+			info.Name = n.name
+			item = n.item
+		}
+		info.Kind = itemKind(item)
+		info.MinSize, info.MaxSize = sizeBounds(item)
+		if g, ok := item.(group); ok {
+			info.Items = itemInfos(g.items)
+		}
+		info.Item = item
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Implemented by every item in this package, returning the name of the function that produced it,
+// like "LengthDelimBytes".
+type kindItem interface {
+	kind() string
+}
+
+// Returns the name of the function that produced item, or its type if it's from outside this
+// package.
+func itemKind(item Item) string {
+	if k, ok := item.(kindItem); ok {
+		return k.kind()
+	}
+	return reflect.TypeOf(item).String()
+}
+
+func describeSize(info ItemInfo) string {
+	min, max := info.MinSize, info.MaxSize
+	switch {
+	case max < 0:
+		return fmt.Sprintf("%d+", min)
+	case min == max:
+		return fmt.Sprint(min)
+	default:
+		return fmt.Sprintf("%d-%d", min, max)
+	}
+}
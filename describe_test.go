@@ -0,0 +1,162 @@
+package encode
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	var id uint64
+	var a, b bool
+	var x, y uint64
+	var payload []byte
+	var points []uint64
+	enc := New(
+		Named("id", FixedUint64(&id)),
+		Named("flags", Flags(&a, &b)),
+		Named("tags", Group(Uvarint64(&x), Uvarint64(&y))),
+		Align(4),
+		Named("payload", LengthDelimBytes(&payload)),
+		Columnar(&points, func(p *uint64) Item { return Uvarint64(p) }),
+		If(&a, Bool(&b)),
+	)
+	require.Equal(
+		t,
+		""+
+			"index  name     kind              size\n"+
+			"0      id       FixedUint64       8\n"+
+			"1      flags    Flags             1\n"+
+			"2      tags     Group             2-20\n"+
+			"2.0             Uvarint64         1-10\n"+
+			"2.1             Uvarint64         1-10\n"+
+			"3               Align             0-3\n"+
+			"4      payload  LengthDelimBytes  1+\n"+
+			"5               Columnar          1+\n"+
+			"6               If                0-1\n",
+		enc.Describe(),
+	)
+
+	id, a, b, x, y, payload = 1, true, false, 2, 3, []byte("abc")
+	buf := enc.Encode()
+	var id2 uint64
+	var payload2 []byte
+	enc2 := New(
+		Named("id", FixedUint64(&id2)),
+		Padding(1),
+		Group(Padding(2)),
+		Align(4),
+		Named("payload", LengthDelimBytes(&payload2)),
+	)
+	require.NoError(t, enc2.Decode(buf))
+	require.Equal(t, id, id2)
+	require.Equal(t, payload, payload2)
+	offset, width, err := enc2.OffsetOf(buf, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, offset)
+	require.Equal(t, 8, width)
+}
+
+func TestItems(t *testing.T) {
+	var id uint64
+	var x uint16
+	var s string
+	enc := New(
+		Named("id", FixedUint64(&id)),
+		Group(FixedUint16(&x), Named("s", LengthDelimString(&s))),
+	)
+	items := enc.Items()
+	require.Len(t, items, 2)
+	require.Equal(t, "id", items[0].Name)
+	require.Equal(t, "FixedUint64", items[0].Kind)
+	require.True(t, items[0].IsFixedSize())
+	require.Equal(t, 8, items[0].MaxSize)
+	require.Equal(t, fixedUint64{&id}, items[0].Item)
+
+	require.Equal(t, "Group", items[1].Kind)
+	require.False(t, items[1].IsFixedSize())
+	require.Equal(t, 3, items[1].MinSize)
+	require.Equal(t, -1, items[1].MaxSize)
+	require.Len(t, items[1].Items, 2)
+	require.Equal(t, "s", items[1].Items[1].Name)
+	require.Equal(t, "LengthDelimString", items[1].Items[1].Kind)
+}
+
+func TestItemKind(t *testing.T) {
+	var u8 uint8
+	var u16 uint16
+	var u32 uint32
+	var u64 uint64
+	var i64 int64
+	var b bool
+	var bs []byte
+	var s string
+	var tm time.Time
+	var bits []bool
+	var set map[uint16]struct{}
+	type elem struct{ x uint64 }
+	var elems []elem
+	field := func(e *elem) Item { return Uvarint64(&e.x) }
+	ptr, data := Pointer(Uvarint64(&u64))
+	reordered := New(FixedUint16(&u16)).WithByteOrder(binary.LittleEndian).items[0]
+
+	for _, tt := range []struct {
+		item Item
+		kind string
+	}{
+		{BCD(&s, 4), "BCD"},
+		{Bitpacked(Bit(&b)), "Bitpacked"},
+		{BitSet(&set, 8), "BitSet"},
+		{BoolBitSet(&bits, 8), "BoolBitSet"},
+		{LengthDelimBitSet(&set, 8), "LengthDelimBitSet"},
+		{LengthDelimBoolBitSet(&bits, 8), "LengthDelimBoolBitSet"},
+		{NativeUint16(&u16), "NativeUint16"},
+		{NativeUint32(&u32), "NativeUint32"},
+		{NativeUint64(&u64), "NativeUint64"},
+		{reordered, "FixedUint16"},
+		{Columnar(&elems, field), "Columnar"},
+		{ParallelColumnar(&elems, 2, field), "ParallelColumnar"},
+		{If(&b, Bool(&b)), "If"},
+		{Switch(&u8, nil), "Switch"},
+		{Group(), "Group"},
+		{Padding(2), "Padding"},
+		{FillPadding(2, 0xff), "FillPadding"},
+		{CheckedPadding(2, 0xff), "CheckedPadding"},
+		{Align(4), "Align"},
+		{Const([]byte("x")), "Const"},
+		{Byte(&u8), "Byte"},
+		{Bool(&b), "Bool"},
+		{Flags(&b), "Flags"},
+		{Flags16(&b), "Flags16"},
+		{Flags32(&b), "Flags32"},
+		{Uvarint16(&u16), "Uvarint16"},
+		{OrdVarint64(&i64), "OrdVarint64"},
+		{Enum(&u8), "Enum"},
+		{Enum16(&u16), "Enum16"},
+		{GRPCFrame(Bool(&b)), "GRPCFrame"},
+		{GRPCFrameCompressed(Bool(&b), &b, Flate(1)), "GRPCFrameCompressed"},
+		{ULEB128(&u64), "Uvarint64"},
+		{SLEB128(&i64), "SLEB128"},
+		{ptr, "Pointer"},
+		{data, "Pointer"},
+		{SkipLengthDelim(), "SkipLengthDelim"},
+		{Strings(&[]string{}), "Strings"},
+		{SQLiteVarint(&u64), "SQLiteVarint"},
+		{NTPTimestamp(&tm), "NTPTimestamp"},
+		{Timespec(&tm, 8), "Timespec"},
+		{Timeval(&tm, 8), "Timeval"},
+		{DOSDateTime(&tm), "DOSDateTime"},
+		{ISO8601(&tm, 0), "ISO8601"},
+		{TLVs(nil, TLVFormat{}), "TLVs"},
+		{TLVRecord(1, Bool(&b), TLVFormat{}), "TLVRecord"},
+		{Trailing(Bool(&b), func() {}), "Trailing"},
+		{TrailingDefault(Bool(&b), func() bool { return true }, func() {}), "TrailingDefault"},
+		{Remainder(&bs), "Remainder"},
+		{VLQ(&u64), "VLQ"},
+		{Versioned(&u64, nil), "Versioned"},
+	} {
+		require.Equal(t, tt.kind, itemKind(tt.item))
+	}
+}
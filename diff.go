@@ -0,0 +1,120 @@
+package encode
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// A field that differs between the two buffers given to Diff.
+type FieldDiff struct {
+	// The index of the item, with the items of a Group numbered as in Describe, like "2.1".
+	Index string
+	// The name given to the item with Named, or "" if it wasn't given one.
+	Name string
+	// The kind of the item, as in Describe.
+	Kind string
+	// The encoded bytes of the item in each buffer.
+	A []byte
+	B []byte
+	// The value the item decoded to from each buffer, or nil if the item isn't bound to a single
+	// value, like Flags and Columnar.
+	ValueA interface{}
+	ValueB interface{}
+}
+
+func (d FieldDiff) String() string {
+	label := d.Index
+	if d.Name != "" {
+		label += " " + d.Name
+	}
+	if d.ValueA != nil && d.ValueB != nil {
+		return fmt.Sprintf("%s %s: %v -> %v", label, d.Kind, d.ValueA, d.ValueB)
+	}
+	return fmt.Sprintf("%s %s: %x -> %x", label, d.Kind, d.A, d.B)
+}
+
+// Diff decodes a and b into enc in turn and returns the fields whose encodings differ between
+// them, for example to find what differs between two replicas of a record. The items of a Group
+// are compared individually. enc is left holding the values decoded from b.
+func (enc Encoding) Diff(a []byte, b []byte) ([]FieldDiff, error) {
+	fieldsA, err := diffFields(enc.items, a)
+	if err != nil {
+		return nil, fmt.Errorf("a: %w", err)
+	}
+	fieldsB, err := diffFields(enc.items, b)
+	if err != nil {
+		return nil, fmt.Errorf("b: %w", err)
+	}
+	var diffs []FieldDiff
+	for i := range fieldsA {
+		fa, fb := fieldsA[i], fieldsB[i]
+		if bytes.Equal(fa.A, fb.A) {
+			continue
+		}
+		fa.B, fa.ValueB = fb.A, fb.ValueA
+		diffs = append(diffs, fa)
+	}
+	return diffs, nil
+}
+
+// Decodes every item from buf, returning each item that isn't a Group with its encoding and value
+// in A and ValueA. Items are decoded in order by the same walk as Describe, so the results for two
+// buffers line up.
+func diffFields(items []Item, buf []byte) ([]FieldDiff, error) {
+	var fields []FieldDiff
+	_, err := diffItems(&fields, "", items, buf, 0)
+	return fields, err
+}
+
+func diffItems(fields *[]FieldDiff, prefix string, items []Item, buf []byte, i int) (int, error) {
+	for idx, item := range items {
+		setOffset(item, i)
+		field := FieldDiff{Index: prefix + fmt.Sprint(idx)}
+		inner := item
+		if n, ok := item.(named); ok {
+			field.Name = n.name
+			inner = n.item
+		}
+		if g, ok := inner.(group); ok {
+			var err error
+			i, err = diffItems(fields, field.Index+".", g.items, buf, i)
+			if err != nil {
+				return i, err
+			}
+			continue
+		}
+		field.Kind = itemKind(inner)
+		err := item.Decode(buf[i:])
+		if err != nil {
+			return i, fmt.Errorf("%s %s: %w", field.Index, field.Kind, err)
+		}
+		end := minInt(i+item.Size(), len(buf))
+		field.A = buf[i:end]
+		field.ValueA = itemValue(inner)
+		*fields = append(*fields, field)
+		i = end
+	}
+	return i, nil
+}
+
+// Returns a copy of the value that item is bound to, if it's one of the items in this package that
+// keeps a pointer to its value in a field named v.
+func itemValue(item Item) interface{} {
+	rv := reflect.ValueOf(item)
+	if rv.Kind() != reflect.Struct || rv.Type().PkgPath() != reflect.TypeOf(group{}).PkgPath() {
+		return nil
+	}
+	v := rv.FieldByName("v")
+	if !v.IsValid() || v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	// v is unexported, so it can't be dereferenced through reflect directly.
+	elem := reflect.NewAt(v.Type().Elem(), unsafe.Pointer(v.Pointer())).Elem()
+	if b, ok := elem.Interface().([]byte); ok {
+		// Decoding the other buffer may reuse this one's backing array.
+		return append([]byte(nil), b...)
+	}
+	return elem.Interface()
+}
@@ -0,0 +1,46 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	var id uint64
+	var name string
+	var a, b bool
+	var payload []byte
+	enc := New(
+		Named("id", FixedUint64(&id)),
+		Named("name", LengthDelimString(&name)),
+		Group(Flags(&a, &b), Named("payload", LengthDelimBytes(&payload))),
+	)
+
+	id, name, a, b, payload = 1, "alice", true, false, []byte{1, 2}
+	bufA := enc.Encode()
+	name, b, payload = "bob", true, []byte{1, 3}
+	bufB := enc.Encode()
+
+	diffs, err := enc.Diff(bufA, bufB)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	require.Equal(t, FieldDiff{
+		Index:  "1",
+		Name:   "name",
+		Kind:   "LengthDelimString",
+		A:      []byte("\x05alice"),
+		B:      []byte("\x03bob"),
+		ValueA: "alice",
+		ValueB: "bob",
+	}, diffs[0])
+	require.Equal(t, "2.0 Flags: 01 -> 03", diffs[1].String())
+	require.Equal(t, "2.1 payload LengthDelimBytes: [1 2] -> [1 3]", diffs[2].String())
+
+	diffs, err = enc.Diff(bufA, bufA)
+	require.NoError(t, err)
+	require.Len(t, diffs, 0)
+
+	_, err = enc.Diff(bufA, bufB[:10])
+	require.ErrorIs(t, err, ErrShortBuffer)
+}
@@ -0,0 +1,83 @@
+package encode
+
+import (
+	"fmt"
+	"strings"
+)
+
+const dumpBytesPerLine = 16
+
+// Dump decodes buf into enc like Decode, and returns a hexdump of buf with each range of bytes
+// labeled with the index, name (see Named), and kind of the item it was decoded by, for example:
+//
+//   0000  00 00 00 00 00 00 00 2a                          0 id FixedUint64
+//   0008  05 68 65 6c 6c 6f                                1 name LengthDelimString
+//   000e  ff                                               (trailing)
+//
+// The items of a Group are labeled individually. If decoding fails, the bytes from the failed
+// item onward are labeled with the error instead. This is meant for debugging interop with other
+// implementations of a format.
+func (enc Encoding) Dump(buf []byte) string {
+	var sb strings.Builder
+	i, err := dumpItems(&sb, "", enc.items, buf, 0)
+	if err != nil {
+		dumpRange(&sb, buf, i, len(buf), "error: "+err.Error())
+	} else if i < len(buf) {
+		dumpRange(&sb, buf, i, len(buf), "(trailing)")
+	}
+	return sb.String()
+}
+
+// Decodes items from buf[i:], writing each item's range to sb. Returns the index in buf after the
+// last item successfully decoded.
+func dumpItems(sb *strings.Builder, prefix string, items []Item, buf []byte, i int) (int, error) {
+	for idx, item := range items {
+		setOffset(item, i)
+		label := prefix + fmt.Sprint(idx)
+		inner := item
+		if n, ok := item.(named); ok {
+			label += " " + n.name
+			inner = n.item
+		}
+		if g, ok := inner.(group); ok {
+			var err error
+			i, err = dumpItems(sb, label+".", g.items, buf, i)
+			if err != nil {
+				return i, err
+			}
+			continue
+		}
+		err := item.Decode(buf[i:])
+		if err != nil {
+			return i, fmt.Errorf("%s %s: %w", label, itemKind(inner), err)
+		}
+		end := i + item.Size()
+		if end > len(buf) {
+			end = len(buf)
+		}
+		dumpRange(sb, buf, i, end, label+" "+itemKind(inner))
+		i = end
+	}
+	return i, nil
+}
+
+// Writes buf[start:end] to sb as hex, dumpBytesPerLine bytes to a line, with label beside the
+// first line.
+func dumpRange(sb *strings.Builder, buf []byte, start int, end int, label string) {
+	if start == end {
+		fmt.Fprintf(sb, "%04x  %-*s  %s\n", start, dumpBytesPerLine*3-1, "", label)
+		return
+	}
+	for i := start; i < end; i += dumpBytesPerLine {
+		lineEnd := minInt(i+dumpBytesPerLine, end)
+		hex := make([]string, 0, dumpBytesPerLine)
+		for _, b := range buf[i:lineEnd] {
+			hex = append(hex, fmt.Sprintf("%02x", b))
+		}
+		if i == start {
+			fmt.Fprintf(sb, "%04x  %-*s  %s\n", i, dumpBytesPerLine*3-1, strings.Join(hex, " "), label)
+		} else {
+			fmt.Fprintf(sb, "%04x  %s\n", i, strings.Join(hex, " "))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package encode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	var id uint64
+	var name string
+	var a, b uint16
+	var blob []byte
+	enc := New(
+		Named("id", FixedUint64(&id)),
+		Named("name", LengthDelimString(&name)),
+		Group(FixedUint16(&a), Named("b", FixedUint16(&b))),
+		LengthDelimBytes(&blob),
+	)
+	id, name, a, b, blob = 42, "hello", 1, 2, bytes.Repeat([]byte{0xab}, 17)
+	buf := append(enc.Encode(), 0xff)
+
+	require.Equal(
+		t,
+		strings.Join([]string{
+			"0000  00 00 00 00 00 00 00 2a                          0 id FixedUint64",
+			"0008  05 68 65 6c 6c 6f                                1 name LengthDelimString",
+			"000e  00 01                                            2.0 FixedUint16",
+			"0010  00 02                                            2.1 b FixedUint16",
+			"0012  11 ab ab ab ab ab ab ab ab ab ab ab ab ab ab ab  3 LengthDelimBytes",
+			"0022  ab ab",
+			"0024  ff                                               (trailing)",
+			"",
+		}, "\n"),
+		enc.Dump(buf),
+	)
+
+	require.Equal(
+		t,
+		strings.Join([]string{
+			"0000  00 00 00 00 00 00 00 2a                          0 id FixedUint64",
+			"0008  05 68 65                                         error: 1 name LengthDelimString: " +
+				"encode: buffer too short",
+			"",
+		}, "\n"),
+		enc.Dump(buf[:11]),
+	)
+}
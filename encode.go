@@ -28,15 +28,14 @@ package encode
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
-	"io"
+	"fmt"
 	"math"
 	"math/bits"
 )
 
-var ErrOverflowVarint = errors.New("encode: overflowed varint")
-var ErrInvalidBool = errors.New("encode: invalid bool, encoded value not 0 or 1")
-var ErrInvalidVarint = errors.New("encode: invalid varint")
+var ErrInvalidBool = NewInvalidValueError("encode: invalid bool, encoded value not 0 or 1")
+var ErrInvalidVarint = NewInvalidValueError("encode: invalid varint")
+var ErrInvalidPadding = NewInvalidValueError("encode: padding contains unexpected bytes")
 
 type Item interface {
 	// Encode this item into buf. buf will be at least Size() bytes.
@@ -47,6 +46,9 @@ type Item interface {
 	Size() int
 }
 
+// An Encoding's items are bound to the fields of one value, and some hold state between Size and
+// Encode, so an Encoding must not be used from more than one goroutine at a time. To share the
+// definition of an encoding between goroutines, use a Schema instead.
 type Encoding struct {
 	items []Item
 }
@@ -56,49 +58,321 @@ func New(items ...Item) Encoding {
 }
 
 func (enc Encoding) Encode() []byte {
-	totalSize := 0
-	for _, item := range enc.items {
-		totalSize += item.Size()
+	return enc.Append(nil)
+}
+
+// Append the encoding to dst, returning the extended buffer. dst is only reallocated if it doesn't
+// have enough spare capacity, so reusing one buffer across calls, as in
+//
+//   buf = enc.Append(buf[:0])
+//
+// encodes without allocating once buf has grown to fit. The result aliases dst, so copy it out
+// before reusing the buffer if it needs to be kept.
+func (enc Encoding) Append(dst []byte) []byte {
+	totalSize := sizeItems(enc.items, 0)
+	start := len(dst)
+	if cap(dst)-start < totalSize {
+		grown := make([]byte, start, start+totalSize)
+		copy(grown, dst)
+		dst = grown
+	}
+	buf := dst[start : start+totalSize]
+	// Items only set the bits they need, so buf must start out zeroed.
+	for j := range buf {
+		buf[j] = 0
+	}
+	encodeItems(enc.items, buf, 0)
+	return dst[:start+totalSize]
+}
+
+// Append the encoding to the end of b, growing it if necessary. This is useful for building a
+// message from a header and several sub-messages without allocating a slice for each.
+func (enc Encoding) EncodeBuffer(b *bytes.Buffer) {
+	b.Grow(sizeItems(enc.items, 0))
+	b.Write(enc.Append(b.AvailableBuffer()))
+}
+
+// Decode buf into the values bound to enc's items.
+//
+// Decode never writes to buf, so it's safe to decode from memory that's read-only, like a file
+// mapped with mmap and PROT_READ. Combined with BytesView and UnsafeStringView, this parses a
+// mapped file without copying its fields out of it. Items from outside this package, and Codecs
+// given to Compressed, must make the same guarantee for it to hold.
+func (enc Encoding) Decode(buf []byte) error {
+	return decodeItems(enc.items, buf, 0, nil)
+}
+
+// Implemented by items whose size depends on where they are in the encoding, like Align, and by
+// items that contain other items, which pass the offsets of their contents along. offset is
+// relative to the start of the whole encoding, and is set before each call to Size, Encode, or
+// Decode. Contents that are delimited or transformed on their own, like those of Compressed and
+// TLVRecord, are given offsets relative to their own start instead.
+type offsetItem interface {
+	setOffset(offset int)
+}
+
+func setOffset(item Item, offset int) {
+	if o, ok := item.(offsetItem); ok {
+		o.setOffset(offset)
+	}
+}
+
+// base is the offset of the first of items from the start of the encoding, as for setOffset.
+func sizeItems(items []Item, base int) int {
+	size := 0
+	for _, item := range items {
+		setOffset(item, base+size)
+		size += item.Size()
 	}
-	buf := make([]byte, totalSize)
+	return size
+}
+
+func encodeItems(items []Item, buf []byte, base int) {
 	i := 0
-	for _, item := range enc.items {
+	for _, item := range items {
+		setOffset(item, base+i)
 		size := item.Size()
 		item.Encode(buf[i : i+size])
 		i += size
 	}
-	return buf
 }
 
-func (enc Encoding) Decode(buf []byte) error {
+func decodeItems(items []Item, buf []byte, base int, s *decodeState) error {
 	i := 0
-	for _, item := range enc.items {
-		err := item.Decode(buf[i:])
+	for _, item := range items {
+		setOffset(item, base+i)
+		err := decodeItem(item, buf[i:], s)
 		if err != nil {
 			return err
 		}
 		i += item.Size()
+		// Items that were absent from buf, like Trailing, still report their size.
+		if i > len(buf) {
+			i = len(buf)
+		}
 	}
 	return nil
 }
 
+// Encode items one after another, as a single Item. This is useful for passing several items to
+// something that wraps one, like Compressed.
+func Group(items ...Item) Item {
+	return group{items: items, offset: new(int)}
+}
+
+type group struct {
+	items []Item
+	// Where the group is in the encoding, as of its last call to setOffset.
+	offset *int
+}
+
+func (e group) setOffset(offset int) {
+	*e.offset = offset
+}
+func (e group) Encode(buf []byte) {
+	encodeItems(e.items, buf, *e.offset)
+}
+func (e group) Size() int {
+	return sizeItems(e.items, *e.offset)
+}
+func (e group) kind() string {
+	return "Group"
+}
+func (e group) withByteOrder(order binary.ByteOrder) Item {
+	return Group(itemsWithByteOrder(e.items, order)...)
+}
+func (e group) sizeBounds() (int, int) {
+	min, max := 0, 0
+	for _, item := range e.items {
+		itemMin, itemMax := sizeBounds(item)
+		min, max = min+itemMin, addMaxSize(max, itemMax)
+	}
+	return min, max
+}
+func (e group) dependsOnEarlier() bool {
+	return anyDependsOnEarlier(e.items)
+}
+func (e group) skip(buf []byte) (int, error) {
+	i := 0
+	for _, item := range e.items {
+		setOffset(item, *e.offset+i)
+		n, err := skipItem(item, buf[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	return i, nil
+}
+func (e group) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e group) decodeLimited(buf []byte, s *decodeState) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+	return decodeItems(e.items, buf, *e.offset, s)
+}
+
 // Quietly ignore n bytes.
 func Padding(n int) TupleItem {
-	return padding{n}
+	return padding{n: n}
+}
+
+// Write n copies of fill, and quietly ignore n bytes on decode. Useful for formats that expect
+// unused space to have a particular value, like 0xFF for erased flash.
+func FillPadding(n int, fill byte) TupleItem {
+	return padding{n: n, fill: fill}
 }
 
-type padding struct{ n int }
+// Write n copies of fill, and on decode return ErrInvalidPadding if any of the n bytes is not
+// fill. This catches records that are corrupted or misaligned.
+func CheckedPadding(n int, fill byte) TupleItem {
+	return padding{n: n, fill: fill, check: true}
+}
+
+type padding struct {
+	n     int
+	fill  byte
+	check bool
+}
 
 func (e padding) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
 func (e padding) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
 func (e padding) SizeTuple(last bool) int                 { return e.Size() }
-func (e padding) Encode(buf []byte)                       {}
+func (e padding) Encode(buf []byte) {
+	if e.fill != 0 {
+		for i := 0; i < e.n; i++ {
+			buf[i] = e.fill
+		}
+	}
+}
 func (e padding) Size() int {
 	return e.n
 }
+func (e padding) kind() string {
+	switch {
+	case e.check:
+		return "CheckedPadding"
+	case e.fill != 0:
+		return "FillPadding"
+	default:
+		return "Padding"
+	}
+}
+func (e padding) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e padding) fixedSize() int {
+	return e.Size()
+}
 func (e padding) Decode(buf []byte) error {
 	if len(buf) < e.n {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
+	}
+	if e.check {
+		for i := 0; i < e.n; i++ {
+			if buf[i] != e.fill {
+				return ErrInvalidPadding
+			}
+		}
+	}
+	return nil
+}
+
+// Pad with zeroes up to the next multiple of n bytes from the start of the encoding, taking into
+// account the sizes of any variable-length items before it. On decode, the padding is skipped.
+// Panics if n is not positive.
+//
+// The item remembers where it was last placed, so like the Encoding that contains it, it must not
+// be used from more than one goroutine at a time.
+func Align(n int) Item {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid n=%d, must be positive", n))
+	}
+	return align{n: n, offset: new(int)}
+}
+
+type align struct {
+	n      int
+	offset *int
+}
+
+func (e align) setOffset(offset int) {
+	*e.offset = offset
+}
+func (e align) Encode(buf []byte) {}
+func (e align) Size() int {
+	return (e.n - *e.offset%e.n) % e.n
+}
+func (e align) kind() string {
+	return "Align"
+}
+func (e align) sizeBounds() (min, max int) {
+	return 0, e.n - 1
+}
+func (e align) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e align) Decode(buf []byte) error {
+	if len(buf) < e.Size() {
+		return ErrShortBuffer
+	}
+	return nil
+}
+
+// Write the bytes b on encode, and on decode return a *ConstMismatchError if the buffer doesn't
+// contain exactly b. Useful for magic numbers and format markers, like "RIFF".
+func Const(b []byte) TupleItem {
+	return encConst{b}
+}
+
+// Returned from decoding Const when the bytes found don't match.
+type ConstMismatchError struct {
+	Expected []byte
+	Actual   []byte
+}
+
+func (err *ConstMismatchError) Error() string {
+	return fmt.Sprintf("encode: expected constant %x, found %x", err.Expected, err.Actual)
+}
+
+func (err *ConstMismatchError) Is(target error) bool {
+	return target == ErrInvalidValue
+}
+
+type encConst struct{ b []byte }
+
+func (e encConst) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e encConst) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e encConst) SizeTuple(last bool) int                 { return e.Size() }
+func (e encConst) OrderPreserving()                        {}
+func (e encConst) Encode(buf []byte) {
+	copy(buf, e.b)
+}
+func (e encConst) Size() int {
+	return len(e.b)
+}
+func (e encConst) kind() string {
+	return "Const"
+}
+func (e encConst) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e encConst) fixedSize() int {
+	return e.Size()
+}
+func (e encConst) Decode(buf []byte) error {
+	if len(buf) < len(e.b) {
+		return ErrShortBuffer
+	}
+	if !bytes.Equal(buf[:len(e.b)], e.b) {
+		return &ConstMismatchError{
+			Expected: e.b,
+			Actual:   append([]byte(nil), buf[:len(e.b)]...),
+		}
 	}
 	return nil
 }
@@ -119,9 +393,18 @@ func (e encByte) Encode(buf []byte) {
 func (e encByte) Size() int {
 	return 1
 }
+func (e encByte) kind() string {
+	return "Byte"
+}
+func (e encByte) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e encByte) fixedSize() int {
+	return e.Size()
+}
 func (e encByte) Decode(buf []byte) error {
 	if len(buf) < 1 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	*e.v = buf[0]
 	return nil
@@ -145,9 +428,18 @@ func (e encBool) Encode(buf []byte) {
 func (e encBool) Size() int {
 	return 1
 }
+func (e encBool) kind() string {
+	return "Bool"
+}
+func (e encBool) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e encBool) fixedSize() int {
+	return e.Size()
+}
 func (e encBool) Decode(buf []byte) error {
 	if len(buf) < 1 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	switch buf[0] {
 	case 0:
@@ -160,6 +452,92 @@ func (e encBool) Decode(buf []byte) error {
 	return nil
 }
 
+// Encode up to 8 bools as the bits of a single byte, with v[0] as the lowest-order bit. Bits not
+// assigned a bool are written as 0 and ignored on decode.
+func Flags(v ...*bool) Item {
+	return newFlags(v, 1)
+}
+
+// Encode up to 16 bools as the bits of a big endian uint16, with v[0] as the lowest-order bit.
+func Flags16(v ...*bool) Item {
+	return newFlags(v, 2)
+}
+
+// Encode up to 32 bools as the bits of a big endian uint32, with v[0] as the lowest-order bit.
+func Flags32(v ...*bool) Item {
+	return newFlags(v, 4)
+}
+
+func newFlags(v []*bool, size int) Item {
+	if len(v) > size*8 {
+		panic(fmt.Sprintf("too many flags=%d, must be at most %d", len(v), size*8))
+	}
+	return flags{v: v, size: size, order: binary.BigEndian}
+}
+
+type flags struct {
+	v     []*bool
+	size  int
+	order binary.ByteOrder
+}
+
+func (e flags) Encode(buf []byte) {
+	var x uint32
+	for i, v := range e.v {
+		if *v {
+			x |= 1 << uint(i)
+		}
+	}
+	switch e.size {
+	case 1:
+		buf[0] = byte(x)
+	case 2:
+		e.order.PutUint16(buf, uint16(x))
+	case 4:
+		e.order.PutUint32(buf, x)
+	}
+}
+func (e flags) Size() int {
+	return e.size
+}
+func (e flags) kind() string {
+	switch e.size {
+	case 2:
+		return "Flags16"
+	case 4:
+		return "Flags32"
+	default:
+		return "Flags"
+	}
+}
+func (e flags) withByteOrder(order binary.ByteOrder) Item {
+	return flags{v: e.v, size: e.size, order: order}
+}
+func (e flags) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e flags) fixedSize() int {
+	return e.Size()
+}
+func (e flags) Decode(buf []byte) error {
+	if len(buf) < e.size {
+		return ErrShortBuffer
+	}
+	var x uint32
+	switch e.size {
+	case 1:
+		x = uint32(buf[0])
+	case 2:
+		x = uint32(e.order.Uint16(buf))
+	case 4:
+		x = e.order.Uint32(buf)
+	}
+	for i, v := range e.v {
+		*v = x&(1<<uint(i)) != 0
+	}
+	return nil
+}
+
 // Encode v in big endian order, taking 2 bytes.
 func FixedUint16(v *uint16) TupleItem {
 	return fixedUint16{v}
@@ -176,9 +554,21 @@ func (e fixedUint16) Encode(buf []byte) {
 func (e fixedUint16) Size() int {
 	return 2
 }
+func (e fixedUint16) kind() string {
+	return "FixedUint16"
+}
+func (e fixedUint16) withByteOrder(order binary.ByteOrder) Item {
+	return uint16Order{v: e.v, order: order}
+}
+func (e fixedUint16) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e fixedUint16) fixedSize() int {
+	return e.Size()
+}
 func (e fixedUint16) Decode(buf []byte) error {
 	if len(buf) < 2 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	*e.v = binary.BigEndian.Uint16(buf)
 	return nil
@@ -200,9 +590,21 @@ func (e fixedUint32) Encode(buf []byte) {
 func (e fixedUint32) Size() int {
 	return 4
 }
+func (e fixedUint32) kind() string {
+	return "FixedUint32"
+}
+func (e fixedUint32) withByteOrder(order binary.ByteOrder) Item {
+	return uint32Order{v: e.v, order: order}
+}
+func (e fixedUint32) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e fixedUint32) fixedSize() int {
+	return e.Size()
+}
 func (e fixedUint32) Decode(buf []byte) error {
 	if len(buf) < 4 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	*e.v = binary.BigEndian.Uint32(buf)
 	return nil
@@ -224,14 +626,75 @@ func (e fixedUint64) Encode(buf []byte) {
 func (e fixedUint64) Size() int {
 	return 8
 }
+func (e fixedUint64) kind() string {
+	return "FixedUint64"
+}
+func (e fixedUint64) withByteOrder(order binary.ByteOrder) Item {
+	return uint64Order{v: e.v, order: order}
+}
+func (e fixedUint64) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e fixedUint64) fixedSize() int {
+	return e.Size()
+}
 func (e fixedUint64) Decode(buf []byte) error {
 	if len(buf) < 8 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	*e.v = binary.BigEndian.Uint64(buf)
 	return nil
 }
 
+// Encode v using a variable-length encoding, so that smaller numbers use fewer bytes. Decode
+// returns ErrVarintOverflow if the encoded value doesn't fit in 16 bits.
+//
+// See more at https://developers.google.com/protocol-buffers/docs/encoding#varints
+//
+//   input bits
+//   high order       low order
+//   zzyyyyyyyxxxxxxx
+//
+//   min     max          encoded size     encoding
+//   0       2^7 - 1      1                0xxxxxxx
+//   2^7     2^14 - 1     2                1xxxxxxx 0yyyyyyy
+//   2^14    2^16 - 1     3                1xxxxxxx 1yyyyyyy 000000zz
+func Uvarint16(v *uint16) Item {
+	return uvarint16{v}
+}
+
+type uvarint16 struct{ v *uint16 }
+
+func (e uvarint16) Encode(buf []byte) {
+	binary.PutUvarint(buf, uint64(*e.v))
+}
+func (e uvarint16) Size() int {
+	return uvarintSize(uint64(*e.v))
+}
+func (e uvarint16) kind() string {
+	return "Uvarint16"
+}
+func (e uvarint16) sizeBounds() (min, max int) {
+	return 1, 3
+}
+func (e uvarint16) skip(buf []byte) (int, error) {
+	return skipUvarint(buf)
+}
+func (e uvarint16) Decode(buf []byte) error {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	if l > math.MaxUint16 {
+		return ErrVarintOverflow
+	}
+	*e.v = uint16(l)
+	return nil
+}
+
 // Encode v using a variable-length encoding, so that smaller numbers use fewer bytes.
 //
 // See more at https://developers.google.com/protocol-buffers/docs/encoding#varints
@@ -258,16 +721,25 @@ func (e uvarint32) Encode(buf []byte) {
 func (e uvarint32) Size() int {
 	return uvarintSize(uint64(*e.v))
 }
+func (e uvarint32) kind() string {
+	return "Uvarint32"
+}
+func (e uvarint32) sizeBounds() (min, max int) {
+	return 1, binary.MaxVarintLen32
+}
+func (e uvarint32) skip(buf []byte) (int, error) {
+	return skipUvarint(buf)
+}
 func (e uvarint32) Decode(buf []byte) error {
 	l, n := binary.Uvarint(buf)
 	if n == 0 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	if n < 0 {
-		return ErrOverflowVarint
+		return ErrVarintOverflow
 	}
 	if n > math.MaxUint32 {
-		return ErrOverflowVarint
+		return ErrVarintOverflow
 	}
 	*e.v = uint32(l)
 	return nil
@@ -304,13 +776,22 @@ func (e uvarint64) Encode(buf []byte) {
 func (e uvarint64) Size() int {
 	return uvarintSize(*e.v)
 }
+func (e uvarint64) kind() string {
+	return "Uvarint64"
+}
+func (e uvarint64) sizeBounds() (min, max int) {
+	return 1, binary.MaxVarintLen64
+}
+func (e uvarint64) skip(buf []byte) (int, error) {
+	return skipUvarint(buf)
+}
 func (e uvarint64) Decode(buf []byte) error {
 	l, n := binary.Uvarint(buf)
 	if n == 0 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	if n < 0 {
-		return ErrOverflowVarint
+		return ErrVarintOverflow
 	}
 	*e.v = l
 	return nil
@@ -365,9 +846,15 @@ func (e ordUvarint64) Size() int {
 	}
 	return 1 + (l-1)/7
 }
+func (e ordUvarint64) kind() string {
+	return "OrdUvarint64"
+}
+func (e ordUvarint64) sizeBounds() (min, max int) {
+	return 1, 9
+}
 func (e ordUvarint64) Decode(buf []byte) error {
 	if len(buf) < 1 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	nLeadingOnes := bits.LeadingZeros8(^buf[0])
 	nBytes := nLeadingOnes + 1
@@ -376,14 +863,14 @@ func (e ordUvarint64) Decode(buf []byte) error {
 
 	if rBits == 63 {
 		if len(buf) < 9 {
-			return io.ErrUnexpectedEOF
+			return ErrShortBuffer
 		}
 		*e.v = binary.BigEndian.Uint64(buf[1:])
 		return nil
 	}
 
 	if len(buf) < nBytes {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	result := uint64(0)
 	for i := 0; i < nBytes; i++ {
@@ -553,23 +1040,29 @@ func (e ordVarint64) Size() int {
 	l := bits.Len64(uv ^ signMask)
 	return 1 + l/7 - l/63
 }
+func (e ordVarint64) kind() string {
+	return "OrdVarint64"
+}
+func (e ordVarint64) sizeBounds() (min, max int) {
+	return 1, 9
+}
 func (e ordVarint64) DecodeTuple(buf []byte, last bool) error {
 	return e.Decode(buf)
 }
 func (e ordVarint64) Decode(buf []byte) error {
 	if len(buf) < 1 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	switch buf[0] & 0x80 {
 	case 0:
 		switch {
 		case buf[0] == 0x00:
 			if len(buf) < 8 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			if buf[1]&0x80 == 0 {
 				if len(buf) < 9 {
-					return io.ErrUnexpectedEOF
+					return ErrShortBuffer
 				}
 				*e.v = int64(uint64(0x8000000000000000) |
 					uint64(buf[1])<<56 |
@@ -592,7 +1085,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 			}
 		case buf[0] == 0x01:
 			if len(buf) < 7 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(uint64(0xFFFF000000000000) |
 				uint64(buf[1])<<40 |
@@ -603,7 +1096,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				uint64(buf[6]))
 		case buf[0]&0xFE == 0x02:
 			if len(buf) < 6 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(uint64(0xFFFFFE0000000000) |
 				uint64(buf[0]&0x01)<<40 |
@@ -614,7 +1107,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				uint64(buf[5]))
 		case buf[0]&0xFC == 0x04:
 			if len(buf) < 5 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(uint64(0xFFFFFFFC00000000) |
 				uint64(buf[0]&0x03)<<32 |
@@ -624,7 +1117,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				uint64(buf[4]))
 		case buf[0]&0xF8 == 0x08:
 			if len(buf) < 4 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(uint64(0xFFFFFFFFF8000000) |
 				uint64(buf[0]&0x07)<<24 |
@@ -633,7 +1126,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				uint64(buf[3]))
 		case buf[0]&0xF0 == 0x10:
 			if len(buf) < 3 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(uint64(0xFFFFFFFFFFF00000) |
 				uint64(buf[0]&0x0F)<<16 |
@@ -641,7 +1134,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				uint64(buf[2]))
 		case buf[0]&0xE0 == 0x20:
 			if len(buf) < 2 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(uint64(0xFFFFFFFFFFFFE000) |
 				uint64(buf[0]&0x1F)<<8 |
@@ -658,20 +1151,20 @@ func (e ordVarint64) Decode(buf []byte) error {
 			*e.v = int64(buf[0] & 0x3F)
 		case buf[0]&0xE0 == 0xC0:
 			if len(buf) < 2 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(buf[0]&0x1F)<<8 |
 				int64(buf[1])
 		case buf[0]&0xF0 == 0xE0:
 			if len(buf) < 3 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(buf[0]&0x0F)<<16 |
 				int64(buf[1])<<8 |
 				int64(buf[2])
 		case buf[0]&0xF8 == 0xF0:
 			if len(buf) < 4 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(buf[0]&0x07)<<24 |
 				int64(buf[1])<<16 |
@@ -679,7 +1172,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				int64(buf[3])
 		case buf[0]&0xFC == 0xF8:
 			if len(buf) < 5 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(buf[0]&0x03)<<32 |
 				int64(buf[1])<<24 |
@@ -688,7 +1181,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				int64(buf[4])
 		case buf[0]&0xFE == 0xFC:
 			if len(buf) < 6 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(buf[0]&0x01)<<40 |
 				int64(buf[1])<<32 |
@@ -698,7 +1191,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				int64(buf[5])
 		case buf[0] == 0xFE:
 			if len(buf) < 7 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			*e.v = int64(buf[1])<<40 |
 				int64(buf[2])<<32 |
@@ -708,7 +1201,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 				int64(buf[6])
 		case buf[0] == 0xFF:
 			if len(buf) < 8 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			if buf[1]&0x80 == 0 {
 				*e.v = int64(buf[1])<<48 |
@@ -720,7 +1213,7 @@ func (e ordVarint64) Decode(buf []byte) error {
 					int64(buf[7])
 			} else {
 				if len(buf) < 9 {
-					return io.ErrUnexpectedEOF
+					return ErrShortBuffer
 				}
 				*e.v = int64(buf[1]&0x7F)<<56 |
 					int64(buf[2])<<48 |
@@ -740,6 +1233,118 @@ func (e ordVarint64) Decode(buf []byte) error {
 	return nil
 }
 
+// Encode v the same way as OrdUvarint64 encodes the same value, so the two can be used
+// interchangeably in keys, using at most 5 bytes. Decode returns ErrVarintOverflow if the encoded
+// value doesn't fit in 32 bits.
+func OrdUvarint32(v *uint32) TupleItem {
+	return ordUvarint32{v}
+}
+
+type ordUvarint32 struct{ v *uint32 }
+
+func (e ordUvarint32) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e ordUvarint32) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e ordUvarint32) SizeTuple(last bool) int                 { return e.Size() }
+func (e ordUvarint32) OrderPreserving()                        {}
+func (e ordUvarint32) Encode(buf []byte) {
+	x := uint64(*e.v)
+	ordUvarint64{&x}.Encode(buf)
+}
+func (e ordUvarint32) Size() int {
+	x := uint64(*e.v)
+	return ordUvarint64{&x}.Size()
+}
+func (e ordUvarint32) kind() string {
+	return "OrdUvarint32"
+}
+func (e ordUvarint32) sizeBounds() (min, max int) {
+	return 1, 5
+}
+func (e ordUvarint32) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return ErrShortBuffer
+	}
+	// The number of leading ones in the first byte is the number of bytes after it, and the rest of
+	// the bits after the zero that ends them are the value.
+	nBytes := bits.LeadingZeros8(^buf[0]) + 1
+	if nBytes > 5 {
+		return ErrVarintOverflow
+	}
+	if len(buf) < nBytes {
+		return ErrShortBuffer
+	}
+	x := uint64(buf[0] & (0xFF >> uint(nBytes)))
+	for i := 1; i < nBytes; i++ {
+		x = x<<8 | uint64(buf[i])
+	}
+	if x > math.MaxUint32 {
+		return ErrVarintOverflow
+	}
+	*e.v = uint32(x)
+	return nil
+}
+
+// Encode v the same way as OrdVarint64 encodes the same value, so the two can be used
+// interchangeably in keys, using at most 5 bytes. Decode returns ErrVarintOverflow if the encoded
+// value doesn't fit in 32 bits.
+func OrdVarint32(v *int32) TupleItem {
+	return ordVarint32{v}
+}
+
+type ordVarint32 struct{ v *int32 }
+
+func (e ordVarint32) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e ordVarint32) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e ordVarint32) SizeTuple(last bool) int                 { return e.Size() }
+func (e ordVarint32) OrderPreserving()                        {}
+func (e ordVarint32) Encode(buf []byte) {
+	x := int64(*e.v)
+	ordVarint64{&x}.Encode(buf)
+}
+func (e ordVarint32) Size() int {
+	x := int64(*e.v)
+	return ordVarint64{&x}.Size()
+}
+func (e ordVarint32) kind() string {
+	return "OrdVarint32"
+}
+func (e ordVarint32) sizeBounds() (min, max int) {
+	return 1, 5
+}
+func (e ordVarint32) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return ErrShortBuffer
+	}
+	// Negative numbers are encoded as the complement of a non-negative one, so complement them back
+	// and decode both the same way: the number of leading ones is the number of bytes, and the rest
+	// of the bits after the zero that ends them are the value.
+	var flip byte
+	if buf[0]&0x80 == 0 {
+		flip = 0xFF
+	}
+	b := buf[0] ^ flip
+	nBytes := bits.LeadingZeros8(^b)
+	if nBytes > 5 {
+		return ErrVarintOverflow
+	}
+	if len(buf) < nBytes {
+		return ErrShortBuffer
+	}
+	x := uint64(b & (0xFF >> uint(nBytes+1)))
+	for i := 1; i < nBytes; i++ {
+		x = x<<8 | uint64(buf[i]^flip)
+	}
+	v := int64(x)
+	if flip != 0 {
+		v = ^v
+	}
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return ErrVarintOverflow
+	}
+	*e.v = int32(v)
+	return nil
+}
+
 // Encodes v, using {delim,0x00} as the ending delimeter. delim is allowed to appear in v, and will
 // be escaped with a following 0xFF per occurrence.
 func DelimBytes(v *[]byte, delim byte) TupleItem {
@@ -773,6 +1378,12 @@ func (e delimBytes) EncodeTuple(buf []byte, last bool) {
 func (e delimBytes) Size() int {
 	return e.SizeTuple(false)
 }
+func (e delimBytes) kind() string {
+	return "DelimBytes"
+}
+func (e delimBytes) sizeBounds() (min, max int) {
+	return 2, -1
+}
 func (e delimBytes) SizeTuple(last bool) int {
 	// All of the bytes of the input, plus one byte to escape each occurrence of `delim`, plus two
 	// for the ending delimiter if it's not the end of a prefix.
@@ -792,7 +1403,7 @@ func (e delimBytes) DecodeTuple(buf []byte, last bool) error {
 		(*e.v)[i-j] = b
 		if b == e.delim && !(i == len(buf)-1 && last) {
 			if len(buf) <= i+1 {
-				return io.ErrUnexpectedEOF
+				return ErrShortBuffer
 			}
 			j++
 			i++
@@ -813,24 +1424,30 @@ type lengthDelimBytes struct{ v *[]byte }
 
 func (e lengthDelimBytes) Encode(buf []byte) {
 	n := binary.PutUvarint(buf, uint64(len(*e.v)))
-	copy(buf, (*e.v)[n:])
+	copy(buf[n:], *e.v)
 }
 func (e lengthDelimBytes) Size() int {
 	return uvarintSize(uint64(len(*e.v))) + len(*e.v)
 }
+func (e lengthDelimBytes) kind() string {
+	return "LengthDelimBytes"
+}
+func (e lengthDelimBytes) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e lengthDelimBytes) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
 func (e lengthDelimBytes) Decode(buf []byte) error {
-	l, n := binary.Uvarint(buf)
-	if n == 0 {
-		return io.ErrUnexpectedEOF
-	}
-	if n < 0 {
-		return ErrOverflowVarint
-	}
-	if uint64(len(buf[n:])) < l {
-		return io.ErrUnexpectedEOF
+	return e.decodeLimited(buf, nil)
+}
+func (e lengthDelimBytes) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
 	}
-	*e.v = make([]byte, l)
-	copy(buf[n:], *e.v)
+	*e.v = s.bytes(len(b))
+	copy(*e.v, b)
 	return nil
 }
 
@@ -843,23 +1460,29 @@ type lengthDelimString struct{ v *string }
 
 func (e lengthDelimString) Encode(buf []byte) {
 	n := binary.PutUvarint(buf, uint64(len(*e.v)))
-	copy(buf, (*e.v)[n:])
+	copy(buf[n:], *e.v)
 }
 func (e lengthDelimString) Size() int {
 	return uvarintSize(uint64(len(*e.v))) + len(*e.v)
 }
+func (e lengthDelimString) kind() string {
+	return "LengthDelimString"
+}
+func (e lengthDelimString) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e lengthDelimString) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
 func (e lengthDelimString) Decode(buf []byte) error {
-	l, n := binary.Uvarint(buf)
-	if n == 0 {
-		return io.ErrUnexpectedEOF
-	}
-	if n < 0 {
-		return ErrOverflowVarint
-	}
-	if uint64(len(buf[n:])) < l {
-		return io.ErrUnexpectedEOF
+	return e.decodeLimited(buf, nil)
+}
+func (e lengthDelimString) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
 	}
-	*e.v = string(buf[n:])
+	*e.v = s.string(b)
 	return nil
 }
 
@@ -879,9 +1502,18 @@ func (e bytes16) Encode(buf []byte) {
 func (e bytes16) Size() int {
 	return 16
 }
+func (e bytes16) kind() string {
+	return "Bytes16"
+}
+func (e bytes16) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e bytes16) fixedSize() int {
+	return e.Size()
+}
 func (e bytes16) Decode(buf []byte) error {
 	if len(buf) < 16 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	copy((*e.v)[:], buf[:16])
 	return nil
@@ -903,14 +1535,43 @@ func (e bytes32) Encode(buf []byte) {
 func (e bytes32) Size() int {
 	return 32
 }
+func (e bytes32) kind() string {
+	return "Bytes32"
+}
+func (e bytes32) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e bytes32) fixedSize() int {
+	return e.Size()
+}
 func (e bytes32) Decode(buf []byte) error {
 	if len(buf) < 32 {
-		return io.ErrUnexpectedEOF
+		return ErrShortBuffer
 	}
 	copy((*e.v)[:], buf[:32])
 	return nil
 }
 
+// Returns the bytes of a length-delimited field at the start of buf, without copying them.
+func readLengthDelim(buf []byte, s *decodeState) ([]byte, error) {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return nil, ErrShortBuffer
+	}
+	if n < 0 {
+		return nil, ErrVarintOverflow
+	}
+	err := s.checkLength(l)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(buf[n:])) < l {
+		return nil, ErrShortBuffer
+	}
+	end := n + int(l)
+	return buf[n:end:end], nil
+}
+
 func uvarintSize(x uint64) int {
 	var b [binary.MaxVarintLen64]byte
 	return binary.PutUvarint(b[:], x)
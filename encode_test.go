@@ -3,7 +3,11 @@ package encode
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
+	"io"
+	"math"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/bradenaw/trand"
@@ -193,3 +197,223 @@ func BenchmarkOrdUvarint64Decode(b *testing.B) {
 		_ = enc.Decode(bunchaEncoded[i%len(bunchaEncoded)])
 	}
 }
+
+func TestConst(t *testing.T) {
+	size := uint32(36)
+	enc := New(Const([]byte("RIFF")), FixedUint32(&size), Const([]byte("WAVE")))
+	b := enc.Encode()
+	require.Equal(t, []byte("RIFF\x00\x00\x00\x24WAVE"), b)
+
+	var size2 uint32
+	require.NoError(t, New(Const([]byte("RIFF")), FixedUint32(&size2), Const([]byte("WAVE"))).Decode(b))
+	require.Equal(t, size, size2)
+
+	err := New(Const([]byte("RIFX")), FixedUint32(&size2)).Decode(b)
+	var mismatch *ConstMismatchError
+	require.True(t, errors.As(err, &mismatch))
+	require.Equal(t, []byte("RIFF"), mismatch.Actual)
+}
+
+func TestAppend(t *testing.T) {
+	a := uint16(0x0102)
+	b := true
+	enc := New(FixedUint16(&a), Bool(&b))
+	require.Equal(t, []byte{0xFF, 0x01, 0x02, 0x01}, enc.Append([]byte{0xFF}))
+
+	buf := make([]byte, 0, 16)
+	for i := 0; i < 3; i++ {
+		// Leftover bytes in the spare capacity must not leak into the encoding.
+		b = false
+		buf = append(buf[:0], 0xFF, 0xFF, 0xFF)
+		buf = enc.Append(buf[:0])
+		require.Equal(t, []byte{0x01, 0x02, 0x00}, buf)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = enc.Append(buf[:0])
+	})
+	require.Equal(t, float64(0), allocs)
+}
+
+func TestEncodeBuffer(t *testing.T) {
+	a := uint16(0x0102)
+	s := "hi"
+	enc := New(FixedUint16(&a), LengthDelimString(&s))
+
+	var b bytes.Buffer
+	b.WriteString("hdr")
+	enc.EncodeBuffer(&b)
+	s = "hello"
+	enc.EncodeBuffer(&b)
+	require.Equal(t, []byte("hdr\x01\x02\x02hi\x01\x02\x05hello"), b.Bytes())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		b.Reset()
+		enc.EncodeBuffer(&b)
+	})
+	require.Equal(t, float64(0), allocs)
+}
+
+func TestFlags(t *testing.T) {
+	f := make([]bool, 10)
+	ptrs := make([]*bool, len(f))
+	for i := range f {
+		ptrs[i] = &f[i]
+	}
+	f[0] = true
+	f[2] = true
+	f[7] = true
+	require.Equal(t, []byte{0x85}, New(Flags(ptrs[:8]...)).Encode())
+	f[9] = true
+	require.Equal(t, []byte{0x02, 0x85}, New(Flags16(ptrs...)).Encode())
+	require.Equal(t, []byte{0x00, 0x00, 0x02, 0x85}, New(Flags32(ptrs...)).Encode())
+
+	f2 := make([]bool, 10)
+	ptrs2 := make([]*bool, len(f2))
+	for i := range f2 {
+		ptrs2[i] = &f2[i]
+	}
+	require.NoError(t, New(Flags16(ptrs2...)).Decode([]byte{0x02, 0x85}))
+	require.Equal(t, f, f2)
+
+	require.Panics(t, func() { Flags(ptrs...) })
+}
+
+func TestPadding(t *testing.T) {
+	a := byte(0x12)
+	require.Equal(t, []byte{0x00, 0x00, 0x12}, New(Padding(2), Byte(&a)).Encode())
+	require.Equal(t, []byte{0xFF, 0xFF, 0x12}, New(FillPadding(2, 0xFF), Byte(&a)).Encode())
+	require.Equal(t, []byte{0xFF, 0xFF, 0x12}, New(CheckedPadding(2, 0xFF), Byte(&a)).Encode())
+
+	require.NoError(t, New(FillPadding(2, 0xFF), Byte(&a)).Decode([]byte{0x00, 0xFF, 0x34}))
+	require.Equal(t, byte(0x34), a)
+	require.NoError(t, New(CheckedPadding(2, 0xFF), Byte(&a)).Decode([]byte{0xFF, 0xFF, 0x56}))
+	require.Equal(t, byte(0x56), a)
+	err := New(CheckedPadding(2, 0xFF), Byte(&a)).Decode([]byte{0xFF, 0xFE, 0x56})
+	require.ErrorIs(t, err, ErrInvalidPadding)
+}
+
+func TestAlign(t *testing.T) {
+	s := "abc"
+	a := uint32(0x01020304)
+	enc := New(LengthDelimString(&s), Align(4), FixedUint32(&a), Align(8))
+	require.Equal(
+		t,
+		[]byte{0x03, 'a', 'b', 'c', 0x01, 0x02, 0x03, 0x04},
+		enc.Encode(),
+	)
+
+	s = "abcde"
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{0x05, 'a', 'b', 'c', 'd', 'e', 0x00, 0x00, 0x01, 0x02, 0x03, 0x04, 0, 0, 0, 0},
+		buf,
+	)
+
+	var s2 string
+	var a2 uint32
+	require.NoError(t, New(LengthDelimString(&s2), Align(4), FixedUint32(&a2), Align(8)).Decode(buf))
+	require.Equal(t, s, s2)
+	require.Equal(t, a, a2)
+
+	// Alignment is from the start of the encoding, even inside other items.
+	b := byte(0x01)
+	cond := true
+	version := uint64(1)
+	a = 5
+	enc = New(
+		Byte(&b),
+		If(&cond, Align(4)),
+		Group(Byte(&b), Align(4)),
+		Versioned(&version, map[uint64]Item{1: Group(Align(4), FixedUint32(&a))}),
+	)
+	buf = enc.Encode()
+	require.Equal(
+		t,
+		[]byte{0x01, 0, 0, 0, 0x01, 0, 0, 0, 0x01, 0, 0, 0, 0x00, 0x00, 0x00, 0x05},
+		buf,
+	)
+	a, version = 0, 0
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint32(5), a)
+	require.NoError(t, enc.DecodeLenient(buf))
+	require.Equal(t, buf, bytes.Join(enc.EncodeBuffers(), nil))
+	require.False(t, strings.Contains(enc.Dump(buf), "trailing"))
+}
+
+func TestUvarint16(t *testing.T) {
+	check := func(x uint16, expected []byte) {
+		require.Equal(t, expected, New(Uvarint16(&x)).Encode())
+		var x2 uint16
+		require.NoError(t, New(Uvarint16(&x2)).Decode(expected))
+		require.Equal(t, x, x2)
+	}
+	check(0, []byte{0x00})
+	check(127, []byte{0x7f})
+	check(128, []byte{0x80, 0x01})
+	check(16384, []byte{0x80, 0x80, 0x01})
+	check(65535, []byte{0xff, 0xff, 0x03})
+
+	var x uint16
+	require.ErrorIs(t, New(Uvarint16(&x)).Decode([]byte{0x80, 0x80, 0x04}), ErrOverflowVarint)
+	require.ErrorIs(t, New(Uvarint16(&x)).Decode([]byte{0x80, 0x80}), io.ErrUnexpectedEOF)
+}
+
+func TestOrdVarint32(t *testing.T) {
+	for _, x := range []uint32{0, 1, 127, 128, 1 << 14, 1 << 21, 1 << 28, math.MaxUint32} {
+		x64 := uint64(x)
+		b := New(OrdUvarint32(&x)).Encode()
+		require.Equal(t, New(OrdUvarint64(&x64)).Encode(), b)
+		var x2 uint32
+		require.NoError(t, New(OrdUvarint32(&x2)).Decode(b))
+		require.Equal(t, x, x2)
+	}
+	for _, x := range []int32{math.MinInt32, -65, -64, -1, 0, 1, 64, math.MaxInt32} {
+		x64 := int64(x)
+		b := New(OrdVarint32(&x)).Encode()
+		require.Equal(t, New(OrdVarint64(&x64)).Encode(), b)
+		var x2 int32
+		require.NoError(t, New(OrdVarint32(&x2)).Decode(b))
+		require.Equal(t, x, x2)
+	}
+
+	big := uint64(math.MaxUint32 + 1)
+	var x uint32
+	err := New(OrdUvarint32(&x)).Decode(New(OrdUvarint64(&big)).Encode())
+	require.ErrorIs(t, err, ErrOverflowVarint)
+	for _, big := range []int64{math.MinInt32 - 1, math.MaxInt32 + 1} {
+		var x int32
+		err := New(OrdVarint32(&x)).Decode(New(OrdVarint64(&big)).Encode())
+		require.ErrorIs(t, err, ErrOverflowVarint)
+	}
+
+	// Every boundary between encoded sizes decodes the same as the 64-bit items.
+	for shift := 0; shift < 40; shift++ {
+		for _, x64 := range []uint64{1<<shift - 1, 1 << shift} {
+			var x uint32
+			err := New(OrdUvarint32(&x)).Decode(New(OrdUvarint64(&x64)).Encode())
+			if x64 > math.MaxUint32 {
+				require.ErrorIs(t, err, ErrOverflowVarint)
+				continue
+			}
+			require.NoError(t, err)
+			require.Equal(t, uint32(x64), x)
+		}
+		for _, x64 := range []int64{1<<shift - 1, 1 << shift, -1 << shift, -1<<shift - 1} {
+			var x int32
+			err := New(OrdVarint32(&x)).Decode(New(OrdVarint64(&x64)).Encode())
+			if x64 < math.MinInt32 || x64 > math.MaxInt32 {
+				require.ErrorIs(t, err, ErrOverflowVarint)
+				continue
+			}
+			require.NoError(t, err)
+			require.Equal(t, int32(x64), x)
+		}
+	}
+
+	var y int32
+	require.ErrorIs(t, New(OrdUvarint32(&x)).Decode([]byte{0xF0, 0x00}), ErrShortBuffer)
+	require.ErrorIs(t, New(OrdVarint32(&y)).Decode([]byte{0x08, 0x00}), ErrShortBuffer)
+	require.ErrorIs(t, New(OrdUvarint32(&x)).Decode([]byte{0xFF}), ErrOverflowVarint)
+	require.ErrorIs(t, New(OrdVarint32(&y)).Decode([]byte{0x00}), ErrOverflowVarint)
+}
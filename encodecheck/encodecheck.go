@@ -0,0 +1,208 @@
+// Package encodecheck provides an analyzer that catches common mistakes in declaring encodings with
+// github.com/bradenaw/encode.
+//
+// It reports:
+//
+//   - The same pointer bound to two items in one encoding, so that one field silently overwrites
+//     the other on decode.
+//   - Items bound to fields of a method's value receiver, as in
+//
+//       func (e foo) encoding() encode.Encoding {
+//       	return encode.New(encode.Bool(&e.b))
+//       }
+//
+//     e is a copy, so anything decoded into it is lost when the method returns. Use a pointer
+//     receiver instead.
+//   - Two encodings of the same type that bind the same fields in a different order, which usually
+//     means that separately-written encode and decode paths have drifted apart.
+package encodecheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const encodePkgPath = "github.com/bradenaw/encode"
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "encodecheck",
+	Doc:      "check for unbound, duplicated, and misordered pointers in encode.Encodings",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// The ordered list of receiver fields bound by one encoding, for comparing against other encodings
+// of the same type.
+type fieldOrder struct {
+	pos    token.Pos
+	fields []string
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	orders := make(map[types.Type][]fieldOrder)
+
+	inspect.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		decl := n.(*ast.FuncDecl)
+		if decl.Body == nil {
+			return
+		}
+		recv, recvType, isValueRecv := receiver(pass, decl)
+
+		ast.Inspect(decl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !isEncodingConstructor(pass, call) {
+				return true
+			}
+
+			var fields []string
+			seen := make(map[string]token.Pos)
+			for _, addr := range boundPointers(pass, call) {
+				key := types.ExprString(addr.X)
+				if prev, ok := seen[key]; ok {
+					pass.Reportf(addr.Pos(), "&%s is already bound to an item at %s; it will be "+
+						"overwritten on decode", key, pass.Fset.Position(prev))
+				} else {
+					seen[key] = addr.Pos()
+				}
+
+				if recv == nil {
+					continue
+				}
+				sel, ok := addr.X.(*ast.SelectorExpr)
+				if !ok {
+					continue
+				}
+				ident, ok := sel.X.(*ast.Ident)
+				if !ok || pass.TypesInfo.Uses[ident] != recv {
+					continue
+				}
+				fields = append(fields, sel.Sel.Name)
+				if isValueRecv {
+					pass.Reportf(addr.Pos(), "&%s points into a copy of value receiver %s; "+
+						"decoded values will be lost, use a pointer receiver", key, ident.Name)
+				}
+			}
+			if recvType != nil && len(fields) > 0 {
+				orders[recvType] = append(orders[recvType], fieldOrder{call.Pos(), fields})
+			}
+			// Nested constructors were already covered by boundPointers.
+			return false
+		})
+	})
+
+	for _, typeOrders := range orders {
+		for i := 1; i < len(typeOrders); i++ {
+			for j := 0; j < i; j++ {
+				a, b := typeOrders[j], typeOrders[i]
+				if sameFieldSet(a.fields, b.fields) && !sameOrder(a.fields, b.fields) {
+					pass.Reportf(b.pos, "encoding binds fields in order (%s), but the encoding at "+
+						"%s uses (%s)", strings.Join(b.fields, ", "), pass.Fset.Position(a.pos),
+						strings.Join(a.fields, ", "))
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Returns the receiver of decl if it's a method, its named type, and whether it's a value (rather
+// than pointer) receiver.
+func receiver(pass *analysis.Pass, decl *ast.FuncDecl) (types.Object, types.Type, bool) {
+	if decl.Recv == nil || len(decl.Recv.List) != 1 || len(decl.Recv.List[0].Names) != 1 {
+		return nil, nil, false
+	}
+	obj := pass.TypesInfo.Defs[decl.Recv.List[0].Names[0]]
+	if obj == nil {
+		return nil, nil, false
+	}
+	t := obj.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		return obj, ptr.Elem(), false
+	}
+	return obj, t, true
+}
+
+// Whether call is to one of the encode package's functions that collects items together.
+func isEncodingConstructor(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != encodePkgPath {
+		return false
+	}
+	switch fn.Name() {
+	case "New", "NewTuple", "Group", "Bitpacked":
+		return true
+	}
+	return false
+}
+
+// The arguments of encode functions that point at a value for the item to read, rather than one it
+// decodes into, by function name and argument index. The value is bound to another item, so these
+// don't bind it a second time.
+var referenceArgs = map[string]int{
+	"If":        0,
+	"Switch":    0,
+	"Versioned": 0,
+}
+
+// Returns every &x expression passed, directly or through nested calls into the encode package, to
+// call, other than those in referenceArgs.
+func boundPointers(pass *analysis.Pass, call *ast.CallExpr) []*ast.UnaryExpr {
+	var result []*ast.UnaryExpr
+	var visit func(call *ast.CallExpr, name string)
+	visit = func(call *ast.CallExpr, name string) {
+		refArg, hasRefArg := referenceArgs[name]
+		for i, arg := range call.Args {
+			if hasRefArg && i == refArg {
+				continue
+			}
+			switch arg := ast.Unparen(arg).(type) {
+			case *ast.UnaryExpr:
+				if arg.Op == token.AND {
+					result = append(result, arg)
+				}
+			case *ast.CallExpr:
+				fn, ok := typeutil.Callee(pass.TypesInfo, arg).(*types.Func)
+				if ok && fn.Pkg() != nil && fn.Pkg().Path() == encodePkgPath {
+					visit(arg, fn.Name())
+				}
+			}
+		}
+	}
+	visit(call, "")
+	return result
+}
+
+func sameFieldSet(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := make(map[string]int)
+	for _, f := range a {
+		counts[f]++
+	}
+	for _, f := range b {
+		counts[f]--
+		if counts[f] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func sameOrder(a []string, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package a
+
+import "github.com/bradenaw/encode"
+
+type good struct {
+	a uint16
+	b string
+	c bool
+}
+
+func (e *good) encoding() encode.Encoding {
+	return encode.New(
+		encode.FixedUint16(&e.a),
+		encode.LengthDelimString(&e.b),
+		encode.Bool(&e.c),
+	)
+}
+
+type valueReceiver struct {
+	c bool
+}
+
+func (e valueReceiver) encoding() encode.Encoding {
+	return encode.New(
+		encode.Bool(&e.c), // want `&e.c points into a copy of value receiver e`
+	)
+}
+
+type duplicate struct {
+	a bool
+	b bool
+}
+
+func (e *duplicate) encoding() encode.Encoding {
+	return encode.New(
+		encode.Bool(&e.a),
+		encode.Group(
+			encode.Bool(&e.b),
+			encode.Bool(&e.a), // want `&e.a is already bound to an item`
+		),
+	)
+}
+
+type reordered struct {
+	a uint16
+	c bool
+}
+
+func (e *reordered) encoder() encode.Encoding {
+	return encode.New(
+		encode.FixedUint16(&e.a),
+		encode.Bool(&e.c),
+	)
+}
+
+func (e *reordered) decoder() encode.Encoding {
+	return encode.New( // want `encoding binds fields in order \(c, a\)`
+		encode.Bool(&e.c),
+		encode.FixedUint16(&e.a),
+	)
+}
+
+type conditional struct {
+	has     bool
+	v       uint64
+	op      byte
+	version uint64
+}
+
+func (e *conditional) encoding() encode.Encoding {
+	return encode.New(
+		encode.Bool(&e.has),
+		encode.If(&e.has, encode.Uvarint64(&e.v)),
+		encode.Byte(&e.op),
+		encode.Switch(&e.op, map[uint8]encode.Item{1: encode.Bool(&e.has)}),
+		encode.Uvarint64(&e.version),
+		encode.Versioned(&e.version, map[uint64]encode.Item{1: encode.Bool(&e.has)}),
+	)
+}
+
+func (e *conditional) misbound() encode.Encoding {
+	return encode.New(
+		encode.Bool(&e.has),
+		encode.If(&e.has, encode.Bool(&e.has)), // want `&e.has is already bound to an item`
+	)
+}
@@ -0,0 +1,16 @@
+package encode
+
+type Item interface{}
+
+type Encoding struct{}
+
+func New(items ...Item) Encoding                               { return Encoding{} }
+func Group(items ...Item) Item                                 { return nil }
+func Bool(v *bool) Item                                        { return nil }
+func Byte(v *byte) Item                                        { return nil }
+func Uvarint64(v *uint64) Item                                 { return nil }
+func If(cond *bool, item Item) Item                            { return nil }
+func Switch(tag *uint8, cases map[uint8]Item) Item             { return nil }
+func Versioned(version *uint64, versions map[uint64]Item) Item { return nil }
+func FixedUint16(v *uint16) Item                               { return nil }
+func LengthDelimString(v *string) Item                         { return nil }
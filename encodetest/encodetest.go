@@ -0,0 +1,152 @@
+// Package encodetest provides helpers for testing Items, so that custom Items can be checked the
+// same way the ones in github.com/bradenaw/encode are.
+package encodetest
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bradenaw/encode"
+)
+
+// The number of values generated by each check.
+const iterations = 1000
+
+// RoundTrip checks that values generated by gen survive being encoded by the Item that item
+// returns for them and then decoded by the Item that item returns for a fresh value, as compared
+// by reflect.DeepEqual. It also checks that Size matches the length of the encoding, that
+// re-encoding the decoded value produces the same bytes, and that decoding any truncation of the
+// encoding doesn't panic.
+//
+// gen is given a seeded *rand.Rand, and the seed is included in failure messages.
+func RoundTrip[T any](t testing.TB, item func(v *T) encode.Item, gen func(r *rand.Rand) T) {
+	t.Helper()
+	seed := time.Now().UnixNano()
+	r := rand.New(rand.NewSource(seed))
+	for i := 0; i < iterations; i++ {
+		x := gen(r)
+		size := item(&x).Size()
+		b := encode.New(item(&x)).Encode()
+		if len(b) != size {
+			t.Fatalf("seed %d: %#v: Size() == %d, but encoded to %d bytes", seed, x, size, len(b))
+		}
+
+		var y T
+		err := encode.New(item(&y)).Decode(b)
+		if err != nil {
+			t.Fatalf("seed %d: %#v: decoding %x: %s", seed, x, b, err)
+		}
+		if !reflect.DeepEqual(x, y) {
+			t.Fatalf("seed %d: %#v encoded to %x, which decoded to %#v", seed, x, b, y)
+		}
+		b2 := encode.New(item(&y)).Encode()
+		if !bytes.Equal(b, b2) {
+			t.Fatalf("seed %d: %#v encoded to %x, but re-encoded to %x", seed, x, b, b2)
+		}
+
+		for j := 0; j < len(b); j++ {
+			var z T
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				_ = encode.New(item(&z)).Decode(b[:j])
+				return false
+			}()
+			if panicked {
+				t.Fatalf("seed %d: %#v: decoding truncated %x panicked", seed, x, b[:j])
+			}
+		}
+	}
+}
+
+// Ordered checks that the Item that item returns preserves ordering, that is that pairs of values
+// generated by gen encode to bytes that compare the same way that compare says the values do.
+// compare returns a negative number, zero, or a positive number if a is less than, equal to, or
+// greater than b respectively. This is checked both when the item is the last in a Tuple and when
+// it isn't.
+//
+// gen is given a seeded *rand.Rand, and the seed is included in failure messages.
+func Ordered[T any](
+	t testing.TB,
+	item func(v *T) encode.TupleItem,
+	gen func(r *rand.Rand) T,
+	compare func(a, b T) int,
+) {
+	t.Helper()
+	seed := time.Now().UnixNano()
+	r := rand.New(rand.NewSource(seed))
+	for i := 0; i < iterations; i++ {
+		a := gen(r)
+		b := gen(r)
+		expected := sign(compare(a, b))
+
+		ea := encode.NewTuple(item(&a)).Encode()
+		eb := encode.NewTuple(item(&b)).Encode()
+		if actual := bytes.Compare(ea, eb); actual != expected {
+			t.Fatalf(
+				"seed %d: compare(%#v, %#v) == %d, but they encoded to %x and %x",
+				seed, a, b, expected, ea, eb,
+			)
+		}
+
+		ea = encode.NewTuple(item(&a), item(&a)).Encode()
+		eb = encode.NewTuple(item(&b), item(&b)).Encode()
+		if actual := bytes.Compare(ea, eb); actual != expected {
+			t.Fatalf(
+				"seed %d: compare(%#v, %#v) == %d, but followed by themselves they encoded to %x "+
+					"and %x",
+				seed, a, b, expected, ea, eb,
+			)
+		}
+	}
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Fuzz wires the Encodings returned by newEncoding into Go's native fuzzing, for example
+//
+//   func FuzzRecord(f *testing.F) {
+//       encodetest.Fuzz(f, func() encode.Encoding {
+//           var r Record
+//           return r.Encoding()
+//       })
+//   }
+//
+// newEncoding must return an Encoding bound to fresh values each time it's called. The corpus is
+// seeded with encode.SeedCorpus of the first Encoding returned. For each input, Fuzz checks that
+// decoding doesn't panic, and if the input decodes successfully, that re-encoding the decoded
+// values produces bytes that decode and re-encode to exactly the same bytes again.
+func Fuzz(f *testing.F, newEncoding func() encode.Encoding) {
+	for _, seed := range encode.SeedCorpus(newEncoding()) {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		enc := newEncoding()
+		err := enc.Decode(b)
+		if err != nil {
+			return
+		}
+		b2 := enc.Encode()
+
+		enc2 := newEncoding()
+		err = enc2.Decode(b2)
+		if err != nil {
+			t.Fatalf("%x decoded, but re-encoded to %x which fails to decode: %s", b, b2, err)
+		}
+		b3 := enc2.Encode()
+		if !bytes.Equal(b2, b3) {
+			t.Fatalf("%x decoded and re-encoded to %x, but then re-encoded to %x", b, b2, b3)
+		}
+	})
+}
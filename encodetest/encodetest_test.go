@@ -0,0 +1,73 @@
+package encodetest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/bradenaw/encode"
+)
+
+func TestRoundTrip(t *testing.T) {
+	RoundTrip(
+		t,
+		func(v *uint64) encode.Item { return encode.Uvarint64(v) },
+		func(r *rand.Rand) uint64 { return r.Uint64() >> uint(r.Intn(64)) },
+	)
+	RoundTrip(
+		t,
+		func(v *[]byte) encode.Item { return encode.LengthDelimBytes(v) },
+		func(r *rand.Rand) []byte {
+			b := make([]byte, r.Intn(300))
+			r.Read(b)
+			return b
+		},
+	)
+}
+
+func TestOrdered(t *testing.T) {
+	Ordered(
+		t,
+		encode.OrdVarint64,
+		func(r *rand.Rand) int64 { return int64(r.Uint64()) >> uint(r.Intn(64)) },
+		func(a, b int64) int {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			default:
+				return 0
+			}
+		},
+	)
+	Ordered(
+		t,
+		encode.OrdUvarint64,
+		func(r *rand.Rand) uint64 { return r.Uint64() >> uint(r.Intn(64)) },
+		func(a, b uint64) int {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			default:
+				return 0
+			}
+		},
+	)
+}
+
+func FuzzExample(f *testing.F) {
+	Fuzz(f, func() encode.Encoding {
+		var a uint64
+		var b []byte
+		var c bool
+		var d []uint64
+		return encode.New(
+			encode.Uvarint64(&a),
+			encode.LengthDelimBytes(&b),
+			encode.Bool(&c),
+			encode.DeltaUvarints(&d),
+		)
+	})
+}
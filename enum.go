@@ -0,0 +1,131 @@
+package encode
+
+import "encoding/binary"
+
+var ErrInvalidEnum = NewInvalidValueError("encode: invalid enum value")
+
+// Encode v as a single byte, and on decode return ErrInvalidEnum if the value isn't one of valid.
+// *v is left untouched if the value is invalid.
+func Enum(v *uint8, valid ...uint8) Item {
+	return enum8{v, valid}
+}
+
+type enum8 struct {
+	v     *uint8
+	valid []uint8
+}
+
+func (e enum8) Encode(buf []byte) {
+	buf[0] = *e.v
+}
+func (e enum8) Size() int {
+	return 1
+}
+func (e enum8) kind() string {
+	return "Enum"
+}
+func (e enum8) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e enum8) fixedSize() int {
+	return e.Size()
+}
+func (e enum8) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return ErrShortBuffer
+	}
+	for _, valid := range e.valid {
+		if buf[0] == valid {
+			*e.v = buf[0]
+			return nil
+		}
+	}
+	return ErrInvalidEnum
+}
+
+// Encode v in big endian order, taking 2 bytes, and on decode return ErrInvalidEnum if the value
+// isn't one of valid.
+func Enum16(v *uint16, valid ...uint16) Item {
+	return enum16{v: v, valid: valid, order: binary.BigEndian}
+}
+
+type enum16 struct {
+	v     *uint16
+	valid []uint16
+	order binary.ByteOrder
+}
+
+func (e enum16) Encode(buf []byte) {
+	e.order.PutUint16(buf, *e.v)
+}
+func (e enum16) Size() int {
+	return 2
+}
+func (e enum16) kind() string {
+	return "Enum16"
+}
+func (e enum16) withByteOrder(order binary.ByteOrder) Item {
+	return enum16{v: e.v, valid: e.valid, order: order}
+}
+func (e enum16) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e enum16) fixedSize() int {
+	return e.Size()
+}
+func (e enum16) Decode(buf []byte) error {
+	if len(buf) < 2 {
+		return ErrShortBuffer
+	}
+	x := e.order.Uint16(buf)
+	for _, valid := range e.valid {
+		if x == valid {
+			*e.v = x
+			return nil
+		}
+	}
+	return ErrInvalidEnum
+}
+
+// Encode v as a uvarint, like Uvarint64, and on decode return ErrInvalidEnum if the value isn't one
+// of valid.
+func UvarintEnum(v *uint64, valid ...uint64) Item {
+	return uvarintEnum{v, valid}
+}
+
+type uvarintEnum struct {
+	v     *uint64
+	valid []uint64
+}
+
+func (e uvarintEnum) Encode(buf []byte) {
+	binary.PutUvarint(buf, *e.v)
+}
+func (e uvarintEnum) Size() int {
+	return uvarintSize(*e.v)
+}
+func (e uvarintEnum) kind() string {
+	return "UvarintEnum"
+}
+func (e uvarintEnum) sizeBounds() (min, max int) {
+	return 1, binary.MaxVarintLen64
+}
+func (e uvarintEnum) skip(buf []byte) (int, error) {
+	return skipUvarint(buf)
+}
+func (e uvarintEnum) Decode(buf []byte) error {
+	x, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	for _, valid := range e.valid {
+		if x == valid {
+			*e.v = x
+			return nil
+		}
+	}
+	return ErrInvalidEnum
+}
@@ -0,0 +1,24 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnum(t *testing.T) {
+	var a uint8
+	var b uint16
+	var c uint64
+	enc := New(Enum(&a, 1, 2, 3), Enum16(&b, 0x100, 0x200), UvarintEnum(&c, 0, 300))
+
+	require.NoError(t, enc.Decode([]byte{0x02, 0x02, 0x00, 0xAC, 0x02}))
+	require.Equal(t, uint8(2), a)
+	require.Equal(t, uint16(0x200), b)
+	require.Equal(t, uint64(300), c)
+
+	require.ErrorIs(t, enc.Decode([]byte{0x04, 0x02, 0x00, 0x00}), ErrInvalidEnum)
+	require.Equal(t, uint8(2), a)
+	require.ErrorIs(t, enc.Decode([]byte{0x01, 0x03, 0x00, 0x00}), ErrInvalidEnum)
+	require.ErrorIs(t, enc.Decode([]byte{0x01, 0x01, 0x00, 0x01}), ErrInvalidEnum)
+}
@@ -0,0 +1,52 @@
+package encode
+
+import (
+	"errors"
+	"io"
+)
+
+// Every error from decoding a buffer falls into one of a few categories, so that callers can tell
+// truncated input from corrupt input without knowing every item's errors. Each category has a
+// sentinel that errors.Is matches against all of the errors in it:
+//
+//   ErrShortBuffer     the buffer ended partway through an item, so it may have been truncated
+//   ErrInvalidValue    the buffer holds bytes that the item never encodes, so it's corrupt
+//   ErrVarintOverflow  a varint encodes a value too large for its item, also an ErrInvalidValue
+//   ErrTrailingBytes   the buffer holds bytes past the end of what was decoded
+//
+// More specific errors, like ErrInvalidBool, belong to a category, so errors.Is matches them both
+// against themselves and against their category's sentinel. Errors from I/O, like those from
+// ReadMessage, are returned as they are.
+
+// Returned when the buffer ends before the encoding does. It also matches io.ErrUnexpectedEOF, which
+// decoding returned for this before ErrShortBuffer existed.
+var ErrShortBuffer error = &categoryError{
+	msg:      "encode: buffer too short",
+	category: io.ErrUnexpectedEOF,
+}
+
+var ErrInvalidValue = errors.New("encode: invalid value")
+var ErrVarintOverflow = NewInvalidValueError("encode: overflowed varint")
+var ErrTrailingBytes = errors.New("encode: unconsumed trailing bytes")
+
+// Deprecated: Use ErrVarintOverflow, which this is equal to.
+var ErrOverflowVarint = ErrVarintOverflow
+
+// Returns an error with message msg that errors.Is matches against ErrInvalidValue, so that Items
+// outside of this package can report corrupt input in the same way as the ones in it.
+func NewInvalidValueError(msg string) error {
+	return &categoryError{msg: msg, category: ErrInvalidValue}
+}
+
+type categoryError struct {
+	msg      string
+	category error
+}
+
+func (err *categoryError) Error() string {
+	return err.msg
+}
+
+func (err *categoryError) Unwrap() error {
+	return err.category
+}
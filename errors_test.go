@@ -0,0 +1,36 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCategories(t *testing.T) {
+	var b bool
+	var x uint16
+	var s string
+
+	err := New(FixedUint16(&x)).Decode([]byte{0x01})
+	require.ErrorIs(t, err, ErrShortBuffer)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.ErrorIs(t, New(LengthDelimString(&s)).Decode([]byte{0x05, 'a'}), ErrShortBuffer)
+
+	err = New(Bool(&b)).Decode([]byte{0x02})
+	require.ErrorIs(t, err, ErrInvalidBool)
+	require.ErrorIs(t, err, ErrInvalidValue)
+	require.False(t, errors.Is(err, ErrShortBuffer))
+
+	err = New(Uvarint16(&x)).Decode([]byte{0xFF, 0xFF, 0x7F})
+	require.ErrorIs(t, err, ErrVarintOverflow)
+	require.ErrorIs(t, err, ErrOverflowVarint)
+	require.ErrorIs(t, err, ErrInvalidValue)
+
+	err = New(Const([]byte("RIFF"))).Decode([]byte("RIFX"))
+	require.ErrorIs(t, err, ErrInvalidValue)
+
+	require.ErrorIs(t, NewInvalidValueError("custom"), ErrInvalidValue)
+	require.Equal(t, "custom", NewInvalidValueError("custom").Error())
+}
@@ -0,0 +1,141 @@
+package encode
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Encode v as an IEEE 754 half-precision float in big endian order, taking 2 bytes. v is rounded
+// to the nearest representable value, ties to even, so values too large for half precision become
+// infinities and values too small become zeroes. NaNs stay NaNs. Decoding is exact.
+func Float16(v *float32) Item {
+	return float16{v}
+}
+
+type float16 struct{ v *float32 }
+
+func (e float16) Encode(buf []byte) {
+	binary.BigEndian.PutUint16(buf, float32ToFloat16(*e.v))
+}
+func (e float16) Size() int {
+	return 2
+}
+func (e float16) kind() string {
+	return "Float16"
+}
+func (e float16) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e float16) fixedSize() int {
+	return e.Size()
+}
+func (e float16) Decode(buf []byte) error {
+	if len(buf) < 2 {
+		return ErrShortBuffer
+	}
+	*e.v = float16ToFloat32(binary.BigEndian.Uint16(buf))
+	return nil
+}
+
+func float32ToFloat16(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23) & 0xff
+	mant := b & 0x7fffff
+
+	if exp == 0xff {
+		if mant != 0 {
+			// Keep the top of the payload, and make sure it stays a quiet NaN.
+			return sign | 0x7e00 | uint16(mant>>13)
+		}
+		return sign | 0x7c00
+	}
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return sign | 0x7c00
+	}
+	if e <= 0 {
+		// Subnormal in half precision, or too small even for that.
+		if e < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - e)
+		return sign | roundShift(mant, shift)
+	}
+	// Rounding up may carry into the exponent, which is exactly right, including overflowing to
+	// infinity.
+	return sign | (uint16(e)<<10 + roundShift(mant, 13))
+}
+
+// Returns x >> shift, rounded to nearest with ties to even.
+func roundShift(x uint32, shift uint) uint16 {
+	result := x >> shift
+	rem := x & (1<<shift - 1)
+	half := uint32(1) << (shift - 1)
+	if rem > half || (rem == half && result&1 == 1) {
+		result++
+	}
+	return uint16(result)
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := int(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		e := -14
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		mant &= 0x3ff
+		return math.Float32frombits(sign | uint32(e+127)<<23 | mant<<13)
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	default:
+		return math.Float32frombits(sign | uint32(exp-15+127)<<23 | mant<<13)
+	}
+}
+
+// Encode v as a bfloat16 in big endian order, taking 2 bytes. bfloat16 is the top half of a
+// float32, so it has the same range but only 8 bits of precision. v's low-order 16 bits are
+// truncated, except that NaNs stay NaNs. Decoding is exact.
+func BFloat16(v *float32) Item {
+	return bfloat16{v}
+}
+
+type bfloat16 struct{ v *float32 }
+
+func (e bfloat16) Encode(buf []byte) {
+	b := math.Float32bits(*e.v)
+	h := uint16(b >> 16)
+	if b&0x7fffffff > 0x7f800000 {
+		// NaN, which might only have had payload bits in the truncated half.
+		h |= 0x0040
+	}
+	binary.BigEndian.PutUint16(buf, h)
+}
+func (e bfloat16) Size() int {
+	return 2
+}
+func (e bfloat16) kind() string {
+	return "BFloat16"
+}
+func (e bfloat16) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e bfloat16) fixedSize() int {
+	return e.Size()
+}
+func (e bfloat16) Decode(buf []byte) error {
+	if len(buf) < 2 {
+		return ErrShortBuffer
+	}
+	*e.v = math.Float32frombits(uint32(binary.BigEndian.Uint16(buf)) << 16)
+	return nil
+}
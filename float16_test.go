@@ -0,0 +1,75 @@
+package encode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloat16(t *testing.T) {
+	check := func(f float32, expected uint16, decoded float32) {
+		b := New(Float16(&f)).Encode()
+		require.Equal(t, []byte{byte(expected >> 8), byte(expected)}, b, "%v", f)
+		var f2 float32
+		require.NoError(t, New(Float16(&f2)).Decode(b))
+		require.Equal(t, math.Float32bits(decoded), math.Float32bits(f2), "%v", f)
+	}
+	exact := func(f float32, expected uint16) { check(f, expected, f) }
+
+	exact(0, 0x0000)
+	exact(float32(math.Copysign(0, -1)), 0x8000)
+	exact(1, 0x3c00)
+	exact(-2, 0xc000)
+	exact(0.5, 0x3800)
+	exact(65504, 0x7bff)
+	exact(float32(math.Inf(1)), 0x7c00)
+	exact(float32(math.Inf(-1)), 0xfc00)
+	// Smallest positive normal and subnormal.
+	exact(float32(math.Ldexp(1, -14)), 0x0400)
+	exact(float32(math.Ldexp(1, -24)), 0x0001)
+	exact(float32(math.Ldexp(1023, -24)), 0x03ff)
+
+	check(65520, 0x7c00, float32(math.Inf(1)))
+	check(1e-10, 0x0000, 0)
+	check(0.1, 0x2e66, 0.0999755859375)
+	// Ties round to even.
+	check(1+float32(math.Ldexp(1, -11)), 0x3c00, 1)
+	check(1+float32(math.Ldexp(3, -11)), 0x3c02, 1+float32(math.Ldexp(1, -9)))
+	check(float32(math.Ldexp(1, -25)), 0x0000, 0)
+	check(float32(math.Ldexp(3, -25)), 0x0002, float32(math.Ldexp(1, -23)))
+
+	f := float32(math.NaN())
+	var f2 float32
+	require.NoError(t, New(Float16(&f2)).Decode(New(Float16(&f)).Encode()))
+	require.True(t, math.IsNaN(float64(f2)))
+
+	for h := 0; h <= math.MaxUint16; h++ {
+		f := float16ToFloat32(uint16(h))
+		if math.IsNaN(float64(f)) {
+			continue
+		}
+		require.Equal(t, uint16(h), float32ToFloat16(f))
+	}
+}
+
+func TestBFloat16(t *testing.T) {
+	check := func(f float32, expected []byte, decoded float32) {
+		b := New(BFloat16(&f)).Encode()
+		require.Equal(t, expected, b, "%v", f)
+		var f2 float32
+		require.NoError(t, New(BFloat16(&f2)).Decode(b))
+		require.Equal(t, math.Float32bits(decoded), math.Float32bits(f2), "%v", f)
+	}
+	check(0, []byte{0x00, 0x00}, 0)
+	check(1, []byte{0x3f, 0x80}, 1)
+	check(-2, []byte{0xc0, 0x00}, -2)
+	check(3.14159, []byte{0x40, 0x49}, 3.140625)
+	check(math.MaxFloat32, []byte{0x7f, 0x7f}, math.Float32frombits(0x7f7f0000))
+	check(float32(math.Inf(-1)), []byte{0xff, 0x80}, float32(math.Inf(-1)))
+
+	f := math.Float32frombits(0x7f800001)
+	var f2 float32
+	require.NoError(t, New(BFloat16(&f2)).Decode(New(BFloat16(&f)).Encode()))
+	require.True(t, math.IsNaN(float64(f2)))
+}
@@ -0,0 +1,148 @@
+package encode
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+var ErrInvalidFloatSeries = NewInvalidValueError("encode: invalid float series header")
+
+// Encode a series of float64 samples using the XOR scheme from Facebook's Gorilla time-series
+// database. This is a uvarint of v's length followed by a bitstream, padded with zeroes to the
+// nearest byte. The first value is written as its 64 bits, then each following value is XORed with
+// the one before it and written as:
+//
+//   0                                 if the XOR is zero, i.e. the value repeated
+//   10 <meaningful bits>              if the XOR's nonzero bits fit inside the window of the last
+//                                     value written with a header
+//   11 <5 bits of leading zeroes>     otherwise, with 64 meaningful bits written as 0
+//      <6 bits of meaningful length>
+//      <meaningful bits>
+//
+// Series of samples that change slowly or not at all, like most metrics, compress to a few bits per
+// sample. Decode returns ErrInvalidFloatSeries if a header describes more than 64 bits.
+func FloatSeries(v *[]float64) Item {
+	return floatSeries{v}
+}
+
+type floatSeries struct{ v *[]float64 }
+
+func (e floatSeries) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	b := bitBuffer{b: buf[n:]}
+	gorillaWalk(*e.v, b.writeBits)
+}
+func (e floatSeries) Size() int {
+	nBits := 0
+	gorillaWalk(*e.v, func(x uint64, n int) { nBits += n })
+	return uvarintSize(uint64(len(*e.v))) + (nBits+7)/8
+}
+func (e floatSeries) kind() string {
+	return "FloatSeries"
+}
+func (e floatSeries) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e floatSeries) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e floatSeries) decodeLimited(buf []byte, s *decodeState) error {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	err := s.checkElements(l)
+	if err != nil {
+		return err
+	}
+	b := bitBuffer{b: buf[n:]}
+	// Every value takes at least one bit, so don't trust a length that couldn't possibly fit.
+	if uint64(b.lenBits()) < l {
+		return ErrShortBuffer
+	}
+	out := make([]float64, l)
+	if l == 0 {
+		*e.v = out
+		return nil
+	}
+	prev, err := b.readBits(64)
+	if err != nil {
+		return err
+	}
+	out[0] = math.Float64frombits(prev)
+	leading, trailing := 0, 0
+	for i := 1; i < len(out); i++ {
+		control, err := b.readBits(1)
+		if err != nil {
+			return err
+		}
+		if control == 1 {
+			control, err = b.readBits(1)
+			if err != nil {
+				return err
+			}
+			if control == 1 {
+				header, err := b.readBits(11)
+				if err != nil {
+					return err
+				}
+				leading = int(header >> 6)
+				meaningful := int(header & 0x3f)
+				if meaningful == 0 {
+					meaningful = 64
+				}
+				if leading+meaningful > 64 {
+					return ErrInvalidFloatSeries
+				}
+				trailing = 64 - leading - meaningful
+			}
+			x, err := b.readBits(64 - leading - trailing)
+			if err != nil {
+				return err
+			}
+			prev ^= x << uint(trailing)
+		}
+		out[i] = math.Float64frombits(prev)
+	}
+	*e.v = out
+	return nil
+}
+
+// Calls emit with each field of the bitstream for v, the n low-order bits of x.
+func gorillaWalk(v []float64, emit func(x uint64, n int)) {
+	if len(v) == 0 {
+		return
+	}
+	prev := math.Float64bits(v[0])
+	emit(prev, 64)
+	// The window of meaningful bits from the last header, none yet.
+	leading, trailing := -1, -1
+	for _, f := range v[1:] {
+		curr := math.Float64bits(f)
+		x := curr ^ prev
+		prev = curr
+		if x == 0 {
+			emit(0, 1)
+			continue
+		}
+		l := bits.LeadingZeros64(x)
+		t := bits.TrailingZeros64(x)
+		if leading >= 0 && l >= leading && t >= trailing {
+			emit(0b10, 2)
+			emit(x>>uint(trailing), 64-leading-trailing)
+			continue
+		}
+		if l > 31 {
+			l = 31
+		}
+		leading, trailing = l, t
+		meaningful := 64 - l - t
+		emit(0b11, 2)
+		emit(uint64(l)<<6|uint64(meaningful&0x3f), 11)
+		emit(x>>uint(t), meaningful)
+	}
+}
@@ -0,0 +1,52 @@
+package encode
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloatSeries(t *testing.T) {
+	check := func(v []float64) []byte {
+		buf := New(FloatSeries(&v)).Encode()
+		var v2 []float64
+		require.NoError(t, New(FloatSeries(&v2)).Decode(buf))
+		require.Len(t, v2, len(v))
+		for i := range v {
+			require.Equal(t, math.Float64bits(v[i]), math.Float64bits(v2[i]))
+		}
+		for i := 0; i < len(buf); i++ {
+			require.ErrorIs(t, New(FloatSeries(&v2)).Decode(buf[:i]), io.ErrUnexpectedEOF)
+		}
+		return buf
+	}
+
+	require.Equal(t, []byte{0x00}, check(nil))
+	require.Equal(
+		t,
+		// 0x3ff0000000000000, then 0 and 0 for the repeats, padded.
+		[]byte{0x03, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0, 0x00},
+		check([]float64{1, 1, 1}),
+	)
+
+	v := make([]float64, 1000)
+	for i := range v {
+		v[i] = 20 + float64(i%10)*0.5
+	}
+	buf := check(v)
+	require.Less(t, len(buf), 8*len(v)/2)
+
+	check([]float64{
+		0, math.Inf(1), math.Inf(-1), math.NaN(), math.Copysign(0, -1),
+		math.MaxFloat64, math.SmallestNonzeroFloat64,
+		1, math.Float64frombits(0x8000000000000001), 3.14159,
+	})
+
+	require.ErrorIs(
+		t,
+		New(FloatSeries(&v)).DecodeLimited(New(FloatSeries(&v)).Encode(), Limits{MaxElements: 999}),
+		ErrLimitExceeded,
+	)
+}
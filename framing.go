@@ -0,0 +1,217 @@
+package encode
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+var ErrFrameTooLarge = errors.New("encode: frame exceeds maximum size")
+
+// Frames are written as a uvarint of the frame's length followed by the frame itself.
+
+// Append msg to dst as a frame, returning the extended buffer.
+func AppendFrame(dst []byte, msg []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(msg)))
+	return append(dst, msg...)
+}
+
+// FrameDecoder splits a byte stream into frames written by AppendFrame. It never performs I/O and
+// never blocks: the caller pushes bytes in with Feed as they arrive, and pulls complete frames out
+// with Drain. This makes it suitable for event-loop style servers that own their own sockets.
+type FrameDecoder struct {
+	maxFrameSize int
+	buf          []byte
+	frames       [][]byte
+	err          error
+}
+
+// Returns a FrameDecoder that fails with ErrFrameTooLarge for any frame longer than maxFrameSize
+// bytes. If maxFrameSize is 0, frames of any size are allowed.
+func NewFrameDecoder(maxFrameSize int) *FrameDecoder {
+	return &FrameDecoder{maxFrameSize: maxFrameSize}
+}
+
+// Feed appends in to the stream. It does not retain in. Any frames completed by in are available
+// from the next call to Drain.
+//
+// Once Feed returns an error the stream is corrupt, and all later calls return the same error.
+func (d *FrameDecoder) Feed(in []byte) error {
+	if d.err != nil {
+		return d.err
+	}
+	d.buf = append(d.buf, in...)
+	i := 0
+	for {
+		l, n := binary.Uvarint(d.buf[i:])
+		if n == 0 {
+			break
+		}
+		if n < 0 {
+			d.err = ErrVarintOverflow
+			break
+		}
+		if d.maxFrameSize > 0 && l > uint64(d.maxFrameSize) {
+			d.err = ErrFrameTooLarge
+			break
+		}
+		if uint64(len(d.buf[i+n:])) < l {
+			break
+		}
+		frame := make([]byte, l)
+		copy(frame, d.buf[i+n:])
+		d.frames = append(d.frames, frame)
+		i += n + int(l)
+	}
+	d.buf = d.buf[:copy(d.buf, d.buf[i:])]
+	return d.err
+}
+
+// Drain returns the frames completed since the last call to Drain, in the order they appeared in
+// the stream. The returned frames are owned by the caller.
+func (d *FrameDecoder) Drain() [][]byte {
+	frames := d.frames
+	d.frames = nil
+	return frames
+}
+
+// Buffered returns the number of bytes fed that are not yet part of a complete frame.
+func (d *FrameDecoder) Buffered() int {
+	return len(d.buf)
+}
+
+// WriteTo writes the encoding to w, implementing io.WriterTo. It's written as EncodeBuffers
+// segments, so large byte fields go to w without being copied into a contiguous message first. No
+// frame is written, so the reader needs some other way to know where the encoding ends; see
+// WriteMessage for one.
+func (enc Encoding) WriteTo(w io.Writer) (int64, error) {
+	bufs := enc.EncodeBuffers()
+	return bufs.WriteTo(w)
+}
+
+// ReadFrom reads r until io.EOF and decodes everything read into enc, implementing io.ReaderFrom.
+// Returns the number of bytes read, along with either the error from reading r or the error from
+// decoding. Since it consumes the whole of r, it's meant for sources that hold exactly one encoding,
+// like a file written by WriteTo; see ReadMessage for streams of several.
+func (enc Encoding) ReadFrom(r io.Reader) (int64, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(buf)), err
+	}
+	return int64(len(buf)), enc.Decode(buf)
+}
+
+// Write enc to w as a single frame, in the same format as AppendFrame.
+func WriteMessage(w io.Writer, enc Encoding) error {
+	size := sizeItems(enc.items, 0)
+	buf := make([]byte, 0, binary.MaxVarintLen64+size)
+	buf = binary.AppendUvarint(buf, uint64(size))
+	_, err := w.Write(enc.Append(buf))
+	return err
+}
+
+// Read exactly one frame written by WriteMessage from r and decode it into enc, waiting through
+// short reads as necessary. Nothing past the end of the frame is consumed from r, so r does not
+// need to be buffered for correctness, though it's much faster if it is.
+//
+// Returns ErrFrameTooLarge before reading the frame if it's longer than maxSize bytes. If maxSize
+// is 0, frames of any size are allowed. Returns io.EOF only if r ends cleanly before the frame.
+func ReadMessage(r io.Reader, enc Encoding, maxSize int) error {
+	frame, err := readFrame(r, maxSize)
+	if err != nil {
+		return err
+	}
+	return enc.Decode(frame)
+}
+
+// ReadMessageContext is ReadMessage, except that it returns ctx.Err() if ctx is done before the
+// frame has been read, rather than waiting on r indefinitely.
+//
+// If r has a SetReadDeadline method, like net.Conn, it's used to interrupt the read once ctx is
+// done, by setting a deadline in the past that's left in place. A deadline already set on r still
+// applies to the read, and isn't changed unless ctx is done first. Otherwise the read continues in
+// the background after ReadMessageContext returns, so r must not be read from again after a
+// cancellation. Either way, part of the frame may have been consumed, so the stream should be
+// considered broken.
+func ReadMessageContext(ctx context.Context, r io.Reader, enc Encoding, maxSize int) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.(readDeadliner); ok {
+		return readMessageDeadline(ctx, r, enc, maxSize)
+	}
+
+	type result struct {
+		frame []byte
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		frame, err := readFrame(r, maxSize)
+		done <- result{frame, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-done:
+		if res.err != nil {
+			return res.err
+		}
+		return enc.Decode(res.frame)
+	}
+}
+
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+func readMessageDeadline(ctx context.Context, r io.Reader, enc Encoding, maxSize int) error {
+	d := r.(readDeadliner)
+	// This also covers ctx's deadline, since ctx is done once it passes.
+	stop := context.AfterFunc(ctx, func() {
+		// A deadline in the past wakes up any blocked read.
+		d.SetReadDeadline(time.Unix(1, 0))
+	})
+
+	frame, err := readFrame(r, maxSize)
+	if !stop() {
+		// r's deadline has been replaced, whether or not the read finished first.
+		return ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	return enc.Decode(frame)
+}
+
+// Read exactly one frame from r. Bytes are read from r one at a time until the length is known, so
+// that nothing past the end of the frame is consumed. The length hasn't been checked against
+// anything but maxFrameSize, so the frame is grown as its bytes arrive rather than allocated up
+// front, and a hostile length fails with io.ErrUnexpectedEOF instead of exhausting memory.
+func readFrame(r io.Reader, maxFrameSize int) ([]byte, error) {
+	l, err := ReadUvarint(asByteReader(r))
+	if err != nil {
+		return nil, err
+	}
+	if l > math.MaxInt || (maxFrameSize > 0 && l > uint64(maxFrameSize)) {
+		return nil, ErrFrameTooLarge
+	}
+	var frame bytes.Buffer
+	frame.Grow(minInt(int(l), readFrameChunkSize))
+	n, err := io.CopyN(&frame, r, int64(l))
+	if n < int64(l) && (err == nil || err == io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	return frame.Bytes(), nil
+}
+
+// The most that readFrame allocates for a frame before any of it has been read.
+const readFrameChunkSize = 64 << 10
@@ -0,0 +1,161 @@
+package encode
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameDecoder(t *testing.T) {
+	msgs := [][]byte{
+		[]byte("hello"),
+		{},
+		bytes.Repeat([]byte{0xAB}, 300),
+		[]byte("world"),
+	}
+	var stream []byte
+	for _, msg := range msgs {
+		stream = AppendFrame(stream, msg)
+	}
+
+	check := func(chunkSize int) {
+		d := NewFrameDecoder(0)
+		var got [][]byte
+		for i := 0; i < len(stream); i += chunkSize {
+			end := minInt(i+chunkSize, len(stream))
+			require.NoError(t, d.Feed(stream[i:end]))
+			got = append(got, d.Drain()...)
+		}
+		require.Equal(t, msgs, got)
+		require.Equal(t, 0, d.Buffered())
+	}
+
+	check(1)
+	check(2)
+	check(7)
+	check(len(stream))
+}
+
+func TestFrameDecoderTooLarge(t *testing.T) {
+	d := NewFrameDecoder(4)
+	require.NoError(t, d.Feed(AppendFrame(nil, []byte("abcd"))))
+	require.ErrorIs(t, d.Feed(AppendFrame(nil, []byte("abcde"))), ErrFrameTooLarge)
+	require.ErrorIs(t, d.Feed([]byte{0x00}), ErrFrameTooLarge)
+	require.Equal(t, [][]byte{[]byte("abcd")}, d.Drain())
+}
+
+func TestReadWriteMessage(t *testing.T) {
+	var a uint64
+	var b string
+	enc := New(Uvarint64(&a), LengthDelimString(&b))
+
+	var stream bytes.Buffer
+	a, b = 1, "hello"
+	require.NoError(t, WriteMessage(&stream, enc))
+	a, b = 300, strings.Repeat("x", 200)
+	require.NoError(t, WriteMessage(&stream, enc))
+	full := append([]byte(nil), stream.Bytes()...)
+
+	r := iotest.OneByteReader(&stream)
+	require.NoError(t, ReadMessage(r, enc, 0))
+	require.Equal(t, uint64(1), a)
+	require.Equal(t, "hello", b)
+	require.NoError(t, ReadMessage(r, enc, 0))
+	require.Equal(t, uint64(300), a)
+	require.Equal(t, strings.Repeat("x", 200), b)
+	require.ErrorIs(t, ReadMessage(r, enc, 0), io.EOF)
+
+	require.ErrorIs(t, ReadMessage(bytes.NewReader(full), enc, 6), ErrFrameTooLarge)
+	require.ErrorIs(t, ReadMessage(bytes.NewReader(full[:4]), enc, 0), io.ErrUnexpectedEOF)
+}
+
+func TestReadMessageHostileLength(t *testing.T) {
+	var a uint64
+	enc := New(Uvarint64(&a))
+
+	// A length past math.MaxInt.
+	hostile := append(bytes.Repeat([]byte{0xFF}, 9), 0x01)
+	require.ErrorIs(t, ReadMessage(bytes.NewReader(hostile), enc, 0), ErrFrameTooLarge)
+
+	// A length of 1TiB, followed by only a few bytes.
+	hostile = append(binary.AppendUvarint(nil, 1<<40), 0x01, 0x02, 0x03)
+	require.ErrorIs(t, ReadMessage(bytes.NewReader(hostile), enc, 0), io.ErrUnexpectedEOF)
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	var a uint64
+	var b []byte
+	enc := New(Uvarint64(&a), LengthDelimBytes(&b))
+	a, b = 300, bytes.Repeat([]byte{0xCD}, 1000)
+
+	var stream bytes.Buffer
+	var _ io.WriterTo = enc
+	n, err := enc.WriteTo(&stream)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(enc.Encode())), n)
+	require.Equal(t, enc.Encode(), stream.Bytes())
+
+	var a2 uint64
+	var b2 []byte
+	enc2 := New(Uvarint64(&a2), LengthDelimBytes(&b2))
+	var _ io.ReaderFrom = enc2
+	n, err = enc2.ReadFrom(iotest.OneByteReader(&stream))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(enc.Encode())), n)
+	require.Equal(t, a, a2)
+	require.Equal(t, b, b2)
+
+	_, err = enc2.ReadFrom(bytes.NewReader(enc.Encode()[:10]))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestReadMessageContext(t *testing.T) {
+	var a uint64
+	enc := New(Uvarint64(&a))
+
+	a = 7
+	frame := AppendFrame(nil, enc.Encode())
+	check := func(r io.Reader, w io.Writer) {
+		go func() { _, _ = w.Write(frame) }()
+		a = 0
+		require.NoError(t, ReadMessageContext(context.Background(), r, enc, 0))
+		require.Equal(t, uint64(7), a)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, ReadMessageContext(ctx, r, enc, 0), context.DeadlineExceeded)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, ReadMessageContext(ctx, r, enc, 0), context.Canceled)
+	}
+
+	// net.Conn has read deadlines.
+	connA, connB := net.Pipe()
+	defer connA.Close()
+	defer connB.Close()
+	check(connA, connB)
+
+	// A deadline the caller set on the conn still applies, and is kept after a successful read.
+	go func() { _, _ = connB.Write(frame) }()
+	require.NoError(t, connA.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	require.NoError(t, ReadMessageContext(ctx, connA, enc, 0))
+	err := ReadMessageContext(ctx, connA, enc, 0)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	// io.Pipe doesn't.
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	check(pr, pw)
+}
@@ -0,0 +1,107 @@
+package encode
+
+import "encoding/binary"
+
+// Encode v as a uvarint of its length followed by groups of four values using group varint. Each
+// group starts with a control byte holding the byte length of each value minus one in two bits,
+// first value in the lowest bits, followed by each value in that many bytes in little endian:
+//
+//   []uint32{1, 256, 65536, 16777216, 7}
+//   05 e4 01 0001 000001 00000001 00 07
+//
+// The last group may have fewer than four values, in which case the unused bits of the control
+// byte are zero. This is usually slightly larger than encoding each value with Uvarint32, but
+// decode doesn't need to branch on every byte so it's considerably faster.
+func GroupVarintUint32s(v *[]uint32) Item {
+	return groupVarintUint32s{v}
+}
+
+type groupVarintUint32s struct{ v *[]uint32 }
+
+func (e groupVarintUint32s) Encode(buf []byte) {
+	v := *e.v
+	n := binary.PutUvarint(buf, uint64(len(v)))
+	for i := 0; i < len(v); i += 4 {
+		control := n
+		n++
+		for j := 0; j < 4 && i+j < len(v); j++ {
+			l := groupVarintLen(v[i+j])
+			buf[control] |= byte(l-1) << (2 * j)
+			x := v[i+j]
+			for k := 0; k < l; k++ {
+				buf[n+k] = byte(x)
+				x >>= 8
+			}
+			n += l
+		}
+	}
+}
+func (e groupVarintUint32s) Size() int {
+	v := *e.v
+	size := uvarintSize(uint64(len(v))) + (len(v)+3)/4
+	for _, x := range v {
+		size += groupVarintLen(x)
+	}
+	return size
+}
+func (e groupVarintUint32s) kind() string {
+	return "GroupVarintUint32s"
+}
+func (e groupVarintUint32s) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e groupVarintUint32s) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e groupVarintUint32s) decodeLimited(buf []byte, s *decodeState) error {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	err := s.checkElements(l)
+	if err != nil {
+		return err
+	}
+	// Every value takes at least one byte, so don't trust a length that couldn't possibly fit.
+	if uint64(len(buf[n:])) < l {
+		return ErrShortBuffer
+	}
+	out := make([]uint32, l)
+	for i := 0; i < len(out); i += 4 {
+		if n >= len(buf) {
+			return ErrShortBuffer
+		}
+		control := buf[n]
+		n++
+		for j := 0; j < 4 && i+j < len(out); j++ {
+			vl := int((control>>(2*j))&0b11) + 1
+			if len(buf)-n < vl {
+				return ErrShortBuffer
+			}
+			var x uint32
+			for k := vl - 1; k >= 0; k-- {
+				x = x<<8 | uint32(buf[n+k])
+			}
+			out[i+j] = x
+			n += vl
+		}
+	}
+	*e.v = out
+	return nil
+}
+
+func groupVarintLen(x uint32) int {
+	switch {
+	case x < 1<<8:
+		return 1
+	case x < 1<<16:
+		return 2
+	case x < 1<<24:
+		return 3
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,46 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupVarintUint32s(t *testing.T) {
+	v := []uint32{1, 256, 65536, 16777216, 7}
+	enc := New(GroupVarintUint32s(&v))
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{
+			0x05,
+			0xe4, 0x01, 0x00, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
+			0x00, 0x07,
+		},
+		buf,
+	)
+
+	var v2 []uint32
+	require.NoError(t, New(GroupVarintUint32s(&v2)).Decode(buf))
+	require.Equal(t, v, v2)
+
+	for _, l := range []int{0, 1, 3, 4, 8, 9} {
+		v = make([]uint32, l)
+		for i := range v {
+			v[i] = uint32(i) * 0x01234567
+		}
+		buf = enc.Encode()
+		require.NoError(t, New(GroupVarintUint32s(&v2)).Decode(buf))
+		require.Equal(t, v, v2)
+		for i := 0; i < len(buf); i++ {
+			require.ErrorIs(t, New(GroupVarintUint32s(&v2)).Decode(buf[:i]), io.ErrUnexpectedEOF)
+		}
+	}
+
+	require.ErrorIs(
+		t,
+		New(GroupVarintUint32s(&v2)).DecodeLimited([]byte{0x03, 0x00, 0, 0, 0}, Limits{MaxElements: 2}),
+		ErrLimitExceeded,
+	)
+}
@@ -0,0 +1,147 @@
+package encode
+
+import (
+	"encoding/binary"
+)
+
+var ErrInvalidGRPCFrame = NewInvalidValueError("encode: invalid gRPC frame flag")
+var ErrGRPCCompressed = NewInvalidValueError("encode: compressed gRPC message without a codec")
+var ErrInvalidGRPCLength = NewInvalidValueError("encode: gRPC message length mismatch")
+
+// Encode item as a gRPC message frame, as sent in the body of a gRPC request or response over
+// HTTP/2: a byte that's 1 if the message is compressed and 0 otherwise, then the length of the
+// message as a big endian uint32, then the message. Messages are always written uncompressed, and
+// decoding a compressed one returns ErrGRPCCompressed; see GRPCFrameCompressed for compression.
+// Decode returns ErrInvalidGRPCLength if item doesn't take up exactly the whole message.
+func GRPCFrame(item Item) Item {
+	return grpcFrame{item: item, compressed: new(bool)}
+}
+
+// GRPCFrameCompressed is GRPCFrame, except that the message is compressed using codec when
+// *compressed is true, and on decode *compressed is set to whether the message was. As with
+// Compressed, the compressed message is remembered so that it's only compressed once per Encode.
+func GRPCFrameCompressed(item Item, compressed *bool, codec Codec) Item {
+	return grpcFrame{
+		item:       item,
+		compressed: compressed,
+		codec:      codec,
+		state:      &compressedState{},
+	}
+}
+
+const grpcHeaderSize = 5
+
+type grpcFrame struct {
+	item       Item
+	compressed *bool
+	codec      Codec
+	state      *compressedState
+}
+
+func (e grpcFrame) setOffset(offset int) {
+	// The message is encoded on its own, whether or not it's compressed.
+	setOffset(e.item, 0)
+}
+func (e grpcFrame) block() []byte {
+	return compressed{item: e.item, codec: e.codec, state: e.state}.compress()
+}
+func (e grpcFrame) Encode(buf []byte) {
+	if *e.compressed {
+		block := e.block()
+		buf[0] = 1
+		binary.BigEndian.PutUint32(buf[1:], uint32(len(block)))
+		copy(buf[grpcHeaderSize:], block)
+		return
+	}
+	size := e.item.Size()
+	binary.BigEndian.PutUint32(buf[1:], uint32(size))
+	e.item.Encode(buf[grpcHeaderSize : grpcHeaderSize+size])
+}
+func (e grpcFrame) Size() int {
+	if *e.compressed {
+		return grpcHeaderSize + len(e.block())
+	}
+	return grpcHeaderSize + e.item.Size()
+}
+func (e grpcFrame) kind() string {
+	if e.codec != nil {
+		return "GRPCFrameCompressed"
+	}
+	return "GRPCFrame"
+}
+func (e grpcFrame) withByteOrder(order binary.ByteOrder) Item {
+	if e.state == nil {
+		return GRPCFrame(withByteOrder(e.item, order))
+	}
+	return GRPCFrameCompressed(withByteOrder(e.item, order), e.compressed, e.codec)
+}
+func (e grpcFrame) sizeBounds() (min, max int) {
+	return grpcHeaderSize, -1
+}
+func (e grpcFrame) dependsOnEarlier() bool {
+	return dependsOnEarlier(e.item)
+}
+func (e grpcFrame) skip(buf []byte) (int, error) {
+	_, msg, err := readGRPCFrame(buf)
+	if err != nil {
+		return 0, err
+	}
+	return grpcHeaderSize + len(msg), nil
+}
+func (e grpcFrame) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e grpcFrame) decodeLimited(buf []byte, s *decodeState) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+	isCompressed, msg, err := readGRPCFrame(buf)
+	if err != nil {
+		return err
+	}
+	if !isCompressed {
+		err = s.checkLength(uint64(len(msg)))
+		if err != nil {
+			return err
+		}
+		err = decodeItem(e.item, msg, s)
+		if err != nil {
+			return err
+		}
+		// Size is item's size plus the header, so it must be the same as the frame's.
+		if e.item.Size() != len(msg) {
+			return ErrInvalidGRPCLength
+		}
+		*e.compressed = false
+		return nil
+	}
+	if e.codec == nil {
+		return ErrGRPCCompressed
+	}
+	// The block is decompressed and remembered in the same way as Compressed, without its length
+	// prefix.
+	err = compressed{item: e.item, codec: e.codec, state: e.state}.decodeBlock(msg, s)
+	if err != nil {
+		return err
+	}
+	*e.compressed = true
+	return nil
+}
+
+// Returns whether the gRPC frame at the start of buf is compressed, and its message.
+func readGRPCFrame(buf []byte) (bool, []byte, error) {
+	if len(buf) < grpcHeaderSize {
+		return false, nil, ErrShortBuffer
+	}
+	if buf[0] > 1 {
+		return false, nil, ErrInvalidGRPCFrame
+	}
+	l := binary.BigEndian.Uint32(buf[1:])
+	if uint64(len(buf[grpcHeaderSize:])) < uint64(l) {
+		return false, nil, ErrShortBuffer
+	}
+	end := grpcHeaderSize + int(l)
+	return buf[0] == 1, buf[grpcHeaderSize:end:end], nil
+}
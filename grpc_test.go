@@ -0,0 +1,61 @@
+package encode
+
+import (
+	"compress/flate"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGRPCFrame(t *testing.T) {
+	var s string
+	var b bool
+	enc := New(GRPCFrame(LengthDelimString(&s)), Bool(&b))
+
+	s, b = "hi", true
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x00, 0x03, 0x02, 'h', 'i', 0x01}, buf)
+
+	s, b = "", false
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, "hi", s)
+	require.True(t, b)
+
+	require.ErrorIs(t, enc.Decode(buf[:7]), ErrShortBuffer)
+	require.ErrorIs(t, enc.Decode([]byte{0x02, 0, 0, 0, 0}), ErrInvalidGRPCFrame)
+	require.ErrorIs(t, enc.Decode([]byte{0x01, 0, 0, 0, 0, 0x01}), ErrGRPCCompressed)
+	require.ErrorIs(t, enc.DecodeLimited(buf, Limits{MaxLength: 2}), ErrLimitExceeded)
+
+	// The message holds more than the item, which would otherwise misalign the items after it.
+	var a, c byte
+	short := New(GRPCFrame(Byte(&a)), Byte(&c))
+	require.ErrorIs(
+		t,
+		short.Decode([]byte{0x00, 0x00, 0x00, 0x00, 0x02, 0x07, 0x08, 0x09}),
+		ErrInvalidGRPCLength,
+	)
+}
+
+func TestGRPCFrameCompressed(t *testing.T) {
+	var s string
+	var compressed bool
+	codec := Flate(flate.BestCompression)
+	enc := New(GRPCFrameCompressed(LengthDelimString(&s), &compressed, codec))
+
+	s, compressed = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", true
+	buf := enc.Encode()
+	require.Equal(t, byte(0x01), buf[0])
+	require.Less(t, len(buf), 5+41)
+
+	s, compressed = "", false
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", s)
+	require.True(t, compressed)
+	require.Equal(t, len(buf), enc.EncodedSize())
+
+	// Uncompressed messages decode too.
+	require.NoError(t, enc.Decode([]byte{0x00, 0x00, 0x00, 0x00, 0x02, 0x01, 'x'}))
+	require.Equal(t, "x", s)
+	require.False(t, compressed)
+	require.Equal(t, 7, enc.EncodedSize())
+}
@@ -0,0 +1,77 @@
+package encode
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var ErrNoCommonVersion = errors.New("encode: peers have no format version in common")
+
+// The largest version list a peer may send during Negotiate.
+const maxNegotiateFrameSize = 4096
+
+// Negotiate agrees on a format version with the peer on the other end of rwc, who must also be
+// calling Negotiate, and stores it in *version. Each side sends the versions it supports as a single
+// frame (see AppendFrame), and both sides pick the highest version appearing in both lists, so that
+// the result is the same on each end. Versioned items bound to version then encode with the chosen
+// version for the rest of the connection:
+//
+//   var version uint64
+//   err := encode.Negotiate(conn, &version, 1, 2)
+//   ...
+//   enc := encode.New(encode.Versioned(&version, map[uint64]encode.Item{1: v1, 2: v2}))
+//
+// Negotiate never reads past the end of the peer's frame, so rwc can continue to be used for
+// messages afterwards. *version is left unchanged if Negotiate returns an error, which is
+// ErrNoCommonVersion if the peers don't share any version.
+//
+// If reading the peer's versions fails, Negotiate closes rwc before returning. Its own versions are
+// written concurrently with the read, and closing is the only way to stop that write if the peer
+// isn't reading, so rwc is unusable afterwards either way.
+func Negotiate(rwc io.ReadWriteCloser, version *uint64, supported ...uint64) error {
+	var payload []byte
+	for _, v := range supported {
+		payload = binary.AppendUvarint(payload, v)
+	}
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := rwc.Write(AppendFrame(nil, payload))
+		writeErr <- err
+	}()
+
+	frame, err := readFrame(rwc, maxNegotiateFrameSize)
+	if err != nil {
+		_ = rwc.Close()
+		<-writeErr
+		return err
+	}
+	err = <-writeErr
+	if err != nil {
+		return err
+	}
+
+	best := uint64(0)
+	found := false
+	for len(frame) > 0 {
+		theirs, n := binary.Uvarint(frame)
+		if n == 0 {
+			return io.ErrUnexpectedEOF
+		}
+		if n < 0 {
+			return ErrVarintOverflow
+		}
+		frame = frame[n:]
+		for _, ours := range supported {
+			if ours == theirs && (!found || theirs > best) {
+				best = theirs
+				found = true
+			}
+		}
+	}
+	if !found {
+		return ErrNoCommonVersion
+	}
+	*version = best
+	return nil
+}
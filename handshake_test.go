@@ -0,0 +1,92 @@
+package encode
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	check := func(a []uint64, b []uint64) (uint64, uint64, error, error) {
+		connA, connB := net.Pipe()
+		defer connA.Close()
+		defer connB.Close()
+
+		type result struct {
+			v   uint64
+			err error
+		}
+		done := make(chan result)
+		go func() {
+			var v uint64
+			err := Negotiate(connB, &v, b...)
+			done <- result{v, err}
+		}()
+		var vA uint64
+		errA := Negotiate(connA, &vA, a...)
+		r := <-done
+		return vA, r.v, errA, r.err
+	}
+
+	vA, vB, errA, errB := check([]uint64{1, 2, 3}, []uint64{2, 3, 4})
+	require.NoError(t, errA)
+	require.NoError(t, errB)
+	require.Equal(t, uint64(3), vA)
+	require.Equal(t, uint64(3), vB)
+
+	vA, vB, errA, errB = check([]uint64{1000000}, []uint64{7, 1000000, 1})
+	require.NoError(t, errA)
+	require.NoError(t, errB)
+	require.Equal(t, uint64(1000000), vA)
+	require.Equal(t, uint64(1000000), vB)
+
+	_, _, errA, errB = check([]uint64{1}, []uint64{2})
+	require.ErrorIs(t, errA, ErrNoCommonVersion)
+	require.ErrorIs(t, errB, ErrNoCommonVersion)
+}
+
+func TestNegotiateVersioned(t *testing.T) {
+	connA, connB := net.Pipe()
+	defer connA.Close()
+	defer connB.Close()
+
+	var versionB uint64
+	done := make(chan error)
+	go func() { done <- Negotiate(connB, &versionB, 1, 2) }()
+	var versionA uint64
+	require.NoError(t, Negotiate(connA, &versionA, 1, 2, 3))
+	require.NoError(t, <-done)
+
+	newEnc := func(version *uint64, x *uint32, s *string) Encoding {
+		return New(Versioned(version, map[uint64]Item{
+			1: FixedUint32(x),
+			2: Group(FixedUint32(x), LengthDelimString(s)),
+		}))
+	}
+	xA, sA := uint32(7), "seven"
+	go func() { done <- WriteMessage(connA, newEnc(&versionA, &xA, &sA)) }()
+	var xB uint32
+	var sB string
+	require.NoError(t, ReadMessage(connB, newEnc(&versionB, &xB, &sB), 64))
+	require.NoError(t, <-done)
+	require.Equal(t, uint64(2), versionB)
+	require.Equal(t, uint32(7), xB)
+	require.Equal(t, "seven", sB)
+}
+
+func TestNegotiateReadError(t *testing.T) {
+	connA, connB := net.Pipe()
+	defer connB.Close()
+
+	// The peer claims a frame too large to accept and then never reads, so Negotiate's write can
+	// only finish by connA being closed.
+	go func() { _, _ = connB.Write(AppendFrame(nil, make([]byte, maxNegotiateFrameSize+1))) }()
+	version := uint64(5)
+	err := Negotiate(connA, &version, 1)
+	require.ErrorIs(t, err, ErrFrameTooLarge)
+	require.Equal(t, uint64(5), version)
+	_, err = connA.Write([]byte{0})
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+}
@@ -0,0 +1,77 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+var ErrInvalidIndex = NewInvalidValueError("encode: invalid index footer")
+
+// AppendIndexed is Append, except that it also appends a footer indexing where each item starts,
+// so that DecodeItemAt can later decode a single item without reading the others. This is the
+// encoding followed by the offset of each item as a big endian uint64, followed by the number of
+// items as a big endian uint32. The offsets are from the start of the encoding, not of dst.
+//
+// The footer comes after the encoding, so Decode can also decode the result as usual.
+func (enc Encoding) AppendIndexed(dst []byte) []byte {
+	dst = enc.Append(dst)
+	offset := 0
+	for _, item := range enc.items {
+		dst = binary.BigEndian.AppendUint64(dst, uint64(offset))
+		setOffset(item, offset)
+		offset += item.Size()
+	}
+	return binary.BigEndian.AppendUint32(dst, uint32(len(enc.items)))
+}
+
+// DecodeItemAt decodes only the i'th item of enc from r, which holds the size bytes that
+// AppendIndexed appended, reading only the footer and the bytes of that item. Since the footer's
+// offsets are from the start of the encoding, r must start there too rather than at the start of
+// the dst that AppendIndexed appended to; use io.NewSectionReader if it doesn't.
+//
+// The item must not depend on any other item in enc having been decoded. That rules out If, Switch,
+// and the data of Pointer, along with any item that contains one of them, like a Group.
+//
+// Returns ErrInvalidIndex if the footer is malformed or doesn't have the same number of items as
+// enc. Panics if i is out of range.
+func (enc Encoding) DecodeItemAt(r io.ReaderAt, size int64, i int) error {
+	if i < 0 || i >= len(enc.items) {
+		panic(fmt.Sprintf("invalid i=%d, must be in [0, %d)", i, len(enc.items)))
+	}
+	footerSize := int64(indexFooterSize(len(enc.items)))
+	if size < footerSize {
+		return ErrInvalidIndex
+	}
+	footer := make([]byte, footerSize)
+	n, err := r.ReadAt(footer, size-footerSize)
+	// The footer ends at the end of r, so r may return io.EOF along with all of it.
+	if err != nil && !(err == io.EOF && n == len(footer)) {
+		return err
+	}
+	if binary.BigEndian.Uint32(footer[len(footer)-4:]) != uint32(len(enc.items)) {
+		return ErrInvalidIndex
+	}
+	encodingSize := uint64(size - footerSize)
+	start := binary.BigEndian.Uint64(footer[i*8:])
+	end := encodingSize
+	if i+1 < len(enc.items) {
+		end = binary.BigEndian.Uint64(footer[(i+1)*8:])
+	}
+	if start > end || end > encodingSize {
+		return ErrInvalidIndex
+	}
+
+	buf := make([]byte, end-start)
+	_, err = r.ReadAt(buf, int64(start))
+	if err != nil {
+		return err
+	}
+	item := enc.items[i]
+	setOffset(item, int(start))
+	return item.Decode(buf)
+}
+
+func indexFooterSize(n int) int {
+	return n*8 + 4
+}
@@ -0,0 +1,86 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexed(t *testing.T) {
+	var a uint32
+	var b, c []byte
+	var d string
+	enc := New(
+		FixedUint32(&a),
+		LengthDelimBytes(&b),
+		LengthDelimBytes(&c),
+		Align(8),
+		LengthDelimString(&d),
+	)
+
+	a = 0xdeadbeef
+	b = bytes.Repeat([]byte{0x01}, 1000)
+	c = []byte("hello")
+	d = "world"
+	buf := enc.AppendIndexed([]byte("prefix"))
+	require.Equal(t, []byte("prefix"), buf[:6])
+	buf = buf[6:]
+	require.Equal(t, enc.Encode(), buf[:len(buf)-indexFooterSize(5)])
+
+	var a2 uint32
+	var b2, c2 []byte
+	var d2 string
+	enc2 := New(
+		FixedUint32(&a2),
+		LengthDelimBytes(&b2),
+		LengthDelimBytes(&c2),
+		Align(8),
+		LengthDelimString(&d2),
+	)
+
+	r := &countingReaderAt{r: bytes.NewReader(buf)}
+	require.NoError(t, enc2.DecodeItemAt(r, int64(len(buf)), 2))
+	require.Equal(t, c, c2)
+	require.Nil(t, b2)
+	require.Less(t, r.n, 100)
+
+	require.NoError(t, enc2.DecodeItemAt(r, int64(len(buf)), 4))
+	require.Equal(t, d, d2)
+	require.NoError(t, enc2.DecodeItemAt(r, int64(len(buf)), 0))
+	require.Equal(t, a, a2)
+
+	require.NoError(t, enc2.Decode(buf))
+	require.Equal(t, b, b2)
+
+	require.ErrorIs(t, New(FixedUint32(&a2)).DecodeItemAt(r, int64(len(buf)), 0), ErrInvalidIndex)
+	require.ErrorIs(t, enc2.DecodeItemAt(r, 3, 0), ErrInvalidIndex)
+	require.Panics(t, func() { _ = enc2.DecodeItemAt(r, int64(len(buf)), 5) })
+
+	// io.ReaderAt allows returning io.EOF along with a read that ends at the end of the input.
+	d2 = ""
+	require.NoError(t, enc2.DecodeItemAt(eofReaderAt{buf}, int64(len(buf)), 4))
+	require.Equal(t, d, d2)
+}
+
+type countingReaderAt struct {
+	r *bytes.Reader
+	n int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.n += len(p)
+	return r.r.ReadAt(p, off)
+}
+
+// Returns io.EOF with every read that reaches the end of b, even if it's complete.
+type eofReaderAt struct{ b []byte }
+
+func (r eofReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n := copy(p, r.b[off:])
+	if off+int64(n) == int64(len(r.b)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,90 @@
+package encode
+
+// A StringTable holds one copy of each distinct string decoded through it, so that identical
+// strings across many decoded records, like enum-like labels or tenant IDs, share memory instead
+// of each being allocated separately.
+//
+// A StringTable only grows, so it's best scoped to one batch of related records and then dropped or
+// Reset. It is not safe for concurrent use.
+type StringTable struct {
+	m map[string]string
+}
+
+func NewStringTable() *StringTable {
+	return &StringTable{m: make(map[string]string)}
+}
+
+// The number of distinct strings in t.
+func (t *StringTable) Len() int {
+	return len(t.m)
+}
+
+// Forget every string in t, so that they can be garbage collected once nothing else refers to
+// them.
+func (t *StringTable) Reset() {
+	t.m = make(map[string]string)
+}
+
+// Returns the copy of b held in t, adding one if there isn't yet.
+func (t *StringTable) intern(b []byte) string {
+	// The compiler doesn't allocate for string(b) when it's only used as a map key.
+	if s, ok := t.m[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	t.m[s] = s
+	return s
+}
+
+// A Decoder decodes with options beyond those of Encoding.Decode, and carries state between the
+// buffers that it decodes. The zero Decoder behaves the same as Encoding.Decode.
+//
+//   d := encode.Decoder{Strings: encode.NewStringTable()}
+//   for _, buf := range bufs {
+//   	err := d.Decode(enc, buf)
+//   	...
+//   }
+type Decoder struct {
+	// Enforced in the same way as by DecodeLimited.
+	Limits Limits
+	// If non-nil, LengthDelimString and ShortString take their strings from this table instead
+	// of allocating a new one for each value.
+	Strings *StringTable
+	// If non-nil, the byte slices and strings made by LengthDelimBytes, LengthDelimString,
+	// ShortString, Base64Bytes, and HexBytes are allocated from this arena, and are only valid
+	// until it is Reset. Strings that are interned in Strings are allocated normally, since they
+	// outlive any one arena.
+	Arena *Arena
+	// If true, buffers that aren't canonical encodings are rejected, as in DecodeCanonical.
+	Canonical bool
+}
+
+// Decode buf into enc's items, using d's options.
+func (d Decoder) Decode(enc Encoding, buf []byte) error {
+	err := decodeItems(enc.items, buf, 0, &decodeState{
+		limits:  d.Limits,
+		strings: d.Strings,
+		arena:   d.Arena,
+	})
+	if err != nil {
+		return err
+	}
+	if d.Canonical {
+		return checkCanonical(enc, buf)
+	}
+	return nil
+}
+
+// Returns b as a string, from s's StringTable or Arena if it has one.
+func (s *decodeState) string(b []byte) string {
+	if s == nil {
+		return string(b)
+	}
+	if s.strings != nil {
+		return s.strings.intern(b)
+	}
+	if s.arena != nil {
+		return s.arena.string(b)
+	}
+	return string(b)
+}
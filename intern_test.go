@@ -0,0 +1,44 @@
+package encode
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoderStrings(t *testing.T) {
+	label := "us-east-1"
+	short := "ok"
+	buf := New(LengthDelimString(&label), ShortString(&short, 4)).Encode()
+
+	d := Decoder{Strings: NewStringTable()}
+	var label1, label2, short1, short2 string
+	require.NoError(t, d.Decode(New(LengthDelimString(&label1), ShortString(&short1, 4)), buf))
+	require.NoError(t, d.Decode(New(LengthDelimString(&label2), ShortString(&short2, 4)), buf))
+	require.Equal(t, label, label1)
+	require.Equal(t, short, short1)
+	require.Equal(t, 2, d.Strings.Len())
+	require.Equal(t, unsafe.StringData(label1), unsafe.StringData(label2))
+	require.Equal(t, unsafe.StringData(short1), unsafe.StringData(short2))
+
+	// Must not alias buf.
+	buf[1] = 'U'
+	require.Equal(t, "us-east-1", label1)
+
+	d.Strings.Reset()
+	require.Equal(t, 0, d.Strings.Len())
+
+	// The zero Decoder doesn't intern.
+	require.NoError(t, Decoder{}.Decode(New(LengthDelimString(&label1)), buf))
+	require.NoError(t, Decoder{}.Decode(New(LengthDelimString(&label2)), buf))
+	require.Equal(t, "Us-east-1", label2)
+	require.True(t, unsafe.StringData(label1) != unsafe.StringData(label2))
+}
+
+func TestDecoderLimits(t *testing.T) {
+	s := "hello"
+	buf := New(LengthDelimString(&s)).Encode()
+	d := Decoder{Limits: Limits{MaxLength: 4}}
+	require.ErrorIs(t, d.Decode(New(LengthDelimString(&s)), buf), ErrLimitExceeded)
+}
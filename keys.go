@@ -0,0 +1,79 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/hex"
+)
+
+// PrefixSuccessor returns the smallest key that sorts after every key beginning with prefix, for
+// use as the exclusive end of a range scan over prefix. Returns nil if there is no such key, which
+// happens when prefix is empty or all 0xFF, meaning the scan should be unbounded.
+func PrefixSuccessor(prefix []byte) []byte {
+	end := len(prefix)
+	for end > 0 && prefix[end-1] == 0xFF {
+		end--
+	}
+	if end == 0 {
+		return nil
+	}
+	successor := append([]byte(nil), prefix[:end]...)
+	successor[end-1]++
+	return successor
+}
+
+// Compare two encoded keys item-by-item. cmp is -1, 0, or 1 as a sorts before, the same as, or
+// after b, and field is the index of the first item that differs, or -1 if none do.
+//
+// Both a and b are decoded into t's items, so the values bound to them are overwritten.
+func (t Tuple) Compare(a []byte, b []byte) (cmp int, field int, err error) {
+	i := 0
+	j := 0
+	for k, item := range t.items {
+		last := k == len(t.items)-1
+		err = item.DecodeTuple(a[i:], last)
+		if err != nil {
+			return 0, k, err
+		}
+		sizeA := item.SizeTuple(last)
+		err = item.DecodeTuple(b[j:], last)
+		if err != nil {
+			return 0, k, err
+		}
+		sizeB := item.SizeTuple(last)
+
+		cmp = bytes.Compare(a[i:i+sizeA], b[j:j+sizeB])
+		if cmp != 0 {
+			return cmp, k, nil
+		}
+		i += sizeA
+		j += sizeB
+	}
+	return 0, -1, nil
+}
+
+// EncodeHex renders key as lowercase hex, which sorts the same way as key itself, for storing
+// sortable keys in systems that only accept strings.
+func EncodeHex(key []byte) string {
+	return hex.EncodeToString(key)
+}
+
+// DecodeHex reverses EncodeHex.
+func DecodeHex(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
+
+// The base32hex alphabet from RFC 4648 is in ASCII order, and leaving off padding means that
+// shorter keys still sort before longer keys that they're a prefix of.
+var sortableBase32 = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// EncodeBase32 renders key as unpadded base32 using the "extended hex" alphabet 0-9 A-V, which
+// sorts the same way as key itself and is 20% shorter than EncodeHex.
+func EncodeBase32(key []byte) string {
+	return sortableBase32.EncodeToString(key)
+}
+
+// DecodeBase32 reverses EncodeBase32.
+func DecodeBase32(s string) ([]byte, error) {
+	return sortableBase32.DecodeString(s)
+}
@@ -0,0 +1,89 @@
+package encode
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixSuccessor(t *testing.T) {
+	require.Equal(t, []byte{0x01, 0x03}, PrefixSuccessor([]byte{0x01, 0x02}))
+	require.Equal(t, []byte{0x02}, PrefixSuccessor([]byte{0x01, 0xFF, 0xFF}))
+	require.Nil(t, PrefixSuccessor([]byte{0xFF, 0xFF}))
+	require.Nil(t, PrefixSuccessor(nil))
+}
+
+func TestTupleCompare(t *testing.T) {
+	var a, b uint64
+	var c int64
+	tuple := NewTuple(OrdUvarint64(&a), OrdUvarint64(&b), OrdVarint64(&c))
+
+	a, b, c = 1, 500, -3
+	key1 := tuple.Encode()
+	a, b, c = 1, 500, 7
+	key2 := tuple.Encode()
+	a, b, c = 1, 2, 7
+	key3 := tuple.Encode()
+
+	cmp, field, err := tuple.Compare(key1, key2)
+	require.NoError(t, err)
+	require.Equal(t, -1, cmp)
+	require.Equal(t, 2, field)
+
+	cmp, field, err = tuple.Compare(key2, key3)
+	require.NoError(t, err)
+	require.Equal(t, 1, cmp)
+	require.Equal(t, 1, field)
+
+	cmp, field, err = tuple.Compare(key3, key3)
+	require.NoError(t, err)
+	require.Equal(t, 0, cmp)
+	require.Equal(t, -1, field)
+}
+
+func TestSortableText(t *testing.T) {
+	keys := [][]byte{
+		{},
+		{0x00},
+		{0x00, 0x00},
+		{0x00, 0x01},
+		{0x01},
+		{0x7f, 0xff, 0xff},
+		{0x80},
+		{0xff},
+		{0xff, 0x00},
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 1000; i++ {
+		k := make([]byte, r.Intn(8))
+		for j := range k {
+			k[j] = byte(r.Intn(4)) * 0x55
+		}
+		keys = append(keys, k)
+	}
+
+	for _, a := range keys {
+		for _, b := range keys[:20] {
+			expected := bytes.Compare(a, b)
+			require.Equal(t, expected, strings.Compare(EncodeHex(a), EncodeHex(b)), "%x %x", a, b)
+			require.Equal(
+				t,
+				expected,
+				strings.Compare(EncodeBase32(a), EncodeBase32(b)),
+				"%x %x",
+				a, b,
+			)
+		}
+
+		a2, err := DecodeHex(EncodeHex(a))
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(a, a2))
+		a2, err = DecodeBase32(EncodeBase32(a))
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(a, a2))
+	}
+}
@@ -0,0 +1,91 @@
+package encode
+
+// Encode v as an unsigned LEB128, as used by WebAssembly and DWARF. This is exactly the same
+// encoding as Uvarint64.
+func ULEB128(v *uint64) Item {
+	return Uvarint64(v)
+}
+
+// Encode v as a signed LEB128, as used by WebAssembly and DWARF: v's two's complement bits are
+// written 7 at a time from lowest-order to highest-order, with the high bit of each byte set if
+// more bytes follow, until the remaining bits are all the same as the sign bit of the last byte
+// written.
+//
+//   -1    7f
+//   63    3f
+//   64    c0 00
+//   -65   bf 7f
+//
+// Unlike zigzag encodings such as protobuf's sint64, negative numbers are not interleaved with
+// positive ones.
+func SLEB128(v *int64) Item {
+	return sleb128{v}
+}
+
+type sleb128 struct{ v *int64 }
+
+func (e sleb128) Encode(buf []byte) {
+	x := *e.v
+	for i := 0; ; i++ {
+		b := byte(x & 0x7f)
+		x >>= 7
+		if (x == 0 && b&0x40 == 0) || (x == -1 && b&0x40 != 0) {
+			buf[i] = b
+			return
+		}
+		buf[i] = b | 0x80
+	}
+}
+func (e sleb128) Size() int {
+	x := *e.v
+	for n := 1; ; n++ {
+		b := byte(x & 0x7f)
+		x >>= 7
+		if (x == 0 && b&0x40 == 0) || (x == -1 && b&0x40 != 0) {
+			return n
+		}
+	}
+}
+func (e sleb128) kind() string {
+	return "SLEB128"
+}
+func (e sleb128) sizeBounds() (min, max int) {
+	return 1, 10
+}
+func (e sleb128) skip(buf []byte) (int, error) {
+	for i := 0; i < len(buf) && i < 10; i++ {
+		if buf[i]&0x80 == 0 {
+			return i + 1, nil
+		}
+	}
+	if len(buf) < 10 {
+		return 0, ErrShortBuffer
+	}
+	return 0, ErrVarintOverflow
+}
+func (e sleb128) Decode(buf []byte) error {
+	var x int64
+	shift := uint(0)
+	for i := 0; ; i++ {
+		if i >= len(buf) {
+			return ErrShortBuffer
+		}
+		b := buf[i]
+		if i == 9 {
+			// Only the lowest bit of the tenth byte is part of the value, the rest must match
+			// it as sign extension.
+			if b != 0x00 && b != 0x7f {
+				return ErrVarintOverflow
+			}
+		}
+		x |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				x |= -1 << shift
+			}
+			*e.v = x
+			return nil
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package encode
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLEB128(t *testing.T) {
+	check := func(x int64, expected []byte) {
+		require.Equal(t, expected, New(SLEB128(&x)).Encode())
+		var x2 int64
+		require.NoError(t, New(SLEB128(&x2)).Decode(expected))
+		require.Equal(t, x, x2)
+	}
+	check(0, []byte{0x00})
+	check(2, []byte{0x02})
+	check(-2, []byte{0x7e})
+	check(63, []byte{0x3f})
+	check(-64, []byte{0x40})
+	check(64, []byte{0xc0, 0x00})
+	check(-65, []byte{0xbf, 0x7f})
+	check(127, []byte{0xff, 0x00})
+	check(-128, []byte{0x80, 0x7f})
+	check(-123456, []byte{0xc0, 0xbb, 0x78})
+	check(math.MaxInt64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00})
+	check(math.MinInt64, []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x7f})
+
+	var x int64
+	var y bool
+	min := []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x7f, 0x01}
+	require.NoError(t, New(SLEB128(&x), Bool(&y)).DecodeFields(min, 1))
+	require.True(t, y)
+	require.ErrorIs(t, New(SLEB128(&x)).Decode([]byte{0x80, 0x80}), io.ErrUnexpectedEOF)
+	require.ErrorIs(
+		t,
+		New(SLEB128(&x)).Decode([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}),
+		ErrOverflowVarint,
+	)
+}
+
+func TestULEB128(t *testing.T) {
+	x := uint64(624485)
+	require.Equal(t, []byte{0xe5, 0x8e, 0x26}, New(ULEB128(&x)).Encode())
+}
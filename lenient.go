@@ -0,0 +1,64 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DecodeLenient is Decode, except that it keeps going after a fixed-size item fails to decode, so
+// that one call finds every bad field in a partially corrupted record rather than only the first.
+// The returned error joins one error per bad field (see errors.Join), each labeled with the item's
+// index, name (see Named), and kind in the same way as Dump. Items that decoded successfully are
+// set as usual, including those after a bad field.
+//
+// Decoding still stops at the first error from a variable-length item, since the items after it
+// can't be found without knowing its size, and when buf is too short for the next item.
+func (enc Encoding) DecodeLenient(buf []byte) error {
+	var errs []error
+	decodeItemsLenient("", enc.items, buf, 0, &errs)
+	return errors.Join(errs...)
+}
+
+// Decodes items from buf, which is at offset base in the encoding, appending an error for each bad
+// item to errs. Returns the number of bytes of buf used, and false if decoding had to stop early.
+func decodeItemsLenient(
+	prefix string,
+	items []Item,
+	buf []byte,
+	base int,
+	errs *[]error,
+) (int, bool) {
+	i := 0
+	for idx, item := range items {
+		setOffset(item, base+i)
+		label := prefix + fmt.Sprint(idx)
+		inner := item
+		if n, ok := item.(named); ok {
+			label += " " + n.name
+			inner = n.item
+		}
+		if g, ok := inner.(group); ok {
+			n, ok := decodeItemsLenient(label+".", g.items, buf[i:], base+i, errs)
+			i += n
+			if !ok {
+				return i, false
+			}
+			continue
+		}
+		err := item.Decode(buf[i:])
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s %s: %w", label, itemKind(inner), err))
+			min, max := sizeBounds(inner)
+			if min != max || len(buf[i:]) < min {
+				return i, false
+			}
+			i += min
+			continue
+		}
+		i += item.Size()
+		if i > len(buf) {
+			i = len(buf)
+		}
+	}
+	return i, true
+}
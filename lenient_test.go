@@ -0,0 +1,59 @@
+package encode
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeLenient(t *testing.T) {
+	var a, c, e bool
+	var b uint16
+	var d byte
+	var s string
+	enc := New(
+		Bool(&a),
+		Named("kind", Enum(&d, 1, 2)),
+		Group(FixedUint16(&b), Named("c", Bool(&c))),
+		LengthDelimString(&s),
+		Bool(&e),
+	)
+
+	require.NoError(t, enc.DecodeLenient([]byte{0x01, 0x01, 0x00, 0x07, 0x01, 0x02, 'h', 'i', 0x01}))
+	require.Equal(t, uint16(7), b)
+	require.Equal(t, "hi", s)
+
+	err := enc.DecodeLenient([]byte{0x02, 0x03, 0x00, 0x08, 0x05, 0x02, 'h', 'o', 0x09})
+	require.ErrorIs(t, err, ErrInvalidBool)
+	require.ErrorIs(t, err, ErrInvalidEnum)
+	require.Equal(t, uint16(8), b)
+	require.Equal(t, "ho", s)
+	require.Equal(
+		t,
+		"0 Bool: "+ErrInvalidBool.Error()+"\n"+
+			"1 kind Enum: "+ErrInvalidEnum.Error()+"\n"+
+			"2.1 c Bool: "+ErrInvalidBool.Error()+"\n"+
+			"4 Bool: "+ErrInvalidBool.Error(),
+		err.Error(),
+	)
+
+	// Nothing after a bad variable-length item can be found.
+	err = enc.DecodeLenient([]byte{0x02, 0x01, 0x00, 0x08, 0x01, 0x05, 'h', 'o'})
+	require.ErrorIs(t, err, ErrInvalidBool)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Len(t, unwrapJoined(err), 2)
+
+	err = enc.DecodeLenient([]byte{0x00, 0x01, 0x00})
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Len(t, unwrapJoined(err), 1)
+}
+
+func unwrapJoined(err error) []error {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		return joined.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package encode
+
+import "errors"
+
+var ErrLimitExceeded = errors.New("encode: decode limit exceeded")
+
+// Limits bound the resources that decoding an untrusted buffer may use. A zero field means no limit
+// of that kind.
+type Limits struct {
+	// The longest length-delimited field that may be decoded, in bytes. This also bounds the
+	// decompressed size of Compressed items.
+	MaxLength int
+	// The most elements that a repeated item may decode.
+	MaxElements int
+	// How deeply items that contain other items, like Group, Compressed, Sealed, and Versioned, may
+	// be nested.
+	MaxDepth int
+}
+
+// DecodeLimited is Decode, except that it returns ErrLimitExceeded as soon as decoding buf would
+// exceed any of limits, before allocating for it.
+func (enc Encoding) DecodeLimited(buf []byte, limits Limits) error {
+	return decodeItems(enc.items, buf, 0, &decodeState{limits: limits})
+}
+
+// Implemented by items that allocate or recurse based on what they find in the buffer, so that
+// they can enforce Limits. Items that contain other items must decode them with decodeItem so that
+// the limits reach them too.
+type limitedItem interface {
+	decodeLimited(buf []byte, s *decodeState) error
+}
+
+// The state of one call to DecodeLimited or Decoder.Decode. A nil *decodeState enforces nothing,
+// which is how plain Decode shares implementations with them.
+type decodeState struct {
+	limits  Limits
+	depth   int
+	strings *StringTable
+	arena   *Arena
+}
+
+func decodeItem(item Item, buf []byte, s *decodeState) error {
+	if li, ok := item.(limitedItem); ok {
+		return li.decodeLimited(buf, s)
+	}
+	return item.Decode(buf)
+}
+
+func (s *decodeState) enter() error {
+	if s == nil {
+		return nil
+	}
+	s.depth++
+	if s.limits.MaxDepth > 0 && s.depth > s.limits.MaxDepth {
+		return ErrLimitExceeded
+	}
+	return nil
+}
+
+func (s *decodeState) leave() {
+	if s == nil {
+		return
+	}
+	s.depth--
+}
+
+func (s *decodeState) checkLength(l uint64) error {
+	if s == nil || s.limits.MaxLength <= 0 {
+		return nil
+	}
+	if l > uint64(s.limits.MaxLength) {
+		return ErrLimitExceeded
+	}
+	return nil
+}
+
+func (s *decodeState) checkElements(n uint64) error {
+	if s == nil || s.limits.MaxElements <= 0 {
+		return nil
+	}
+	if n > uint64(s.limits.MaxElements) {
+		return ErrLimitExceeded
+	}
+	return nil
+}
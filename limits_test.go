@@ -0,0 +1,43 @@
+package encode
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLengthDelim(t *testing.T) {
+	b := []byte("hello")
+	s := "world"
+	buf := New(LengthDelimBytes(&b), LengthDelimString(&s)).Encode()
+	require.Equal(t, []byte("\x05hello\x05world"), buf)
+
+	var b2 []byte
+	var s2 string
+	require.NoError(t, New(LengthDelimBytes(&b2), LengthDelimString(&s2)).Decode(buf))
+	require.Equal(t, b, b2)
+	require.Equal(t, s, s2)
+}
+
+func TestDecodeLimited(t *testing.T) {
+	big := bytes.Repeat([]byte{'a'}, 1000)
+	buf := New(LengthDelimBytes(&big)).Encode()
+
+	var b []byte
+	err := New(LengthDelimBytes(&b)).DecodeLimited(buf, Limits{MaxLength: 999})
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	require.NoError(t, New(LengthDelimBytes(&b)).DecodeLimited(buf, Limits{MaxLength: 1000}))
+	require.Equal(t, big, b)
+
+	buf = New(Compressed(LengthDelimBytes(&big), Flate(9))).Encode()
+	require.Less(t, len(buf), 100)
+	err = New(Compressed(LengthDelimBytes(&b), Flate(9))).DecodeLimited(buf, Limits{MaxLength: 500})
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	x := true
+	nested := Group(Group(Group(Bool(&x))))
+	buf = New(nested).Encode()
+	require.ErrorIs(t, New(nested).DecodeLimited(buf, Limits{MaxDepth: 2}), ErrLimitExceeded)
+	require.NoError(t, New(nested).DecodeLimited(buf, Limits{MaxDepth: 3}))
+}
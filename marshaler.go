@@ -0,0 +1,241 @@
+package encode
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"reflect"
+)
+
+// Implemented by types that can be embedded with Marshaler, for example *time.Time.
+type BinaryMarshalUnmarshaler interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Encode v's MarshalBinary as a uvarint of its length, followed by the marshaled bytes. Decode
+// hands the bytes to v's UnmarshalBinary.
+//
+// Encode and Size panic if MarshalBinary returns an error, since they can't return one. If v can
+// fail to marshal, check by calling v.MarshalBinary() before encoding.
+func Marshaler(v BinaryMarshalUnmarshaler) Item {
+	return marshaler{v: v, state: &marshalState{}}
+}
+
+type marshaler struct {
+	v     BinaryMarshalUnmarshaler
+	state *marshalState
+}
+
+// Remembers the bytes that an item was last decoded from, so that Size and Encode reproduce them
+// for as long as the value still marshals the same as just after decoding. Otherwise, a value
+// decoded from text that isn't the form it marshals to, like "+5" for a big.Int, would report a
+// different size than it took up in the buffer, misaligning the items after it.
+type marshalState struct {
+	valid bool
+	// What the value marshaled to just after it was decoded.
+	marshaled []byte
+	// The bytes it was decoded from.
+	decoded []byte
+}
+
+func (s *marshalState) set(decoded []byte, marshaled []byte, err error) {
+	s.valid = err == nil
+	s.marshaled = marshaled
+	s.decoded = append([]byte(nil), decoded...)
+}
+
+// Returns the bytes to write for a value that currently marshals to b.
+func (s *marshalState) wire(b []byte) []byte {
+	if s.valid && bytes.Equal(b, s.marshaled) {
+		return s.decoded
+	}
+	s.valid = false
+	return b
+}
+
+func (e marshaler) marshal() []byte {
+	b, err := e.v.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return e.state.wire(b)
+}
+func (e marshaler) Encode(buf []byte) {
+	b := e.marshal()
+	n := binary.PutUvarint(buf, uint64(len(b)))
+	copy(buf[n:], b)
+}
+func (e marshaler) Size() int {
+	b := e.marshal()
+	return uvarintSize(uint64(len(b))) + len(b)
+}
+func (e marshaler) kind() string {
+	return "Marshaler"
+}
+func (e marshaler) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e marshaler) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e marshaler) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e marshaler) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
+	}
+	err = e.v.UnmarshalBinary(b)
+	if err != nil {
+		return err
+	}
+	marshaled, err := e.v.MarshalBinary()
+	e.state.set(b, marshaled, err)
+	return nil
+}
+
+// Implemented by types that can be embedded with TextMarshaler.
+type TextMarshalUnmarshaler interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+// Encode v's MarshalText as a uvarint of its length, followed by the text. Decode hands the text
+// to v's UnmarshalText. This is for types that only offer a text form, like *big.Int; use
+// Marshaler for types that also offer a binary form, since it's usually more compact.
+//
+// Encode and Size panic if MarshalText returns an error, since they can't return one.
+func TextMarshaler(v TextMarshalUnmarshaler) Item {
+	return textMarshaler{v: v, state: &marshalState{}}
+}
+
+type textMarshaler struct {
+	v     TextMarshalUnmarshaler
+	state *marshalState
+}
+
+func (e textMarshaler) marshal() []byte {
+	b, err := e.v.MarshalText()
+	if err != nil {
+		panic(err)
+	}
+	return e.state.wire(b)
+}
+func (e textMarshaler) Encode(buf []byte) {
+	b := e.marshal()
+	n := binary.PutUvarint(buf, uint64(len(b)))
+	copy(buf[n:], b)
+}
+func (e textMarshaler) Size() int {
+	b := e.marshal()
+	return uvarintSize(uint64(len(b))) + len(b)
+}
+func (e textMarshaler) kind() string {
+	return "TextMarshaler"
+}
+func (e textMarshaler) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e textMarshaler) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e textMarshaler) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e textMarshaler) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
+	}
+	err = e.v.UnmarshalText(b)
+	if err != nil {
+		return err
+	}
+	marshaled, err := e.v.MarshalText()
+	e.state.set(b, marshaled, err)
+	return nil
+}
+
+// Encode v with encoding/gob, as a uvarint of the gob's length followed by the gob itself. v must
+// be a pointer so that it can be decoded into. This is for fields where a quick deep encoding is
+// more important than a compact one: each gob carries a description of v's type, so it's much
+// larger than encoding the same fields with this package.
+//
+// Decode replaces *v entirely, unlike gob.Decoder, which leaves alone fields the gob doesn't
+// mention. Encode and Size panic if gob can't encode v.
+func Gob(v interface{}) Item {
+	return gobItem{v: v, state: &gobState{}}
+}
+
+type gobItem struct {
+	v     interface{}
+	state *gobState
+}
+
+// Like marshalState, but remembering a copy of the decoded value rather than what it gobs to, since
+// gobbing the same value twice can give different bytes, for example by ordering a map differently.
+type gobState struct {
+	// nil if there's nothing remembered.
+	value   interface{}
+	decoded []byte
+}
+
+func (e gobItem) marshal() []byte {
+	if e.state.value != nil {
+		if reflect.DeepEqual(reflect.ValueOf(e.v).Elem().Interface(), e.state.value) {
+			return e.state.decoded
+		}
+		e.state.value = nil
+	}
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(e.v)
+	if err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+func (e gobItem) Encode(buf []byte) {
+	b := e.marshal()
+	n := binary.PutUvarint(buf, uint64(len(b)))
+	copy(buf[n:], b)
+}
+func (e gobItem) Size() int {
+	b := e.marshal()
+	return uvarintSize(uint64(len(b))) + len(b)
+}
+func (e gobItem) kind() string {
+	return "Gob"
+}
+func (e gobItem) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e gobItem) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e gobItem) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e gobItem) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
+	}
+	// gob leaves alone the fields that b doesn't mention, but *e.v should be exactly what b holds.
+	reflect.ValueOf(e.v).Elem().SetZero()
+	err = gob.NewDecoder(bytes.NewReader(b)).Decode(e.v)
+	if err != nil {
+		return err
+	}
+	// Decoded again to get a copy that later changes to e.v don't show up in.
+	value := reflect.New(reflect.TypeOf(e.v).Elem())
+	err = gob.NewDecoder(bytes.NewReader(b)).Decode(value.Interface())
+	if err != nil {
+		return err
+	}
+	e.state.value = value.Elem().Interface()
+	e.state.decoded = append([]byte(nil), b...)
+	return nil
+}
@@ -0,0 +1,121 @@
+package encode
+
+import (
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshaler(t *testing.T) {
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	id := uint16(3)
+	buf := New(Marshaler(&ts), FixedUint16(&id)).Encode()
+
+	var ts2 time.Time
+	var id2 uint16
+	require.NoError(t, New(Marshaler(&ts2), FixedUint16(&id2)).Decode(buf))
+	require.True(t, ts.Equal(ts2))
+	require.Equal(t, id, id2)
+}
+
+// Accepts leading zero bytes, but marshals without them.
+type paddedByte byte
+
+func (b paddedByte) MarshalBinary() ([]byte, error) {
+	return []byte{byte(b)}, nil
+}
+func (b *paddedByte) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return io.ErrUnexpectedEOF
+	}
+	*b = paddedByte(data[len(data)-1])
+	return nil
+}
+
+func TestMarshalerNonCanonical(t *testing.T) {
+	var x paddedByte
+	var id uint16
+	enc := New(Marshaler(&x), FixedUint16(&id))
+	buf := []byte{3, 0, 0, 5, 0x01, 0x02}
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, paddedByte(5), x)
+	require.Equal(t, uint16(0x0102), id)
+	require.Equal(t, buf, enc.Encode())
+
+	x = 6
+	require.Equal(t, []byte{1, 6, 0x01, 0x02}, enc.Encode())
+}
+
+func TestTextMarshaler(t *testing.T) {
+	x, ok := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.True(t, ok)
+	buf := New(TextMarshaler(x)).Encode()
+	require.Equal(t, append([]byte{30}, "123456789012345678901234567890"...), buf)
+
+	x2 := new(big.Int)
+	require.NoError(t, New(TextMarshaler(x2)).Decode(buf))
+	require.Equal(t, 0, x.Cmp(x2))
+}
+
+func TestGob(t *testing.T) {
+	type doc struct {
+		Name  string
+		Tags  map[string][]int
+		Inner *doc
+	}
+	d := doc{Name: "a", Tags: map[string][]int{"x": {1, 2}}, Inner: &doc{Name: "b"}}
+	id := uint16(9)
+	buf := New(FixedUint16(&id), Gob(&d)).Encode()
+
+	var d2 doc
+	var id2 uint16
+	require.NoError(t, New(FixedUint16(&id2), Gob(&d2)).Decode(buf))
+	require.Equal(t, d, d2)
+	require.Equal(t, id, id2)
+}
+
+func TestTextMarshalerNonCanonical(t *testing.T) {
+	x := new(big.Int)
+	var id uint16
+	enc := New(TextMarshaler(x), FixedUint16(&id))
+	buf := []byte("\x02+5\x01\x02")
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, int64(5), x.Int64())
+	require.Equal(t, uint16(0x0102), id)
+	require.Equal(t, buf, enc.Encode())
+
+	x.SetInt64(6)
+	require.Equal(t, []byte("\x016\x01\x02"), enc.Encode())
+}
+
+func TestGobNonCanonical(t *testing.T) {
+	type doc struct {
+		Name string
+		Tags map[string]int
+	}
+	// Written by a version of doc with another field, which decoding leaves out.
+	type wideDoc struct {
+		Name  string
+		Extra string
+		Tags  map[string]int
+	}
+	wide := wideDoc{Name: "a", Extra: "dropped", Tags: map[string]int{"x": 1, "y": 2, "z": 3}}
+	id := uint16(0x0102)
+	buf := New(Gob(&wide), FixedUint16(&id)).Encode()
+
+	d := doc{Name: "leftover", Tags: map[string]int{"w": 4}}
+	var id2 uint16
+	enc := New(Gob(&d), FixedUint16(&id2))
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, doc{Name: "a", Tags: map[string]int{"x": 1, "y": 2, "z": 3}}, d)
+	require.Equal(t, uint16(0x0102), id2)
+	require.Equal(t, buf, enc.Encode())
+
+	d.Name = "b"
+	require.NoError(t, enc.Decode(enc.Encode()))
+	require.Equal(t, "b", d.Name)
+	require.Equal(t, uint16(0x0102), id2)
+}
@@ -0,0 +1,268 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"math"
+)
+
+var ErrMerkleMismatch = NewInvalidValueError("encode: merkle blob hash mismatch")
+
+// Encode v split into chunks of chunkSize bytes, the last of which may be shorter, along with a
+// hash of each chunk and a root hash over all of them, so that each chunk can be verified on its
+// own with ParseMerkleBlob. This suits content-addressed storage, where the root hash names the
+// blob, and resumable transfers, where chunks that arrived intact needn't be fetched again.
+//
+// Written as a uvarint of the length of v, the root hash, the hash of each chunk in order, and then
+// v itself. Hashes are computed with newHash, for example sha256.New. The root is a Merkle tree
+// over the chunks as in RFC 6962: each chunk's hash is H(0x00 || chunk), and each interior node is
+// H(0x01 || left || right), with the left subtree holding the largest power of two chunks less
+// than the number of chunks below the node.
+//
+// Decode verifies every chunk, and returns ErrMerkleMismatch if any hash doesn't match. Panics if
+// chunkSize is not positive.
+func MerkleBlob(v *[]byte, chunkSize int, newHash func() hash.Hash) Item {
+	if chunkSize <= 0 {
+		panic(fmt.Sprintf("invalid chunkSize=%d, must be positive", chunkSize))
+	}
+	return merkleBlob{v: v, chunkSize: chunkSize, newHash: newHash}
+}
+
+type merkleBlob struct {
+	v         *[]byte
+	chunkSize int
+	newHash   func() hash.Hash
+}
+
+func numChunks(l int, chunkSize int) int {
+	return (l + chunkSize - 1) / chunkSize
+}
+
+func (e merkleBlob) Encode(buf []byte) {
+	v := *e.v
+	h := e.newHash()
+	hashSize := h.Size()
+	i := binary.PutUvarint(buf, uint64(len(v)))
+	root := buf[i : i+hashSize]
+	i += hashSize
+	n := numChunks(len(v), e.chunkSize)
+	hashes := buf[i : i+n*hashSize]
+	i += len(hashes)
+	var scratch [64]byte
+	for j := 0; j < n; j++ {
+		chunk := v[j*e.chunkSize : minInt((j+1)*e.chunkSize, len(v))]
+		copy(hashes[j*hashSize:], merkleLeafHash(h, scratch[:0], chunk))
+	}
+	copy(root, merkleRoot(h, hashes))
+	copy(buf[i:], v)
+}
+func (e merkleBlob) Size() int {
+	l := len(*e.v)
+	hashSize := e.newHash().Size()
+	return uvarintSize(uint64(l)) + hashSize*(1+numChunks(l, e.chunkSize)) + l
+}
+func (e merkleBlob) kind() string {
+	return "MerkleBlob"
+}
+func (e merkleBlob) sizeBounds() (min, max int) {
+	return 1 + e.newHash().Size(), -1
+}
+func (e merkleBlob) skip(buf []byte) (int, error) {
+	view, err := parseMerkleBlob(buf, e.chunkSize, e.newHash, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(view.data) < view.length {
+		return 0, ErrShortBuffer
+	}
+	return view.size(), nil
+}
+func (e merkleBlob) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e merkleBlob) decodeLimited(buf []byte, s *decodeState) error {
+	view, err := parseMerkleBlob(buf, e.chunkSize, e.newHash, s)
+	if err != nil {
+		return err
+	}
+	if len(view.data) < view.length {
+		return ErrShortBuffer
+	}
+	err = view.checkRoot()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < view.NumChunks(); i++ {
+		_, err := view.Chunk(i)
+		if err != nil {
+			return err
+		}
+	}
+	*e.v = s.bytes(view.length)
+	copy(*e.v, view.data)
+	return nil
+}
+
+// A MerkleBlobView is a blob written by MerkleBlob, for verifying and reading its chunks one at a
+// time. It refers to the buffer it was parsed from rather than copying it.
+type MerkleBlobView struct {
+	newHash   func() hash.Hash
+	chunkSize int
+	length    int
+	root      []byte
+	hashes    []byte
+	// As much of the blob's contents as is in the buffer, which may be less than length.
+	data []byte
+}
+
+// ParseMerkleBlob parses the header of a blob written by MerkleBlob with the same chunkSize and
+// newHash from the start of buf, and checks the chunk hashes against the root hash, returning
+// ErrMerkleMismatch if they don't match. buf only needs to hold the header and hashes, not the
+// contents, so that chunks can be verified as they arrive. Compare Root against a trusted root
+// hash to know the blob is the expected one.
+func ParseMerkleBlob(buf []byte, chunkSize int, newHash func() hash.Hash) (MerkleBlobView, error) {
+	if chunkSize <= 0 {
+		panic(fmt.Sprintf("invalid chunkSize=%d, must be positive", chunkSize))
+	}
+	view, err := parseMerkleBlob(buf, chunkSize, newHash, nil)
+	if err != nil {
+		return MerkleBlobView{}, err
+	}
+	err = view.checkRoot()
+	if err != nil {
+		return MerkleBlobView{}, err
+	}
+	return view, nil
+}
+
+// Parses everything but the contents, without verifying anything.
+func parseMerkleBlob(
+	buf []byte,
+	chunkSize int,
+	newHash func() hash.Hash,
+	s *decodeState,
+) (MerkleBlobView, error) {
+	l, i := binary.Uvarint(buf)
+	if i == 0 {
+		return MerkleBlobView{}, ErrShortBuffer
+	}
+	if i < 0 {
+		return MerkleBlobView{}, ErrVarintOverflow
+	}
+	err := s.checkLength(l)
+	if err != nil {
+		return MerkleBlobView{}, err
+	}
+	if l > math.MaxInt {
+		return MerkleBlobView{}, ErrShortBuffer
+	}
+	// The contents needn't be in buf, but every chunk's hash must be, which bounds the number of
+	// chunks without overflowing.
+	hashSize := newHash().Size()
+	n := l / uint64(chunkSize)
+	if l%uint64(chunkSize) != 0 {
+		n++
+	}
+	if uint64(len(buf[i:]))/uint64(hashSize) < 1+n {
+		return MerkleBlobView{}, ErrShortBuffer
+	}
+	headerSize := i + hashSize*(1+int(n))
+	data := buf[headerSize:]
+	if len(data) > int(l) {
+		data = data[:l]
+	}
+	return MerkleBlobView{
+		newHash:   newHash,
+		chunkSize: chunkSize,
+		length:    int(l),
+		root:      buf[i : i+hashSize],
+		hashes:    buf[i+hashSize : headerSize],
+		data:      data,
+	}, nil
+}
+
+func (v MerkleBlobView) checkRoot() error {
+	if !bytes.Equal(merkleRoot(v.newHash(), v.hashes), v.root) {
+		return ErrMerkleMismatch
+	}
+	return nil
+}
+
+func (v MerkleBlobView) size() int {
+	return len(v.root) + len(v.hashes) + uvarintSize(uint64(v.length)) + v.length
+}
+
+// Root returns the root hash of the blob.
+func (v MerkleBlobView) Root() []byte {
+	return v.root
+}
+
+// Len returns the length of the blob's contents in bytes.
+func (v MerkleBlobView) Len() int {
+	return v.length
+}
+
+// NumChunks returns the number of chunks in the blob.
+func (v MerkleBlobView) NumChunks() int {
+	return len(v.hashes) / len(v.root)
+}
+
+// ChunkRange returns the offsets in the blob's contents of the start and end of the i'th chunk.
+func (v MerkleBlobView) ChunkRange(i int) (start int, end int) {
+	if i < 0 || i >= v.NumChunks() {
+		panic(fmt.Sprintf("invalid i=%d, must be in [0, %d)", i, v.NumChunks()))
+	}
+	return i * v.chunkSize, minInt((i+1)*v.chunkSize, v.length)
+}
+
+// Chunk returns the i'th chunk of the blob's contents after checking it against its hash,
+// returning ErrMerkleMismatch if it doesn't match, or ErrShortBuffer if the buffer the view was
+// parsed from ends before the end of the chunk. The result aliases that buffer.
+func (v MerkleBlobView) Chunk(i int) ([]byte, error) {
+	start, end := v.ChunkRange(i)
+	if len(v.data) < end {
+		return nil, ErrShortBuffer
+	}
+	chunk := v.data[start:end:end]
+	hashSize := len(v.root)
+	var scratch [64]byte
+	actual := merkleLeafHash(v.newHash(), scratch[:0], chunk)
+	if !bytes.Equal(actual, v.hashes[i*hashSize:(i+1)*hashSize]) {
+		return nil, ErrMerkleMismatch
+	}
+	return chunk, nil
+}
+
+// Appends H(0x00 || chunk) to dst.
+func merkleLeafHash(h hash.Hash, dst []byte, chunk []byte) []byte {
+	h.Reset()
+	h.Write([]byte{0x00})
+	h.Write(chunk)
+	return h.Sum(dst)
+}
+
+// Returns the root of the tree over the given leaf hashes, concatenated.
+func merkleRoot(h hash.Hash, hashes []byte) []byte {
+	hashSize := h.Size()
+	n := len(hashes) / hashSize
+	switch n {
+	case 0:
+		h.Reset()
+		return h.Sum(nil)
+	case 1:
+		return append([]byte(nil), hashes...)
+	}
+	split := 1
+	for split*2 < n {
+		split *= 2
+	}
+	left := merkleRoot(h, hashes[:split*hashSize])
+	right := merkleRoot(h, hashes[split*hashSize:])
+	h.Reset()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
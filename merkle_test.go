@@ -0,0 +1,89 @@
+package encode
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleBlob(t *testing.T) {
+	var v []byte
+	var b bool
+	enc := New(MerkleBlob(&v, 4, sha256.New), Bool(&b))
+
+	for _, l := range []int{0, 1, 4, 5, 12, 13, 100} {
+		v = make([]byte, l)
+		for i := range v {
+			v[i] = byte(i)
+		}
+		expected := append([]byte(nil), v...)
+		b = true
+		buf := enc.Encode()
+		require.Len(t, buf, enc.EncodedSize())
+
+		v, b = nil, false
+		require.NoError(t, enc.Decode(buf))
+		require.Equal(t, expected, append([]byte{}, v...))
+		require.True(t, b)
+
+		view, err := ParseMerkleBlob(buf, 4, sha256.New)
+		require.NoError(t, err)
+		require.Equal(t, l, view.Len())
+		require.Equal(t, (l+3)/4, view.NumChunks())
+		for i := 0; i < view.NumChunks(); i++ {
+			chunk, err := view.Chunk(i)
+			require.NoError(t, err)
+			start, end := view.ChunkRange(i)
+			require.Equal(t, expected[start:end], chunk)
+		}
+	}
+
+	// The root is RFC 6962's Merkle tree hash.
+	v = []byte("abcdef")
+	buf := enc.Encode()
+	leaf := func(b string) []byte {
+		h := sha256.Sum256(append([]byte{0x00}, b...))
+		return h[:]
+	}
+	root := sha256.Sum256(append(append([]byte{0x01}, leaf("abcd")...), leaf("ef")...))
+	view, err := ParseMerkleBlob(buf, 4, sha256.New)
+	require.NoError(t, err)
+	require.Equal(t, root[:], view.Root())
+
+	// A corrupt chunk only fails that chunk.
+	buf[len(buf)-2] ^= 0xFF
+	require.ErrorIs(t, enc.Decode(buf), ErrMerkleMismatch)
+	view, err = ParseMerkleBlob(buf, 4, sha256.New)
+	require.NoError(t, err)
+	_, err = view.Chunk(0)
+	require.NoError(t, err)
+	_, err = view.Chunk(1)
+	require.ErrorIs(t, err, ErrMerkleMismatch)
+	buf[len(buf)-2] ^= 0xFF
+
+	// Chunks can be verified before the rest arrives.
+	view, err = ParseMerkleBlob(buf[:len(buf)-3], 4, sha256.New)
+	require.NoError(t, err)
+	_, err = view.Chunk(0)
+	require.NoError(t, err)
+	_, err = view.Chunk(1)
+	require.ErrorIs(t, err, ErrShortBuffer)
+
+	// Or before any of them have.
+	view, err = ParseMerkleBlob(buf[:1+3*32], 4, sha256.New)
+	require.NoError(t, err)
+	require.Equal(t, 6, view.Len())
+	_, err = view.Chunk(0)
+	require.ErrorIs(t, err, ErrShortBuffer)
+	_, err = ParseMerkleBlob(buf[:3*32], 4, sha256.New)
+	require.ErrorIs(t, err, ErrShortBuffer)
+
+	// A corrupt chunk hash fails against the root.
+	buf[1+32] ^= 0xFF
+	_, err = ParseMerkleBlob(buf, 4, sha256.New)
+	require.ErrorIs(t, err, ErrMerkleMismatch)
+	require.ErrorIs(t, enc.Decode(buf), ErrMerkleMismatch)
+
+	require.ErrorIs(t, enc.DecodeLimited(enc.Encode(), Limits{MaxLength: 5}), ErrLimitExceeded)
+}
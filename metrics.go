@@ -0,0 +1,40 @@
+package encode
+
+// Metrics collects the sizes of named fields (see Named) as they're encoded and decoded, for
+// example to find which fields take up the most space in storage. Implementations must be safe for
+// concurrent use if the Encodings they're given to are used concurrently.
+type Metrics interface {
+	// Called each time the field called name is encoded, with its size in bytes.
+	Encoded(name string, n int)
+	// Called each time the field called name is decoded, with its size in bytes.
+	Decoded(name string, n int)
+}
+
+// WithMetrics returns an Encoding that encodes and decodes the same as enc, but reports the size
+// of each item made with Named to m. Named items inside a Group or another Named item are reported
+// too, with their names joined to the enclosing ones by a dot, like "header.length". Named items
+// inside other kinds of items aren't reported.
+func (enc Encoding) WithMetrics(m Metrics) Encoding {
+	return Encoding{items: itemsWithMetrics(enc.items, m, "")}
+}
+
+func itemsWithMetrics(items []Item, m Metrics, prefix string) []Item {
+	result := make([]Item, len(items))
+	for i, item := range items {
+		result[i] = withMetrics(item, m, prefix)
+	}
+	return result
+}
+
+func withMetrics(item Item, m Metrics, prefix string) Item {
+	switch item := item.(type) {
+	case named:
+		item.metrics = m
+		item.path = prefix + item.name
+		item.item = withMetrics(item.item, m, item.path+".")
+		return item
+	case group:
+		return Group(itemsWithMetrics(item.items, m, prefix)...)
+	}
+	return item
+}
@@ -0,0 +1,36 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sizeMetrics struct {
+	encoded map[string]int
+	decoded map[string]int
+}
+
+func (m *sizeMetrics) Encoded(name string, n int) { m.encoded[name] += n }
+func (m *sizeMetrics) Decoded(name string, n int) { m.decoded[name] += n }
+
+func TestWithMetrics(t *testing.T) {
+	var id uint64
+	var length uint16
+	var body []byte
+	var other bool
+	m := &sizeMetrics{encoded: map[string]int{}, decoded: map[string]int{}}
+	enc := New(
+		Named("id", Uvarint64(&id)),
+		Named("header", Group(Named("length", FixedUint16(&length)), Bool(&other))),
+		Group(Named("body", LengthDelimBytes(&body))),
+	).WithMetrics(m)
+
+	id, length, body = 300, 5, []byte("hello")
+	buf := enc.Encode()
+	require.NoError(t, enc.Decode(buf))
+	require.NoError(t, enc.Decode(buf))
+
+	require.Equal(t, map[string]int{"id": 2, "header": 3, "header.length": 2, "body": 6}, m.encoded)
+	require.Equal(t, map[string]int{"id": 4, "header": 6, "header.length": 4, "body": 12}, m.decoded)
+}
@@ -0,0 +1,70 @@
+//go:build unix
+
+package encode
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Decoding from a read-only mapping faults if Decode ever writes to its input.
+func TestDecodeMmap(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, 16))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	var (
+		id      uint64
+		name    string
+		payload []byte
+		view    []byte
+		secret  string
+		flag    bool
+	)
+	items := func() []Item {
+		return []Item{
+			FixedUint64(&id),
+			LengthDelimString(&name),
+			Compressed(LengthDelimBytes(&payload), Flate(1)),
+			BytesView(&view),
+			Sealed(UnsafeStringView(&secret), aead),
+			Group(Bool(&flag), Align(8)),
+		}
+	}
+	id, name, payload, view, secret, flag = 7, "name", bytes.Repeat([]byte("x"), 1000),
+		[]byte("view"), "secret", true
+	encoded := New(items()...).Encode()
+
+	path := filepath.Join(t.TempDir(), "mapped")
+	require.NoError(t, os.WriteFile(path, encoded, 0o600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	mapped, err := syscall.Mmap(int(f.Fd()), 0, len(encoded), syscall.PROT_READ, syscall.MAP_SHARED)
+	require.NoError(t, err)
+	defer syscall.Munmap(mapped)
+
+	// Turn a write to the mapping into a test failure rather than a crash.
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+
+	id, name, payload, view, secret, flag = 0, "", nil, nil, "", false
+	require.NoError(t, New(items()...).Decode(mapped))
+	require.Equal(t, uint64(7), id)
+	require.Equal(t, "name", name)
+	require.Len(t, payload, 1000)
+	require.Equal(t, []byte("view"), view)
+	require.Equal(t, "secret", secret)
+	require.True(t, flag)
+
+	// view refers to the mapping rather than a copy of it.
+	require.Equal(t, &mapped[bytes.Index(encoded, []byte("view"))], &view[0])
+}
@@ -0,0 +1,81 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var ErrInvalidCurrency = NewInvalidValueError("encode: invalid currency code")
+
+// Encode an amount of money as its ISO 4217 currency code in 3 bytes, like "USD", followed by the
+// amount in the currency's minor units, like cents, as a zigzag varint so that small negative
+// amounts like refunds stay small:
+//
+//   "USD", -1999
+//   55 53 44 9d 1f
+//
+// Panics on encode if currency isn't 3 uppercase ASCII letters. Decode returns ErrInvalidCurrency
+// in the same case.
+func Money(currency *string, minorUnits *int64) Item {
+	return money{currency: currency, minorUnits: minorUnits}
+}
+
+type money struct {
+	currency   *string
+	minorUnits *int64
+}
+
+func (e money) Encode(buf []byte) {
+	if !validCurrency(*e.currency) {
+		panic(fmt.Sprintf("invalid currency code %q, must be 3 uppercase letters", *e.currency))
+	}
+	copy(buf, *e.currency)
+	binary.PutVarint(buf[3:], *e.minorUnits)
+}
+func (e money) Size() int {
+	return 3 + varintSize(*e.minorUnits)
+}
+func (e money) kind() string {
+	return "Money"
+}
+func (e money) sizeBounds() (min, max int) {
+	return 4, 3 + binary.MaxVarintLen64
+}
+func (e money) skip(buf []byte) (int, error) {
+	if len(buf) < 3 {
+		return 0, ErrShortBuffer
+	}
+	n, err := skipUvarint(buf[3:])
+	return 3 + n, err
+}
+func (e money) Decode(buf []byte) error {
+	if len(buf) < 3 {
+		return ErrShortBuffer
+	}
+	currency := string(buf[:3])
+	if !validCurrency(currency) {
+		return ErrInvalidCurrency
+	}
+	x, n := binary.Varint(buf[3:])
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	*e.currency = currency
+	*e.minorUnits = x
+	return nil
+}
+
+func validCurrency(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
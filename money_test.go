@@ -0,0 +1,29 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoney(t *testing.T) {
+	currency := "USD"
+	units := int64(-1999)
+	enc := New(Money(&currency, &units))
+	buf := enc.Encode()
+	require.Equal(t, []byte{'U', 'S', 'D', 0x9d, 0x1f}, buf)
+
+	var currency2 string
+	var units2 int64
+	enc2 := New(Money(&currency2, &units2))
+	require.NoError(t, enc2.Decode(buf))
+	require.Equal(t, currency, currency2)
+	require.Equal(t, units, units2)
+
+	require.ErrorIs(t, enc2.Decode([]byte{'U', 'S', 'D'}), io.ErrUnexpectedEOF)
+	require.ErrorIs(t, enc2.Decode([]byte{'u', 's', 'd', 0x00}), ErrInvalidCurrency)
+
+	currency = "US"
+	require.Panics(t, func() { enc.Encode() })
+}
@@ -0,0 +1,223 @@
+// Package mqtt provides items that read and write the primitive data types of MQTT v5, so that
+// MQTT packets can be built and parsed with encodings built with github.com/bradenaw/encode. For
+// example, a PUBLISH packet with QoS 0 and no properties is
+//
+//   encode.New(mqtt.Packet(
+//   	&header,
+//   	mqtt.String(&topic),
+//   	mqtt.VarInt(&propertiesLength),
+//   	encode.Remainder(&payload),
+//   ))
+//
+// Decode rejects anything the specification calls malformed, like a Variable Byte Integer written
+// in more bytes than necessary or a string that isn't valid UTF-8, with ErrMalformed.
+//
+// See https://docs.oasis-open.org/mqtt/mqtt/v5.0/mqtt-v5.0.html for the format.
+package mqtt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrMalformed = encode.NewInvalidValueError("mqtt: malformed packet")
+
+// The largest value a Variable Byte Integer can hold.
+const MaxVarInt = 268435455
+
+// The longest String or Binary, in bytes.
+const MaxLength = 65535
+
+// Encode v as a Variable Byte Integer, which takes 1 to 4 bytes: seven bits of v per byte, least
+// significant first, with the high bit set on every byte but the last. Panics on encode if v is
+// greater than MaxVarInt.
+func VarInt(v *uint32) encode.Item {
+	return varInt{v}
+}
+
+type varInt struct{ v *uint32 }
+
+func (e varInt) Encode(buf []byte) {
+	x := *e.v
+	if x > MaxVarInt {
+		panic(fmt.Sprintf("mqtt: %d is too large for a Variable Byte Integer", x))
+	}
+	i := 0
+	for ; x >= 0x80; i++ {
+		buf[i] = byte(x) | 0x80
+		x >>= 7
+	}
+	buf[i] = byte(x)
+}
+func (e varInt) Size() int {
+	return varIntSize(*e.v)
+}
+func (e varInt) Decode(buf []byte) error {
+	x, _, err := readVarInt(buf)
+	if err != nil {
+		return err
+	}
+	*e.v = x
+	return nil
+}
+
+func varIntSize(x uint32) int {
+	n := 1
+	for ; x >= 0x80; x >>= 7 {
+		n++
+	}
+	return n
+}
+
+func readVarInt(buf []byte) (uint32, int, error) {
+	x := uint32(0)
+	for i := 0; i < 4; i++ {
+		if i == len(buf) {
+			return 0, 0, encode.ErrShortBuffer
+		}
+		b := buf[i]
+		x |= uint32(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			if i > 0 && b == 0 {
+				// Written in more bytes than necessary.
+				return 0, 0, ErrMalformed
+			}
+			return x, i + 1, nil
+		}
+	}
+	return 0, 0, ErrMalformed
+}
+
+// Encode v as a UTF-8 Encoded String: its length in bytes as a big endian uint16, then v. Panics on
+// encode if v is longer than MaxLength, isn't valid UTF-8, or contains U+0000, since the
+// specification doesn't allow any of them.
+func String(v *string) encode.Item {
+	return stringItem{v}
+}
+
+// Encode k and then v as a UTF-8 String Pair, as used by User Property.
+func StringPair(k *string, v *string) encode.Item {
+	return encode.Group(String(k), String(v))
+}
+
+type stringItem struct{ v *string }
+
+func (e stringItem) Encode(buf []byte) {
+	if !validString(*e.v) {
+		panic("mqtt: strings must be valid UTF-8 without U+0000")
+	}
+	putLength(buf, len(*e.v))
+	copy(buf[2:], *e.v)
+}
+func (e stringItem) Size() int {
+	return 2 + len(*e.v)
+}
+func (e stringItem) Decode(buf []byte) error {
+	b, err := readBinary(buf)
+	if err != nil {
+		return err
+	}
+	s := string(b)
+	if !validString(s) {
+		return ErrMalformed
+	}
+	*e.v = s
+	return nil
+}
+
+func validString(s string) bool {
+	return utf8.ValidString(s) && !strings.ContainsRune(s, 0)
+}
+
+// Encode v as Binary Data: its length as a big endian uint16, then v. Panics on encode if v is
+// longer than MaxLength.
+func Binary(v *[]byte) encode.Item {
+	return binaryItem{v}
+}
+
+type binaryItem struct{ v *[]byte }
+
+func (e binaryItem) Encode(buf []byte) {
+	putLength(buf, len(*e.v))
+	copy(buf[2:], *e.v)
+}
+func (e binaryItem) Size() int {
+	return 2 + len(*e.v)
+}
+func (e binaryItem) Decode(buf []byte) error {
+	b, err := readBinary(buf)
+	if err != nil {
+		return err
+	}
+	*e.v = append((*e.v)[:0], b...)
+	return nil
+}
+
+func putLength(buf []byte, l int) {
+	if l > MaxLength {
+		panic(fmt.Sprintf("mqtt: length %d is longer than %d", l, MaxLength))
+	}
+	binary.BigEndian.PutUint16(buf, uint16(l))
+}
+
+func readBinary(buf []byte) ([]byte, error) {
+	if len(buf) < 2 {
+		return nil, encode.ErrShortBuffer
+	}
+	l := int(binary.BigEndian.Uint16(buf))
+	if len(buf[2:]) < l {
+		return nil, encode.ErrShortBuffer
+	}
+	return buf[2 : 2+l], nil
+}
+
+// Encode a control packet: the fixed header, made of the byte *header holding the packet type and
+// flags and then the length of the rest of the packet as a Variable Byte Integer, followed by
+// items. On decode, items are given only the bytes of this packet.
+func Packet(header *byte, items ...encode.Item) encode.Item {
+	return packet{header: header, body: encode.Group(items...)}
+}
+
+type packet struct {
+	header *byte
+	body   encode.Item
+}
+
+func (e packet) Encode(buf []byte) {
+	buf[0] = *e.header
+	size := uint32(e.body.Size())
+	n := 1 + varIntSize(size)
+	varInt{&size}.Encode(buf[1:n])
+	e.body.Encode(buf[n:])
+}
+func (e packet) Size() int {
+	size := e.body.Size()
+	return 1 + varIntSize(uint32(size)) + size
+}
+func (e packet) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return encode.ErrShortBuffer
+	}
+	l, n, err := readVarInt(buf[1:])
+	if err != nil {
+		return err
+	}
+	start := 1 + n
+	if uint64(len(buf[start:])) < uint64(l) {
+		return encode.ErrShortBuffer
+	}
+	body := buf[start : start+int(l)]
+	err = e.body.Decode(body)
+	if err != nil {
+		return err
+	}
+	if e.body.Size() != len(body) {
+		return ErrMalformed
+	}
+	*e.header = buf[0]
+	return nil
+}
@@ -0,0 +1,86 @@
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarInt(t *testing.T) {
+	// The examples from the specification.
+	for _, tc := range []struct {
+		x        uint32
+		expected []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7F}},
+		{128, []byte{0x80, 0x01}},
+		{16383, []byte{0xFF, 0x7F}},
+		{16384, []byte{0x80, 0x80, 0x01}},
+		{2097151, []byte{0xFF, 0xFF, 0x7F}},
+		{2097152, []byte{0x80, 0x80, 0x80, 0x01}},
+		{MaxVarInt, []byte{0xFF, 0xFF, 0xFF, 0x7F}},
+	} {
+		require.Equal(t, tc.expected, encode.New(VarInt(&tc.x)).Encode())
+		var x uint32
+		require.NoError(t, encode.New(VarInt(&x)).Decode(tc.expected))
+		require.Equal(t, tc.x, x)
+	}
+
+	var x uint32
+	require.ErrorIs(t, encode.New(VarInt(&x)).Decode([]byte{0x80, 0x00}), ErrMalformed)
+	tooLong := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0x01}
+	require.ErrorIs(t, encode.New(VarInt(&x)).Decode(tooLong), ErrMalformed)
+	require.ErrorIs(t, encode.New(VarInt(&x)).Decode([]byte{0x80}), encode.ErrShortBuffer)
+	x = MaxVarInt + 1
+	require.Panics(t, func() { encode.New(VarInt(&x)).Encode() })
+}
+
+func TestStrings(t *testing.T) {
+	k, v := "key", "välue"
+	b := []byte{0x01}
+	enc := encode.New(StringPair(&k, &v), Binary(&b))
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{0x00, 0x03, 'k', 'e', 'y', 0x00, 0x06, 'v', 0xC3, 0xA4, 'l', 'u', 'e', 0x00, 0x01, 0x01},
+		buf,
+	)
+	k, v, b = "", "", nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, "key", k)
+	require.Equal(t, "välue", v)
+	require.Equal(t, []byte{0x01}, b)
+
+	require.ErrorIs(t, encode.New(String(&k)).Decode([]byte{0x00, 0x01, 0xFF}), ErrMalformed)
+	require.ErrorIs(t, encode.New(String(&k)).Decode([]byte{0x00, 0x01, 0x00}), ErrMalformed)
+	require.ErrorIs(t, encode.New(String(&k)).Decode([]byte{0x00, 0x02, 'a'}), encode.ErrShortBuffer)
+	k = "a\x00b"
+	require.Panics(t, func() { encode.New(String(&k)).Encode() })
+}
+
+func TestPacket(t *testing.T) {
+	header := byte(0x30)
+	topic := "a/b"
+	propertiesLength := uint32(0)
+	payload := []byte("hi")
+	enc := encode.New(Packet(
+		&header,
+		String(&topic),
+		VarInt(&propertiesLength),
+		encode.Remainder(&payload),
+	))
+
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x30, 0x08, 0x00, 0x03, 'a', '/', 'b', 0x00, 'h', 'i'}, buf)
+
+	header, topic, payload = 0, "", nil
+	// Bytes after the packet belong to the next one.
+	require.NoError(t, enc.Decode(append(buf, 0xE0, 0x00)))
+	require.Equal(t, byte(0x30), header)
+	require.Equal(t, "a/b", topic)
+	require.Equal(t, []byte("hi"), payload)
+
+	require.ErrorIs(t, enc.Decode(buf[:9]), encode.ErrShortBuffer)
+}
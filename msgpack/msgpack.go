@@ -0,0 +1,455 @@
+// Package msgpack provides items that read and write MessagePack values, so that encodings built
+// with github.com/bradenaw/encode can talk to msgpack peers.
+//
+// Values are always written in their shortest form, as is done by nearly every MessagePack
+// implementation. Decode only accepts the shortest form of each value, and returns ErrNotShortest
+// otherwise, so that decoding and re-encoding never changes the size of a value.
+//
+// See https://github.com/msgpack/msgpack/blob/master/spec.md for the format.
+package msgpack
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrType = encode.NewInvalidValueError("msgpack: unexpected type")
+var ErrNotShortest = encode.NewInvalidValueError("msgpack: value not in shortest form")
+
+const (
+	tagNil     = 0xc0
+	tagFalse   = 0xc2
+	tagTrue    = 0xc3
+	tagBin8    = 0xc4
+	tagBin16   = 0xc5
+	tagBin32   = 0xc6
+	tagUint8   = 0xcc
+	tagUint16  = 0xcd
+	tagUint32  = 0xce
+	tagUint64  = 0xcf
+	tagInt8    = 0xd0
+	tagInt16   = 0xd1
+	tagInt32   = 0xd2
+	tagInt64   = 0xd3
+	tagStr8    = 0xd9
+	tagStr16   = 0xda
+	tagStr32   = 0xdb
+	tagArray16 = 0xdc
+	tagArray32 = 0xdd
+	tagMap16   = 0xde
+	tagMap32   = 0xdf
+)
+
+// Encode v as a positive fixint or a uint8, uint16, uint32, or uint64.
+func Uint(v *uint64) encode.Item {
+	return uintItem{v}
+}
+
+type uintItem struct{ v *uint64 }
+
+func (e uintItem) Encode(buf []byte) {
+	x := *e.v
+	switch uintSize(x) {
+	case 1:
+		buf[0] = byte(x)
+	case 2:
+		buf[0] = tagUint8
+		buf[1] = byte(x)
+	case 3:
+		buf[0] = tagUint16
+		binary.BigEndian.PutUint16(buf[1:], uint16(x))
+	case 5:
+		buf[0] = tagUint32
+		binary.BigEndian.PutUint32(buf[1:], uint32(x))
+	default:
+		buf[0] = tagUint64
+		binary.BigEndian.PutUint64(buf[1:], x)
+	}
+}
+func (e uintItem) Size() int { return uintSize(*e.v) }
+func (e uintItem) Decode(buf []byte) error {
+	x, n, err := readUint(buf)
+	if err != nil {
+		return err
+	}
+	if uintSize(x) != n {
+		return ErrNotShortest
+	}
+	*e.v = x
+	return nil
+}
+
+func uintSize(x uint64) int {
+	switch {
+	case x <= 0x7f:
+		return 1
+	case x <= math.MaxUint8:
+		return 2
+	case x <= math.MaxUint16:
+		return 3
+	case x <= math.MaxUint32:
+		return 5
+	default:
+		return 9
+	}
+}
+
+// Returns the value of the unsigned integer at the start of buf and its encoded size.
+func readUint(buf []byte) (uint64, int, error) {
+	if len(buf) < 1 {
+		return 0, 0, encode.ErrShortBuffer
+	}
+	if buf[0] <= 0x7f {
+		return uint64(buf[0]), 1, nil
+	}
+	var n int
+	switch buf[0] {
+	case tagUint8:
+		n = 2
+	case tagUint16:
+		n = 3
+	case tagUint32:
+		n = 5
+	case tagUint64:
+		n = 9
+	default:
+		return 0, 0, ErrType
+	}
+	if len(buf) < n {
+		return 0, 0, encode.ErrShortBuffer
+	}
+	switch n {
+	case 2:
+		return uint64(buf[1]), n, nil
+	case 3:
+		return uint64(binary.BigEndian.Uint16(buf[1:])), n, nil
+	case 5:
+		return uint64(binary.BigEndian.Uint32(buf[1:])), n, nil
+	default:
+		return binary.BigEndian.Uint64(buf[1:]), n, nil
+	}
+}
+
+// Encode v as a positive or negative fixint, or else the shortest of int8, int16, int32, and int64
+// that fits it. Non-negative values beyond the fixint range are encoded as unsigned integers, as
+// most implementations do.
+func Int(v *int64) encode.Item {
+	return intItem{v}
+}
+
+type intItem struct{ v *int64 }
+
+func (e intItem) Encode(buf []byte) {
+	x := *e.v
+	if x >= 0 {
+		ux := uint64(x)
+		uintItem{&ux}.Encode(buf)
+		return
+	}
+	switch intSize(x) {
+	case 1:
+		buf[0] = byte(x)
+	case 2:
+		buf[0] = tagInt8
+		buf[1] = byte(x)
+	case 3:
+		buf[0] = tagInt16
+		binary.BigEndian.PutUint16(buf[1:], uint16(x))
+	case 5:
+		buf[0] = tagInt32
+		binary.BigEndian.PutUint32(buf[1:], uint32(x))
+	default:
+		buf[0] = tagInt64
+		binary.BigEndian.PutUint64(buf[1:], uint64(x))
+	}
+}
+func (e intItem) Size() int { return intSize(*e.v) }
+func (e intItem) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return encode.ErrShortBuffer
+	}
+	if buf[0] <= 0x7f || (buf[0] >= tagUint8 && buf[0] <= tagUint64) {
+		x, n, err := readUint(buf)
+		if err != nil {
+			return err
+		}
+		if x > math.MaxInt64 {
+			return ErrType
+		}
+		if uintSize(x) != n {
+			return ErrNotShortest
+		}
+		*e.v = int64(x)
+		return nil
+	}
+	if buf[0] >= 0xe0 {
+		*e.v = int64(int8(buf[0]))
+		return nil
+	}
+	var n int
+	switch buf[0] {
+	case tagInt8:
+		n = 2
+	case tagInt16:
+		n = 3
+	case tagInt32:
+		n = 5
+	case tagInt64:
+		n = 9
+	default:
+		return ErrType
+	}
+	if len(buf) < n {
+		return encode.ErrShortBuffer
+	}
+	var x int64
+	switch n {
+	case 2:
+		x = int64(int8(buf[1]))
+	case 3:
+		x = int64(int16(binary.BigEndian.Uint16(buf[1:])))
+	case 5:
+		x = int64(int32(binary.BigEndian.Uint32(buf[1:])))
+	default:
+		x = int64(binary.BigEndian.Uint64(buf[1:]))
+	}
+	if intSize(x) != n {
+		return ErrNotShortest
+	}
+	*e.v = x
+	return nil
+}
+
+func intSize(x int64) int {
+	switch {
+	case x >= 0:
+		return uintSize(uint64(x))
+	case x >= -32:
+		return 1
+	case x >= math.MinInt8:
+		return 2
+	case x >= math.MinInt16:
+		return 3
+	case x >= math.MinInt32:
+		return 5
+	default:
+		return 9
+	}
+}
+
+// Encode v as a fixstr, str8, str16, or str32.
+func Str(v *string) encode.Item {
+	return strItem{v}
+}
+
+type strItem struct{ v *string }
+
+func (e strItem) Encode(buf []byte) {
+	n := putLength(buf, uint64(len(*e.v)), 0xa0, 31, tagStr8, tagStr16, tagStr32)
+	copy(buf[n:], *e.v)
+}
+func (e strItem) Size() int {
+	return lengthSize(uint64(len(*e.v)), 31, true) + len(*e.v)
+}
+func (e strItem) Decode(buf []byte) error {
+	b, err := readPayload(buf, 0xa0, 31, tagStr8, tagStr16, tagStr32)
+	if err != nil {
+		return err
+	}
+	*e.v = string(b)
+	return nil
+}
+
+// Encode v as a bin8, bin16, or bin32.
+func Bin(v *[]byte) encode.Item {
+	return binItem{v}
+}
+
+type binItem struct{ v *[]byte }
+
+func (e binItem) Encode(buf []byte) {
+	n := putLength(buf, uint64(len(*e.v)), 0, -1, tagBin8, tagBin16, tagBin32)
+	copy(buf[n:], *e.v)
+}
+func (e binItem) Size() int {
+	return lengthSize(uint64(len(*e.v)), -1, true) + len(*e.v)
+}
+func (e binItem) Decode(buf []byte) error {
+	b, err := readPayload(buf, 0, -1, tagBin8, tagBin16, tagBin32)
+	if err != nil {
+		return err
+	}
+	*e.v = append([]byte(nil), b...)
+	return nil
+}
+
+// Encode the header of an array of n elements as a fixarray, array16, or array32. The elements
+// themselves are the items that follow.
+func ArrayHeader(n *uint32) encode.Item {
+	return headerItem{n: n, fixBase: 0x90, tag16: tagArray16, tag32: tagArray32}
+}
+
+// Encode the header of a map of n key-value pairs as a fixmap, map16, or map32. The keys and values
+// themselves are the items that follow, alternating.
+func MapHeader(n *uint32) encode.Item {
+	return headerItem{n: n, fixBase: 0x80, tag16: tagMap16, tag32: tagMap32}
+}
+
+type headerItem struct {
+	n       *uint32
+	fixBase byte
+	tag16   byte
+	tag32   byte
+}
+
+func (e headerItem) Encode(buf []byte) {
+	putLength(buf, uint64(*e.n), e.fixBase, 15, 0, e.tag16, e.tag32)
+}
+func (e headerItem) Size() int {
+	return lengthSize(uint64(*e.n), 15, false)
+}
+func (e headerItem) Decode(buf []byte) error {
+	l, _, err := readLength(buf, e.fixBase, 15, 0, e.tag16, e.tag32)
+	if err != nil {
+		return err
+	}
+	*e.n = uint32(l)
+	return nil
+}
+
+// Encode v as true or false.
+func Bool(v *bool) encode.Item {
+	return boolItem{v}
+}
+
+type boolItem struct{ v *bool }
+
+func (e boolItem) Encode(buf []byte) {
+	if *e.v {
+		buf[0] = tagTrue
+	} else {
+		buf[0] = tagFalse
+	}
+}
+func (e boolItem) Size() int { return 1 }
+func (e boolItem) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return encode.ErrShortBuffer
+	}
+	switch buf[0] {
+	case tagFalse:
+		*e.v = false
+	case tagTrue:
+		*e.v = true
+	default:
+		return ErrType
+	}
+	return nil
+}
+
+// Encode nil. Useful as a placeholder for a value that's absent.
+func Nil() encode.Item {
+	return nilItem{}
+}
+
+type nilItem struct{}
+
+func (e nilItem) Encode(buf []byte) { buf[0] = tagNil }
+func (e nilItem) Size() int         { return 1 }
+func (e nilItem) Decode(buf []byte) error {
+	if len(buf) < 1 {
+		return encode.ErrShortBuffer
+	}
+	if buf[0] != tagNil {
+		return ErrType
+	}
+	return nil
+}
+
+// The size of the header for a length l. Lengths up to fixMax fit in the header byte itself, or
+// fixMax is -1 if there's no fix form. has8 is whether there's a form with a 1-byte length.
+func lengthSize(l uint64, fixMax int, has8 bool) int {
+	switch {
+	case fixMax >= 0 && l <= uint64(fixMax):
+		return 1
+	case has8 && l <= math.MaxUint8:
+		return 2
+	case l <= math.MaxUint16:
+		return 3
+	default:
+		return 5
+	}
+}
+
+// Write the header for a length l, returning its size. A tag8 of 0 means there's no form with a
+// 1-byte length.
+func putLength(buf []byte, l uint64, fixBase byte, fixMax int, tag8, tag16, tag32 byte) int {
+	switch lengthSize(l, fixMax, tag8 != 0) {
+	case 1:
+		buf[0] = fixBase | byte(l)
+		return 1
+	case 2:
+		buf[0] = tag8
+		buf[1] = byte(l)
+		return 2
+	case 3:
+		buf[0] = tag16
+		binary.BigEndian.PutUint16(buf[1:], uint16(l))
+		return 3
+	default:
+		buf[0] = tag32
+		binary.BigEndian.PutUint32(buf[1:], uint32(l))
+		return 5
+	}
+}
+
+func readLength(
+	buf []byte,
+	fixBase byte,
+	fixMax int,
+	tag8, tag16, tag32 byte,
+) (uint64, int, error) {
+	if len(buf) < 1 {
+		return 0, 0, encode.ErrShortBuffer
+	}
+	var l uint64
+	var n int
+	switch {
+	case fixMax >= 0 && buf[0]&^byte(fixMax) == fixBase:
+		l, n = uint64(buf[0]&byte(fixMax)), 1
+	case tag8 != 0 && buf[0] == tag8:
+		if len(buf) < 2 {
+			return 0, 0, encode.ErrShortBuffer
+		}
+		l, n = uint64(buf[1]), 2
+	case buf[0] == tag16:
+		if len(buf) < 3 {
+			return 0, 0, encode.ErrShortBuffer
+		}
+		l, n = uint64(binary.BigEndian.Uint16(buf[1:])), 3
+	case buf[0] == tag32:
+		if len(buf) < 5 {
+			return 0, 0, encode.ErrShortBuffer
+		}
+		l, n = uint64(binary.BigEndian.Uint32(buf[1:])), 5
+	default:
+		return 0, 0, ErrType
+	}
+	if lengthSize(l, fixMax, tag8 != 0) != n {
+		return 0, 0, ErrNotShortest
+	}
+	return l, n, nil
+}
+
+func readPayload(buf []byte, fixBase byte, fixMax int, tag8, tag16, tag32 byte) ([]byte, error) {
+	l, n, err := readLength(buf, fixBase, fixMax, tag8, tag16, tag32)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(buf[n:])) < l {
+		return nil, encode.ErrShortBuffer
+	}
+	return buf[n : n+int(l)], nil
+}
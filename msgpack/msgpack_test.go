@@ -0,0 +1,57 @@
+package msgpack
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoding(t *testing.T) {
+	checkInt := func(x int64, expected []byte) {
+		require.Equal(t, expected, encode.New(Int(&x)).Encode())
+		var x2 int64
+		require.NoError(t, encode.New(Int(&x2)).Decode(expected))
+		require.Equal(t, x, x2)
+	}
+	checkInt(0, []byte{0x00})
+	checkInt(127, []byte{0x7f})
+	checkInt(128, []byte{0xcc, 0x80})
+	checkInt(65536, []byte{0xce, 0x00, 0x01, 0x00, 0x00})
+	checkInt(-1, []byte{0xff})
+	checkInt(-32, []byte{0xe0})
+	checkInt(-33, []byte{0xd0, 0xdf})
+	checkInt(-129, []byte{0xd1, 0xff, 0x7f})
+	checkInt(-1<<40, []byte{0xd3, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00})
+
+	n := uint32(3)
+	s := "hi"
+	long := string(bytes.Repeat([]byte{'x'}, 40))
+	b := []byte{1, 2}
+	ok := true
+	u := uint64(1 << 33)
+	enc := encode.New(ArrayHeader(&n), Str(&s), Str(&long), Bin(&b), Bool(&ok), Uint(&u), Nil())
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x93, 0xa2, 'h', 'i', 0xd9, 40}, buf[:6])
+
+	var n2 uint32
+	var s2, long2 string
+	var b2 []byte
+	var ok2 bool
+	var u2 uint64
+	enc2 := encode.New(ArrayHeader(&n2), Str(&s2), Str(&long2), Bin(&b2), Bool(&ok2), Uint(&u2), Nil())
+	require.NoError(t, enc2.Decode(buf))
+	require.Equal(t, n, n2)
+	require.Equal(t, s, s2)
+	require.Equal(t, long, long2)
+	require.Equal(t, b, b2)
+	require.Equal(t, ok, ok2)
+	require.Equal(t, u, u2)
+
+	var m uint32
+	require.NoError(t, encode.New(MapHeader(&m)).Decode([]byte{0x82}))
+	require.Equal(t, uint32(2), m)
+	require.ErrorIs(t, encode.New(Uint(&u2)).Decode([]byte{0xcc, 0x05}), ErrNotShortest)
+	require.ErrorIs(t, encode.New(Str(&s2)).Decode([]byte{0xc4, 0x00}), ErrType)
+}
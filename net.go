@@ -0,0 +1,96 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// Encode v as exactly size bytes, which must be 6 for a MAC address (EUI-48) or 8 for an EUI-64.
+// Encode panics if v isn't size bytes long, since writing it would misalign everything after it.
+func HardwareAddr(v *net.HardwareAddr, size int) Item {
+	if size != 6 && size != 8 {
+		panic(fmt.Sprintf("invalid size=%d, must be 6 or 8", size))
+	}
+	return hardwareAddr{v: v, size: size}
+}
+
+type hardwareAddr struct {
+	v    *net.HardwareAddr
+	size int
+}
+
+func (e hardwareAddr) Encode(buf []byte) {
+	if len(*e.v) != e.size {
+		panic(fmt.Sprintf("encode: hardware address %s is not %d bytes", *e.v, e.size))
+	}
+	copy(buf, *e.v)
+}
+func (e hardwareAddr) Size() int {
+	return e.size
+}
+func (e hardwareAddr) kind() string {
+	return "HardwareAddr"
+}
+func (e hardwareAddr) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e hardwareAddr) fixedSize() int {
+	return e.Size()
+}
+func (e hardwareAddr) Decode(buf []byte) error {
+	if len(buf) < e.size {
+		return ErrShortBuffer
+	}
+	v := make(net.HardwareAddr, e.size)
+	copy(v, buf)
+	*e.v = v
+	return nil
+}
+
+// Encode v as an IPv4 address in a 4-byte big endian word, as most network headers store them,
+// taking 4 bytes. WithByteOrder changes the order, for structures that store addresses as host
+// order integers. Encode panics if v isn't an IPv4 address, including if it's an IPv4-mapped IPv6
+// address, which should be unmapped with Unmap first. Decode returns ErrShortBuffer if buf is too
+// short, and otherwise always sets v to a valid IPv4 address.
+func IPv4(v *netip.Addr) Item {
+	return ipv4{v: v, order: binary.BigEndian}
+}
+
+type ipv4 struct {
+	v     *netip.Addr
+	order binary.ByteOrder
+}
+
+func (e ipv4) Encode(buf []byte) {
+	if !e.v.Is4() {
+		panic(fmt.Sprintf("encode: %s is not an IPv4 address", *e.v))
+	}
+	a := e.v.As4()
+	e.order.PutUint32(buf, binary.BigEndian.Uint32(a[:]))
+}
+func (e ipv4) Size() int {
+	return 4
+}
+func (e ipv4) kind() string {
+	return "IPv4"
+}
+func (e ipv4) withByteOrder(order binary.ByteOrder) Item {
+	return ipv4{v: e.v, order: order}
+}
+func (e ipv4) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e ipv4) fixedSize() int {
+	return e.Size()
+}
+func (e ipv4) Decode(buf []byte) error {
+	if len(buf) < 4 {
+		return ErrShortBuffer
+	}
+	var a [4]byte
+	binary.BigEndian.PutUint32(a[:], e.order.Uint32(buf))
+	*e.v = netip.AddrFrom4(a)
+	return nil
+}
@@ -0,0 +1,76 @@
+package encode
+
+import (
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHardwareAddr(t *testing.T) {
+	mac, err := net.ParseMAC("00:00:5e:00:53:01")
+	require.NoError(t, err)
+	eui64, err := net.ParseMAC("02:00:5e:10:00:00:00:01")
+	require.NoError(t, err)
+	enc := New(HardwareAddr(&mac, 6), HardwareAddr(&eui64, 8))
+
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{0x00, 0x00, 0x5E, 0x00, 0x53, 0x01, 0x02, 0x00, 0x5E, 0x10, 0x00, 0x00, 0x00, 0x01},
+		buf,
+	)
+
+	mac, eui64 = nil, nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, "00:00:5e:00:53:01", mac.String())
+	require.Equal(t, "02:00:5e:10:00:00:00:01", eui64.String())
+	require.ErrorIs(t, enc.Decode(buf[:13]), ErrShortBuffer)
+
+	shared := mac
+	other := []byte{9, 9, 9, 9, 9, 9, 2, 0, 0, 0, 0, 0, 0, 0}
+	require.NoError(t, enc.Decode(other))
+	require.Equal(t, "00:00:5e:00:53:01", shared.String())
+	require.Equal(t, "09:09:09:09:09:09", mac.String())
+
+	diffs, err := New(HardwareAddr(&mac, 6)).Diff(buf[:6], other[:6])
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	require.Equal(t, "00:00:5e:00:53:01", diffs[0].ValueA.(net.HardwareAddr).String())
+	require.Equal(t, "09:09:09:09:09:09", diffs[0].ValueB.(net.HardwareAddr).String())
+
+	mac = mac[:5]
+	require.Panics(t, func() { enc.Encode() })
+	require.Panics(t, func() { HardwareAddr(&mac, 20) })
+}
+
+func TestIPv4(t *testing.T) {
+	addr := netip.MustParseAddr("192.0.2.1")
+	enc := New(IPv4(&addr))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0xC0, 0x00, 0x02, 0x01}, buf)
+	require.Equal(
+		t,
+		[]byte{0x01, 0x02, 0x00, 0xC0},
+		enc.WithByteOrder(binary.LittleEndian).Encode(),
+	)
+
+	addr = netip.Addr{}
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, netip.MustParseAddr("192.0.2.1"), addr)
+	require.True(t, addr.Is4())
+	require.NoError(t, enc.WithByteOrder(binary.LittleEndian).Decode([]byte{1, 2, 0, 0xC0}))
+	require.Equal(t, netip.MustParseAddr("192.0.2.1"), addr)
+	require.ErrorIs(t, enc.Decode(buf[:3]), ErrShortBuffer)
+
+	for _, bad := range []netip.Addr{
+		{},
+		netip.MustParseAddr("2001:db8::1"),
+		netip.MustParseAddr("::ffff:192.0.2.1"),
+	} {
+		addr = bad
+		require.Panics(t, func() { enc.Encode() })
+	}
+}
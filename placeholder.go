@@ -0,0 +1,61 @@
+package encode
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// A Placeholder is space reserved in a buffer for a field whose value isn't known until after what
+// follows it has been written, like the length or checksum of a body. Writing the body directly
+// after the placeholder and patching the field afterwards avoids encoding the body twice, once to
+// learn its size and once to write it, for example
+//
+//   buf, length := Reserve(buf, 4)
+//   buf = body.Append(buf)
+//   n := uint32(len(length.Following(buf)))
+//   length.Patch(buf, FixedUint32(&n))
+type Placeholder struct {
+	offset int
+	size   int
+}
+
+// Append size zero bytes to dst for a field to be patched later, returning the extended buffer and
+// a Placeholder for them.
+func Reserve(dst []byte, size int) ([]byte, Placeholder) {
+	p := Placeholder{offset: len(dst), size: size}
+	for i := 0; i < size; i++ {
+		dst = append(dst, 0)
+	}
+	return dst, p
+}
+
+// Write size zero bytes to b for a field to be patched later, returning a Placeholder for them.
+// Patch it with b.Bytes().
+func ReserveBuffer(b *bytes.Buffer, size int) Placeholder {
+	p := Placeholder{offset: b.Len(), size: size}
+	for i := 0; i < size; i++ {
+		b.WriteByte(0)
+	}
+	return p
+}
+
+// Following returns the part of buf written after the placeholder, where buf is the buffer it was
+// reserved in, or a later extension of it.
+func (p Placeholder) Following(buf []byte) []byte {
+	return buf[p.offset+p.size:]
+}
+
+// Patch encodes item into the space reserved by the placeholder in buf, where buf is the buffer it
+// was reserved in, or a later extension of it. Panics if item's size isn't the size reserved.
+func (p Placeholder) Patch(buf []byte, item Item) {
+	size := item.Size()
+	if size != p.size {
+		panic(fmt.Sprintf("item has size %d, but placeholder reserved %d", size, p.size))
+	}
+	field := buf[p.offset : p.offset+p.size]
+	// Items only set the bits they need, so field must start out zeroed.
+	for i := range field {
+		field[i] = 0
+	}
+	item.Encode(field)
+}
@@ -0,0 +1,36 @@
+package encode
+
+import (
+	"bytes"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceholder(t *testing.T) {
+	var s string
+	body := New(LengthDelimString(&s))
+	s = "hello"
+
+	buf := []byte{0xAA}
+	buf, length := Reserve(buf, 4)
+	buf = body.Append(buf)
+	n := uint32(len(length.Following(buf)))
+	length.Patch(buf, FixedUint32(&n))
+	require.Equal(t, []byte{0xAA, 0x00, 0x00, 0x00, 0x06, 0x05, 'h', 'e', 'l', 'l', 'o'}, buf)
+
+	var b bytes.Buffer
+	checksum := ReserveBuffer(&b, 4)
+	body.EncodeBuffer(&b)
+	sum := crc32.ChecksumIEEE(checksum.Following(b.Bytes()))
+	checksum.Patch(b.Bytes(), FixedUint32(&sum))
+
+	var gotSum uint32
+	s = ""
+	require.NoError(t, New(FixedUint32(&gotSum), LengthDelimString(&s)).Decode(b.Bytes()))
+	require.Equal(t, sum, gotSum)
+	require.Equal(t, "hello", s)
+
+	require.Panics(t, func() { length.Patch(buf, Bool(new(bool))) })
+}
@@ -0,0 +1,102 @@
+package encode
+
+import (
+	"encoding/binary"
+)
+
+var ErrInvalidPointer = NewInvalidValueError("encode: pointer does not match where its data is")
+
+// Pointer returns a pair of items for formats where a header holds the position of data that comes
+// later, like the section headers of ELF or the central directory of ZIP. ptr encodes as a big
+// endian uint32 of the position of data, and data encodes as item. Put ptr wherever the format
+// keeps the position, and data after it in the region where the format keeps the data, for example
+//
+//   nameAt, name := Pointer(LengthDelimString(&s))
+//   enc := New(FixedUint16(&version), nameAt, FixedUint32(&flags), name)
+//
+// The position is relative to the start of the encoding, even if data is inside a Group or If.
+// On decode, data returns ErrInvalidPointer if the position decoded by ptr isn't where data was
+// found, since the data is always written immediately after the items before it.
+func Pointer(item Item) (ptr Item, data Item) {
+	state := &pointerState{}
+	return pointer{state: state, order: binary.BigEndian}, pointerTarget{state: state, item: item}
+}
+
+type pointerState struct {
+	// Where the target is in the encoding, as of its last call to setOffset.
+	offset int
+	// The position read by the pointer on decode.
+	decoded uint32
+}
+
+type pointer struct {
+	state *pointerState
+	order binary.ByteOrder
+}
+
+func (e pointer) Encode(buf []byte) {
+	e.order.PutUint32(buf, uint32(e.state.offset))
+}
+func (e pointer) Size() int {
+	return 4
+}
+func (e pointer) kind() string {
+	return "Pointer"
+}
+func (e pointer) withByteOrder(order binary.ByteOrder) Item {
+	return pointer{state: e.state, order: order}
+}
+func (e pointer) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e pointer) fixedSize() int {
+	return e.Size()
+}
+func (e pointer) Decode(buf []byte) error {
+	if len(buf) < 4 {
+		return ErrShortBuffer
+	}
+	e.state.decoded = e.order.Uint32(buf)
+	return nil
+}
+
+type pointerTarget struct {
+	state *pointerState
+	item  Item
+}
+
+func (e pointerTarget) setOffset(offset int) {
+	e.state.offset = offset
+	setOffset(e.item, offset)
+}
+func (e pointerTarget) Encode(buf []byte) {
+	e.item.Encode(buf)
+}
+func (e pointerTarget) Size() int {
+	return e.item.Size()
+}
+func (e pointerTarget) kind() string {
+	return "Pointer"
+}
+func (e pointerTarget) withByteOrder(order binary.ByteOrder) Item {
+	return pointerTarget{state: e.state, item: withByteOrder(e.item, order)}
+}
+func (e pointerTarget) sizeBounds() (int, int) {
+	return sizeBounds(e.item)
+}
+func (e pointerTarget) dependsOnEarlier() bool {
+	// The position is checked against the one ptr decoded.
+	return true
+}
+func (e pointerTarget) skip(buf []byte) (int, error) {
+	return skipItem(e.item, buf)
+}
+func (e pointerTarget) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e pointerTarget) decodeLimited(buf []byte, s *decodeState) error {
+	if uint64(e.state.decoded) != uint64(e.state.offset) {
+		return ErrInvalidPointer
+	}
+	return decodeItem(e.item, buf, s)
+}
@@ -0,0 +1,78 @@
+package encode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointer(t *testing.T) {
+	var version uint16
+	var name string
+	var blob []byte
+	nameAt, nameData := Pointer(LengthDelimString(&name))
+	blobAt, blobData := Pointer(LengthDelimBytes(&blob))
+	enc := New(FixedUint16(&version), blobAt, nameAt, nameData, blobData)
+
+	version, name, blob = 3, "abc", []byte{0xFF}
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{
+			0x00, 0x03,
+			0x00, 0x00, 0x00, 0x0E,
+			0x00, 0x00, 0x00, 0x0A,
+			0x03, 'a', 'b', 'c',
+			0x01, 0xFF,
+		},
+		buf,
+	)
+	require.True(t, enc.EqualsEncoded(buf))
+	var w bytes.Buffer
+	_, err := enc.WriteTo(&w)
+	require.NoError(t, err)
+	require.Equal(t, buf, w.Bytes())
+
+	version, name, blob = 0, "", nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint16(3), version)
+	require.Equal(t, "abc", name)
+	require.Equal(t, []byte{0xFF}, blob)
+
+	buf[5] = 0x0F
+	require.ErrorIs(t, enc.Decode(buf), ErrInvalidPointer)
+	require.ErrorIs(t, enc.Decode(buf), ErrInvalidValue)
+
+	name = "abcd"
+	le := enc.WithByteOrder(binary.LittleEndian)
+	require.Equal(
+		t,
+		[]byte{
+			0x03, 0x00,
+			0x0F, 0x00, 0x00, 0x00,
+			0x0A, 0x00, 0x00, 0x00,
+			0x04, 'a', 'b', 'c', 'd',
+			0x01, 0xFF,
+		},
+		le.Encode(),
+	)
+
+	// Positions are from the start of the encoding wherever data is.
+	var a, b byte
+	aAt, aData := Pointer(Byte(&a))
+	bAt, bData := Pointer(Byte(&b))
+	enc = New(aAt, bAt, Group(Byte(&a), aData), Trailing(bData, nil))
+	a, b = 7, 8
+	buf = enc.Encode()
+	require.Equal(
+		t,
+		[]byte{0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x0A, 0x07, 0x07, 0x08},
+		buf,
+	)
+	a, b = 0, 0
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, byte(7), a)
+	require.Equal(t, byte(8), b)
+}
@@ -0,0 +1,191 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DecodeFields is Decode, except that it only needs to decode the items at the given indices into
+// enc. Other items are skipped where possible by finding their sizes from buf, which for
+// fixed-size, varint, and length-delimited items is much cheaper than decoding them, doesn't
+// allocate, and leaves their values untouched. Some are decoded anyway, setting their values as
+// Decode would:
+//
+//   - items that can't be measured without decoding them, like Columnar
+//   - items that depend on the values of earlier items, like If, Switch, and the data of Pointer,
+//     along with every item before them, since any of those may be the one depended on
+//
+// so place these after the fields read on hot paths if that matters. Nothing after the last
+// requested item is looked at.
+//
+// Panics if any index is out of range.
+func (enc Encoding) DecodeFields(buf []byte, indices ...int) error {
+	want := make([]bool, len(enc.items))
+	last := -1
+	for _, idx := range indices {
+		if idx < 0 || idx >= len(enc.items) {
+			panic(fmt.Sprintf("invalid index=%d, must be in [0, %d)", idx, len(enc.items)))
+		}
+		want[idx] = true
+		if idx > last {
+			last = idx
+		}
+	}
+
+	decodeThrough := lastDependent(enc.items[:last+1])
+	i := 0
+	for idx, item := range enc.items[:last+1] {
+		setOffset(item, i)
+		var n int
+		if want[idx] || idx <= decodeThrough {
+			err := item.Decode(buf[i:])
+			if err != nil {
+				return err
+			}
+			n = item.Size()
+		} else {
+			var err error
+			n, err = skipItem(item, buf[i:])
+			if err != nil {
+				return err
+			}
+		}
+		i += n
+		if i > len(buf) {
+			i = len(buf)
+		}
+	}
+	return nil
+}
+
+// Implemented by items that can find their encoded size at the start of buf without decoding it.
+type skipper interface {
+	skip(buf []byte) (int, error)
+}
+
+// Returns the encoded size of item at the start of buf, decoding item if it doesn't implement
+// skipper and isn't fixed-size.
+func skipItem(item Item, buf []byte) (int, error) {
+	if s, ok := item.(skipper); ok {
+		return s.skip(buf)
+	}
+	if f, ok := item.(fixedItem); ok {
+		return skipFixed(f.fixedSize(), buf)
+	}
+	err := item.Decode(buf)
+	if err != nil {
+		return 0, err
+	}
+	return item.Size(), nil
+}
+
+// Implemented by items that read values bound to earlier items, like If reading its condition, or
+// that contain such items. The items they depend on must be decoded rather than skipped before they
+// can be decoded or skipped themselves.
+type dependentItem interface {
+	dependsOnEarlier() bool
+}
+
+func dependsOnEarlier(item Item) bool {
+	if d, ok := item.(dependentItem); ok {
+		return d.dependsOnEarlier()
+	}
+	return false
+}
+
+func anyDependsOnEarlier(items []Item) bool {
+	for _, item := range items {
+		if dependsOnEarlier(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the index of the last of items that depends on earlier items, or -1 if none do.
+func lastDependent(items []Item) int {
+	for i := len(items) - 1; i >= 0; i-- {
+		if dependsOnEarlier(items[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func skipFixed(size int, buf []byte) (int, error) {
+	if len(buf) < size {
+		return 0, ErrShortBuffer
+	}
+	return size, nil
+}
+
+func skipUvarint(buf []byte) (int, error) {
+	_, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, ErrShortBuffer
+	}
+	if n < 0 {
+		return 0, ErrVarintOverflow
+	}
+	return n, nil
+}
+
+func skipLengthDelim(buf []byte) (int, error) {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, ErrShortBuffer
+	}
+	if n < 0 {
+		return 0, ErrVarintOverflow
+	}
+	if uint64(len(buf[n:])) < l {
+		return 0, ErrShortBuffer
+	}
+	return n + int(l), nil
+}
+
+// OffsetOf returns where the i'th item of enc is in buf, which holds an encoding of enc, so that
+// it can be modified in place without re-encoding the rest of buf, for example
+//
+//   offset, width, err := enc.OffsetOf(buf, 3)
+//   if err != nil { ... }
+//   count++
+//   FixedUint32(&count).Encode(buf[offset : offset+width])
+//
+// The items before it are measured as in DecodeFields, so they may be variable-length, and some of
+// them may be decoded for the same reasons. Panics if i is out of range or the i'th item isn't
+// fixed-size, like the fixed-width integers, Byte, Bool, Flags, Bytes16, and Padding are.
+func (enc Encoding) OffsetOf(buf []byte, i int) (offset int, width int, err error) {
+	if i < 0 || i >= len(enc.items) {
+		panic(fmt.Sprintf("invalid i=%d, must be in [0, %d)", i, len(enc.items)))
+	}
+	min, max := sizeBounds(enc.items[i])
+	if min != max {
+		panic(fmt.Sprintf("item %d of type %T isn't fixed-size", i, enc.items[i]))
+	}
+	decodeThrough := lastDependent(enc.items[:i])
+	for idx, item := range enc.items[:i] {
+		setOffset(item, offset)
+		var n int
+		if idx <= decodeThrough {
+			err = item.Decode(buf[offset:])
+			n = item.Size()
+		} else {
+			n, err = skipItem(item, buf[offset:])
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		offset = minInt(offset+n, len(buf))
+	}
+	width = min
+	if len(buf)-offset < width {
+		return 0, 0, ErrShortBuffer
+	}
+	return offset, width, nil
+}
+
+// Implemented by items that always encode to the same number of bytes.
+type fixedItem interface {
+	fixedSize() int
+}
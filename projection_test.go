@@ -0,0 +1,121 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeFields(t *testing.T) {
+	var a uint32
+	var b []byte
+	var c uint64
+	var d string
+	var e uint16
+	var f bool
+	var g []uint64
+	enc := New(
+		FixedUint32(&a),
+		LengthDelimBytes(&b),
+		Group(Uvarint64(&c), LengthDelimString(&d)),
+		FixedUint16(&e),
+		DeltaUvarints(&g),
+		Bool(&f),
+	)
+	a, b, c, d, e, f, g = 7, []byte("skipped"), 300, "also skipped", 0xbeef, true, []uint64{1, 2}
+	buf := enc.Encode()
+
+	var a2 uint32
+	var b2 []byte
+	var c2 uint64
+	var d2 string
+	var e2 uint16
+	var f2 bool
+	var g2 []uint64
+	enc2 := New(
+		FixedUint32(&a2),
+		LengthDelimBytes(&b2),
+		Group(Uvarint64(&c2), LengthDelimString(&d2)),
+		FixedUint16(&e2),
+		DeltaUvarints(&g2),
+		Bool(&f2),
+	)
+	require.NoError(t, enc2.DecodeFields(buf, 3, 0))
+	require.Equal(t, a, a2)
+	require.Equal(t, e, e2)
+	require.Nil(t, b2)
+	require.Equal(t, uint64(0), c2)
+	require.Equal(t, "", d2)
+	require.Nil(t, g2)
+
+	require.NoError(t, enc2.DecodeFields(buf, 5))
+	require.Equal(t, f, f2)
+
+	require.NoError(t, enc2.DecodeFields(buf))
+	require.ErrorIs(t, enc2.DecodeFields(buf[:10], 3), io.ErrUnexpectedEOF)
+	require.Panics(t, func() { _ = enc2.DecodeFields(buf, 6) })
+}
+
+func TestDecodeFieldsConditional(t *testing.T) {
+	var p bool
+	var a uint32
+	var b uint16
+	enc := New(Flags(&p), If(&p, FixedUint32(&a)), FixedUint16(&b))
+	p, a, b = true, 5, 9
+	buf := enc.Encode()
+
+	p, a, b = false, 0, 0
+	require.NoError(t, enc.DecodeFields(buf, 2))
+	require.Equal(t, uint16(9), b)
+	require.True(t, p)
+
+	p, a, b = false, 0, 0
+	offset, width, err := enc.OffsetOf(buf, 2)
+	require.NoError(t, err)
+	require.Equal(t, 5, offset)
+	require.Equal(t, 2, width)
+
+	var tag uint8
+	var x uint64
+	var y uint32
+	enc = New(Byte(&tag), Switch(&tag, map[uint8]Item{1: Uvarint64(&x)}), FixedUint32(&y))
+	tag, x, y = 1, 300, 7
+	buf = enc.Encode()
+
+	tag, x, y = 0, 0, 0
+	require.NoError(t, enc.DecodeFields(buf, 2))
+	require.Equal(t, uint32(7), y)
+}
+
+func TestOffsetOf(t *testing.T) {
+	var name string
+	var count uint32
+	var flag bool
+	enc := New(LengthDelimString(&name), FixedUint32(&count), Bool(&flag))
+	name, count, flag = "abc", 41, true
+	buf := enc.Encode()
+
+	offset, width, err := enc.OffsetOf(buf, 1)
+	require.NoError(t, err)
+	require.Equal(t, 4, offset)
+	require.Equal(t, 4, width)
+	count++
+	FixedUint32(&count).Encode(buf[offset : offset+width])
+
+	count = 0
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint32(42), count)
+	require.Equal(t, "abc", name)
+	require.True(t, flag)
+
+	offset, width, err = enc.OffsetOf(buf, 2)
+	require.NoError(t, err)
+	require.Equal(t, 8, offset)
+	require.Equal(t, 1, width)
+
+	_, _, err = enc.OffsetOf(buf[:8], 2)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.Panics(t, func() { _, _, _ = enc.OffsetOf(buf, 0) })
+	require.Panics(t, func() { _, _, _ = enc.OffsetOf(buf, 3) })
+}
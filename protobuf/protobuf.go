@@ -0,0 +1,351 @@
+// Package protobuf provides items that read and write the Protocol Buffers wire format, so that
+// encodings built with github.com/bradenaw/encode can interoperate with protoc-generated code.
+//
+// A message is declared as a list of fields keyed by their field numbers, mirroring the .proto
+// definition:
+//
+//   // message Foo {
+//   //   uint64 id = 1;
+//   //   string name = 2;
+//   // }
+//   func (f *foo) encoding() encode.Encoding {
+//   	return encode.New(protobuf.Message(
+//   		protobuf.Uint64(1, &f.id),
+//   		protobuf.String(2, &f.name),
+//   	))
+//   }
+//
+// See https://developers.google.com/protocol-buffers/docs/encoding for the wire format.
+package protobuf
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrWireType = encode.NewInvalidValueError("protobuf: field has unexpected wire type")
+var ErrInvalidTag = encode.NewInvalidValueError("protobuf: invalid field tag")
+
+const (
+	wireVarint          = 0
+	wireFixed64         = 1
+	wireLengthDelimited = 2
+	wireStartGroup      = 3
+	wireEndGroup        = 4
+	wireFixed32         = 5
+)
+
+// A Field is one field of a Message, identified by its field number.
+type Field interface {
+	number() int
+	wireType() int
+	// The field's value, without its tag.
+	payload() encode.Item
+}
+
+type field struct {
+	n    int
+	wt   int
+	item encode.Item
+}
+
+func (f field) number() int          { return f.n }
+func (f field) wireType() int        { return f.wt }
+func (f field) payload() encode.Item { return f.item }
+
+func newField(n int, wt int, item encode.Item) Field {
+	if n < 1 || n > 1<<29-1 {
+		panic("protobuf: invalid field number, must be in [1, 2^29-1]")
+	}
+	return field{n: n, wt: wt, item: item}
+}
+
+// Encode fields in order as a protobuf message, taking up the remainder of the buffer.
+//
+// On decode, fields may appear in any order, as they may in messages from other implementations.
+// Fields not present in the buffer are left untouched, unknown fields are skipped, and if a field
+// appears more than once the last occurrence wins.
+func Message(fields ...Field) encode.Item {
+	return message{fields}
+}
+
+type message struct{ fields []Field }
+
+func (e message) Encode(buf []byte) {
+	i := 0
+	for _, f := range e.fields {
+		i += binary.PutUvarint(buf[i:], tag(f))
+		item := f.payload()
+		size := item.Size()
+		item.Encode(buf[i : i+size])
+		i += size
+	}
+}
+func (e message) Size() int {
+	size := 0
+	for _, f := range e.fields {
+		size += uvarintSize(tag(f)) + f.payload().Size()
+	}
+	return size
+}
+func (e message) Decode(buf []byte) error {
+	i := 0
+	for i < len(buf) {
+		t, n := binary.Uvarint(buf[i:])
+		if n == 0 {
+			return encode.ErrShortBuffer
+		}
+		if n < 0 {
+			return encode.ErrVarintOverflow
+		}
+		i += n
+		number := t >> 3
+		wt := int(t & 0x7)
+		if number == 0 {
+			return ErrInvalidTag
+		}
+
+		// The wire type alone determines the value's length, so use that rather than trusting the
+		// field to consume the right number of bytes.
+		n, err := skip(buf[i:], wt)
+		if err != nil {
+			return err
+		}
+		value := buf[i : i+n]
+		i += n
+
+		for _, f := range e.fields {
+			if uint64(f.number()) != number {
+				continue
+			}
+			if f.wireType() != wt {
+				return ErrWireType
+			}
+			err := f.payload().Decode(value)
+			if err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func tag(f Field) uint64 {
+	return uint64(f.number())<<3 | uint64(f.wireType())
+}
+
+// Returns the length of the value at the start of buf with wire type wt.
+func skip(buf []byte, wt int) (int, error) {
+	switch wt {
+	case wireVarint:
+		_, n := binary.Uvarint(buf)
+		if n == 0 {
+			return 0, encode.ErrShortBuffer
+		}
+		if n < 0 {
+			return 0, encode.ErrVarintOverflow
+		}
+		return n, nil
+	case wireFixed64:
+		if len(buf) < 8 {
+			return 0, encode.ErrShortBuffer
+		}
+		return 8, nil
+	case wireLengthDelimited:
+		l, n := binary.Uvarint(buf)
+		if n == 0 {
+			return 0, encode.ErrShortBuffer
+		}
+		if n < 0 {
+			return 0, encode.ErrVarintOverflow
+		}
+		if uint64(len(buf[n:])) < l {
+			return 0, encode.ErrShortBuffer
+		}
+		return n + int(l), nil
+	case wireFixed32:
+		if len(buf) < 4 {
+			return 0, encode.ErrShortBuffer
+		}
+		return 4, nil
+	default:
+		// Groups are deprecated, and not supported.
+		return 0, ErrWireType
+	}
+}
+
+// A uint64 or uint32 field, encoded as a varint.
+func Uint64(n int, v *uint64) Field {
+	return newField(n, wireVarint, encode.Uvarint64(v))
+}
+
+// An int64 or int32 field, encoded as the varint of its two's complement. Negative numbers always
+// take 10 bytes; prefer Sint64 for fields that are often negative.
+func Int64(n int, v *int64) Field {
+	return newField(n, wireVarint, int64Varint{v})
+}
+
+// An sint64 or sint32 field, encoded as a zigzag varint so that small negative numbers are small.
+func Sint64(n int, v *int64) Field {
+	return newField(n, wireVarint, zigzagVarint{v})
+}
+
+// A bool field.
+func Bool(n int, v *bool) Field {
+	return newField(n, wireVarint, boolVarint{v})
+}
+
+// A fixed32 field, encoded in little endian order.
+func Fixed32(n int, v *uint32) Field {
+	return newField(n, wireFixed32, fixed32{v})
+}
+
+// A fixed64 field, encoded in little endian order.
+func Fixed64(n int, v *uint64) Field {
+	return newField(n, wireFixed64, fixed64{v})
+}
+
+// A double field.
+func Double(n int, v *float64) Field {
+	return newField(n, wireFixed64, double{v})
+}
+
+// A bytes field.
+func Bytes(n int, v *[]byte) Field {
+	return newField(n, wireLengthDelimited, encode.LengthDelimBytes(v))
+}
+
+// A string field.
+func String(n int, v *string) Field {
+	return newField(n, wireLengthDelimited, encode.LengthDelimString(v))
+}
+
+// A field containing an embedded message made up of fields.
+func Embedded(n int, fields ...Field) Field {
+	return newField(n, wireLengthDelimited, embedded{message{fields}})
+}
+
+type int64Varint struct{ v *int64 }
+
+func (e int64Varint) Encode(buf []byte) { binary.PutUvarint(buf, uint64(*e.v)) }
+func (e int64Varint) Size() int         { return uvarintSize(uint64(*e.v)) }
+func (e int64Varint) Decode(buf []byte) error {
+	x, err := readUvarint(buf)
+	if err != nil {
+		return err
+	}
+	*e.v = int64(x)
+	return nil
+}
+
+type zigzagVarint struct{ v *int64 }
+
+func (e zigzagVarint) Encode(buf []byte) { binary.PutVarint(buf, *e.v) }
+func (e zigzagVarint) Size() int {
+	var b [binary.MaxVarintLen64]byte
+	return binary.PutVarint(b[:], *e.v)
+}
+func (e zigzagVarint) Decode(buf []byte) error {
+	x, err := readUvarint(buf)
+	if err != nil {
+		return err
+	}
+	*e.v = int64(x>>1) ^ -int64(x&1)
+	return nil
+}
+
+type boolVarint struct{ v *bool }
+
+func (e boolVarint) Encode(buf []byte) {
+	if *e.v {
+		buf[0] = 1
+	}
+}
+func (e boolVarint) Size() int { return 1 }
+func (e boolVarint) Decode(buf []byte) error {
+	x, err := readUvarint(buf)
+	if err != nil {
+		return err
+	}
+	*e.v = x != 0
+	return nil
+}
+
+type fixed32 struct{ v *uint32 }
+
+func (e fixed32) Encode(buf []byte) { binary.LittleEndian.PutUint32(buf, *e.v) }
+func (e fixed32) Size() int         { return 4 }
+func (e fixed32) Decode(buf []byte) error {
+	if len(buf) < 4 {
+		return encode.ErrShortBuffer
+	}
+	*e.v = binary.LittleEndian.Uint32(buf)
+	return nil
+}
+
+type fixed64 struct{ v *uint64 }
+
+func (e fixed64) Encode(buf []byte) { binary.LittleEndian.PutUint64(buf, *e.v) }
+func (e fixed64) Size() int         { return 8 }
+func (e fixed64) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return encode.ErrShortBuffer
+	}
+	*e.v = binary.LittleEndian.Uint64(buf)
+	return nil
+}
+
+type double struct{ v *float64 }
+
+func (e double) Encode(buf []byte) { binary.LittleEndian.PutUint64(buf, math.Float64bits(*e.v)) }
+func (e double) Size() int         { return 8 }
+func (e double) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return encode.ErrShortBuffer
+	}
+	*e.v = math.Float64frombits(binary.LittleEndian.Uint64(buf))
+	return nil
+}
+
+type embedded struct{ m message }
+
+func (e embedded) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(e.m.Size()))
+	e.m.Encode(buf[n:])
+}
+func (e embedded) Size() int {
+	size := e.m.Size()
+	return uvarintSize(uint64(size)) + size
+}
+func (e embedded) Decode(buf []byte) error {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return encode.ErrShortBuffer
+	}
+	if n < 0 {
+		return encode.ErrVarintOverflow
+	}
+	if uint64(len(buf[n:])) < l {
+		return encode.ErrShortBuffer
+	}
+	return e.m.Decode(buf[n : n+int(l)])
+}
+
+func readUvarint(buf []byte) (uint64, error) {
+	x, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, encode.ErrShortBuffer
+	}
+	if n < 0 {
+		return 0, encode.ErrVarintOverflow
+	}
+	return x, nil
+}
+
+func uvarintSize(x uint64) int {
+	var b [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(b[:], x)
+}
@@ -0,0 +1,78 @@
+package protobuf
+
+import (
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage(t *testing.T) {
+	// The examples from https://developers.google.com/protocol-buffers/docs/encoding.
+	a := uint64(150)
+	b := "testing"
+	require.Equal(t, []byte{0x08, 0x96, 0x01}, encode.New(Message(Uint64(1, &a))).Encode())
+	require.Equal(
+		t,
+		[]byte{0x12, 0x07, 0x74, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x67},
+		encode.New(Message(String(2, &b))).Encode(),
+	)
+	require.Equal(
+		t,
+		[]byte{0x1a, 0x03, 0x08, 0x96, 0x01},
+		encode.New(Message(Embedded(3, Uint64(1, &a)))).Encode(),
+	)
+
+	type record struct {
+		id     uint64
+		delta  int64
+		neg    int64
+		ok     bool
+		crc    uint32
+		stamp  uint64
+		ratio  float64
+		data   []byte
+		name   string
+		nested uint64
+	}
+	message := func(r *record) encode.Encoding {
+		return encode.New(Message(
+			Uint64(1, &r.id),
+			Sint64(2, &r.delta),
+			Int64(3, &r.neg),
+			Bool(4, &r.ok),
+			Fixed32(5, &r.crc),
+			Fixed64(6, &r.stamp),
+			Double(7, &r.ratio),
+			Bytes(8, &r.data),
+			String(9, &r.name),
+			Embedded(10, Uint64(1, &r.nested)),
+		))
+	}
+	r := record{
+		id:     1 << 40,
+		delta:  -3,
+		neg:    -1,
+		ok:     true,
+		crc:    0xDEADBEEF,
+		stamp:  12345,
+		ratio:  0.5,
+		data:   []byte{1, 2, 3},
+		name:   "name",
+		nested: 7,
+	}
+	buf := message(&r).Encode()
+
+	var r2 record
+	require.NoError(t, message(&r2).Decode(buf))
+	require.Equal(t, r, r2)
+
+	// Readers that only know some of the fields skip the rest, in any order.
+	var name string
+	var id uint64
+	require.NoError(t, encode.New(Message(String(9, &name), Uint64(1, &id))).Decode(buf))
+	require.Equal(t, r.name, name)
+	require.Equal(t, r.id, id)
+
+	require.ErrorIs(t, encode.New(Message(Fixed32(1, &r2.crc))).Decode(buf), ErrWireType)
+}
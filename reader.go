@@ -0,0 +1,129 @@
+package encode
+
+import (
+	"io"
+	"math/bits"
+)
+
+// These read the same formats as the corresponding items, but one value at a time from a stream,
+// so that foreign streams can be parsed incrementally without building an Encoding. Each reads
+// exactly the bytes of one value. They return io.EOF only if r ends before the first byte of the
+// value, and io.ErrUnexpectedEOF if it ends partway through.
+
+// Read a value in the format of Uvarint64 from r.
+func ReadUvarint(r io.ByteReader) (uint64, error) {
+	var x uint64
+	for shift := uint(0); ; shift += 7 {
+		if shift >= 64 {
+			return 0, ErrVarintOverflow
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			if shift > 0 && err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if shift == 63 && b > 1 {
+			return 0, ErrVarintOverflow
+		}
+		x |= uint64(b&0x7F) << shift
+		if b < 0x80 {
+			return x, nil
+		}
+	}
+}
+
+// Read a value in the format of OrdUvarint64 from r.
+func ReadOrdUvarint64(r io.ByteReader) (uint64, error) {
+	var buf [9]byte
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	buf[0] = b
+	n := bits.LeadingZeros8(^b) + 1
+	err = readBytes(r, buf[1:n])
+	if err != nil {
+		return 0, err
+	}
+	var x uint64
+	err = ordUvarint64{&x}.Decode(buf[:n])
+	return x, err
+}
+
+// Read a value in the format of OrdVarint64 from r.
+func ReadOrdVarint64(r io.ByteReader) (int64, error) {
+	var buf [9]byte
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	buf[0] = b
+	start, n := 1, 0
+	switch b {
+	case 0x00, 0xFF:
+		// The length depends on the second byte too.
+		err = readBytes(r, buf[1:2])
+		if err != nil {
+			return 0, err
+		}
+		start, n = 2, 8
+		if (buf[1]&0x80 == 0) == (b == 0x00) {
+			n = 9
+		}
+	default:
+		if b&0x80 == 0 {
+			n = bits.LeadingZeros8(b)
+		} else {
+			n = bits.LeadingZeros8(^b)
+		}
+	}
+	err = readBytes(r, buf[start:n])
+	if err != nil {
+		return 0, err
+	}
+	var x int64
+	err = ordVarint64{&x}.Decode(buf[:n])
+	return x, err
+}
+
+// Read a value in the format of LengthDelimBytes from r. Bytes are read from r one at a time until
+// the length is known, so that nothing past the end of the value is consumed.
+//
+// Returns ErrFrameTooLarge before reading the bytes if there are more than maxLength of them. If
+// maxLength is 0, values of any length are allowed.
+func ReadLengthDelim(r io.Reader, maxLength int) ([]byte, error) {
+	return readFrame(r, maxLength)
+}
+
+// Read len(buf) bytes from r into buf, which aren't the first of a value.
+func readBytes(r io.ByteReader, buf []byte) error {
+	for i := range buf {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		buf[i] = b
+	}
+	return nil
+}
+
+// Adapts an io.Reader to io.ByteReader, reading a byte at a time if it isn't one already.
+func asByteReader(r io.Reader) io.ByteReader {
+	if br, ok := r.(io.ByteReader); ok {
+		return br
+	}
+	return oneByteReader{r}
+}
+
+type oneByteReader struct{ r io.Reader }
+
+func (r oneByteReader) ReadByte() (byte, error) {
+	var one [1]byte
+	_, err := io.ReadFull(r.r, one[:])
+	return one[0], err
+}
@@ -0,0 +1,75 @@
+package encode
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadVarints(t *testing.T) {
+	uints := []uint64{0, 1, 127, 128, 300, 1 << 35, 1<<56 - 1, 1 << 56, math.MaxUint64}
+	ints := []int64{0, 1, -1, 63, -64, 64, -65, 1 << 40, -(1 << 48), math.MaxInt64, math.MinInt64}
+
+	var stream []byte
+	for _, x := range uints {
+		stream = append(stream, New(Uvarint64(&x), OrdUvarint64(&x)).Encode()...)
+	}
+	for _, x := range ints {
+		stream = append(stream, New(OrdVarint64(&x)).Encode()...)
+	}
+
+	r := bytes.NewReader(stream)
+	for _, x := range uints {
+		got, err := ReadUvarint(r)
+		require.NoError(t, err)
+		require.Equal(t, x, got)
+		got, err = ReadOrdUvarint64(r)
+		require.NoError(t, err)
+		require.Equal(t, x, got)
+	}
+	for _, x := range ints {
+		got, err := ReadOrdVarint64(r)
+		require.NoError(t, err)
+		require.Equal(t, x, got)
+	}
+	_, err := ReadOrdVarint64(r)
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = ReadUvarint(bytes.NewReader([]byte{0x80}))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ReadOrdUvarint64(bytes.NewReader([]byte{0xC0}))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ReadOrdVarint64(bytes.NewReader([]byte{0xFF}))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ReadUvarint(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 11)))
+	require.ErrorIs(t, err, ErrOverflowVarint)
+	_, err = ReadUvarint(bytes.NewReader(append(bytes.Repeat([]byte{0xFF}, 9), 0x7F)))
+	require.ErrorIs(t, err, ErrOverflowVarint)
+	x, err := ReadUvarint(bytes.NewReader(append(bytes.Repeat([]byte{0xFF}, 9), 0x01)))
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint64), x)
+}
+
+func TestReadLengthDelim(t *testing.T) {
+	a := []byte("hello")
+	b := strings.Repeat("x", 200)
+	stream := New(LengthDelimBytes(&a), LengthDelimString(&b)).Encode()
+
+	r := iotest.OneByteReader(bytes.NewReader(stream))
+	got, err := ReadLengthDelim(r, 0)
+	require.NoError(t, err)
+	require.Equal(t, a, got)
+	got, err = ReadLengthDelim(r, 0)
+	require.NoError(t, err)
+	require.Equal(t, b, string(got))
+	_, err = ReadLengthDelim(r, 0)
+	require.ErrorIs(t, err, io.EOF)
+
+	_, err = ReadLengthDelim(bytes.NewReader(stream), 4)
+	require.ErrorIs(t, err, ErrFrameTooLarge)
+}
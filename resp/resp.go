@@ -0,0 +1,270 @@
+// Package resp provides items that read and write values in RESP, the Redis serialization
+// protocol, so that tools speaking to Redis can build and parse frames with encodings built with
+// github.com/bradenaw/encode.
+//
+// Only the RESP2 types are supported. Decode only accepts integers and lengths written the way
+// Redis writes them, without leading zeroes or a '+' sign, and returns ErrSyntax otherwise, so
+// that decoding and re-encoding never changes the size of a value.
+//
+// See https://redis.io/docs/reference/protocol-spec/ for the format.
+package resp
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/bradenaw/encode"
+)
+
+var ErrType = encode.NewInvalidValueError("resp: unexpected type")
+var ErrSyntax = encode.NewInvalidValueError("resp: malformed value")
+
+const (
+	prefixSimpleString = '+'
+	prefixError        = '-'
+	prefixInteger      = ':'
+	prefixBulkString   = '$'
+	prefixArray        = '*'
+)
+
+var crlf = []byte("\r\n")
+
+// Encode v as a simple string, like "+OK\r\n". Panics on encode if v contains '\r' or '\n'.
+func SimpleString(v *string) encode.Item {
+	return lineItem{prefix: prefixSimpleString, v: v}
+}
+
+// Encode v as an error, like "-ERR unknown command\r\n". Panics on encode if v contains '\r' or
+// '\n'.
+func Error(v *string) encode.Item {
+	return lineItem{prefix: prefixError, v: v}
+}
+
+type lineItem struct {
+	prefix byte
+	v      *string
+}
+
+func (e lineItem) Encode(buf []byte) {
+	if strings.ContainsAny(*e.v, "\r\n") {
+		panic("resp: simple strings and errors may not contain CR or LF")
+	}
+	buf[0] = e.prefix
+	n := 1 + copy(buf[1:], *e.v)
+	copy(buf[n:], crlf)
+}
+func (e lineItem) Size() int {
+	return 1 + len(*e.v) + len(crlf)
+}
+func (e lineItem) Decode(buf []byte) error {
+	line, _, err := readLine(buf, e.prefix)
+	if err != nil {
+		return err
+	}
+	*e.v = string(line)
+	return nil
+}
+
+// Encode v as an integer, like ":1000\r\n".
+func Integer(v *int64) encode.Item {
+	return integerItem{prefix: prefixInteger, v: v}
+}
+
+// Encode the header of an array of n elements, like "*2\r\n". The elements themselves are the
+// items that follow. n may be -1 for a null array.
+func ArrayHeader(n *int64) encode.Item {
+	return integerItem{prefix: prefixArray, v: n}
+}
+
+type integerItem struct {
+	prefix byte
+	v      *int64
+}
+
+func (e integerItem) Encode(buf []byte) {
+	buf[0] = e.prefix
+	b := strconv.AppendInt(buf[1:1], *e.v, 10)
+	copy(buf[1+len(b):], crlf)
+}
+func (e integerItem) Size() int {
+	return 1 + intLen(*e.v) + len(crlf)
+}
+func (e integerItem) Decode(buf []byte) error {
+	x, _, err := readInteger(buf, e.prefix)
+	if err != nil {
+		return err
+	}
+	*e.v = x
+	return nil
+}
+
+// Encode v as a bulk string, like "$5\r\nhello\r\n". A nil v is encoded as the null bulk string,
+// "$-1\r\n", and decoding the null bulk string sets v to nil.
+func BulkString(v *[]byte) encode.Item {
+	return bulkStringItem{v}
+}
+
+type bulkStringItem struct{ v *[]byte }
+
+func (e bulkStringItem) Encode(buf []byte) {
+	putBulkString(buf, *e.v)
+}
+func (e bulkStringItem) Size() int {
+	return bulkStringSize(*e.v)
+}
+func (e bulkStringItem) Decode(buf []byte) error {
+	b, _, err := readBulkString(buf)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		*e.v = nil
+	} else {
+		*e.v = append([]byte{}, b...)
+	}
+	return nil
+}
+
+// Encode v as an array of bulk strings, which is how clients send commands to Redis:
+//
+//   [][]byte{[]byte("GET"), []byte("key")}
+//   *2\r\n$3\r\nGET\r\n$3\r\nkey\r\n
+//
+// Decode returns ErrType if any of the elements isn't a bulk string.
+func Command(v *[][]byte) encode.Item {
+	return commandItem{v}
+}
+
+type commandItem struct{ v *[][]byte }
+
+func (e commandItem) Encode(buf []byte) {
+	n := len(*e.v)
+	x := int64(n)
+	header := integerItem{prefix: prefixArray, v: &x}
+	header.Encode(buf)
+	i := header.Size()
+	for _, b := range *e.v {
+		i += putBulkString(buf[i:], b)
+	}
+}
+func (e commandItem) Size() int {
+	size := 1 + intLen(int64(len(*e.v))) + len(crlf)
+	for _, b := range *e.v {
+		size += bulkStringSize(b)
+	}
+	return size
+}
+func (e commandItem) Decode(buf []byte) error {
+	l, i, err := readInteger(buf, prefixArray)
+	if err != nil {
+		return err
+	}
+	if l < 0 {
+		return ErrSyntax
+	}
+	// Each element takes at least 5 bytes, so don't trust a length that couldn't possibly fit.
+	if l > int64(len(buf[i:])/5) {
+		return encode.ErrShortBuffer
+	}
+	out := make([][]byte, l)
+	for j := range out {
+		b, n, err := readBulkString(buf[i:])
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return ErrSyntax
+		}
+		out[j] = append([]byte{}, b...)
+		i += n
+	}
+	*e.v = out
+	return nil
+}
+
+func putBulkString(buf []byte, b []byte) int {
+	l := int64(len(b))
+	if b == nil {
+		l = -1
+	}
+	header := integerItem{prefix: prefixBulkString, v: &l}
+	header.Encode(buf)
+	n := header.Size()
+	if b == nil {
+		return n
+	}
+	n += copy(buf[n:], b)
+	n += copy(buf[n:], crlf)
+	return n
+}
+
+func bulkStringSize(b []byte) int {
+	if b == nil {
+		return 5
+	}
+	return 1 + intLen(int64(len(b))) + len(crlf) + len(b) + len(crlf)
+}
+
+// Returns the contents of the bulk string at the start of buf, nil for the null bulk string, and
+// its encoded size.
+func readBulkString(buf []byte) ([]byte, int, error) {
+	l, n, err := readInteger(buf, prefixBulkString)
+	if err != nil {
+		return nil, 0, err
+	}
+	if l == -1 {
+		return nil, n, nil
+	}
+	if l < 0 {
+		return nil, 0, ErrSyntax
+	}
+	if int64(len(buf[n:])) < l+int64(len(crlf)) {
+		return nil, 0, encode.ErrShortBuffer
+	}
+	end := n + int(l)
+	if !bytes.Equal(buf[end:end+len(crlf)], crlf) {
+		return nil, 0, ErrSyntax
+	}
+	return buf[n:end:end], end + len(crlf), nil
+}
+
+// Returns the line after prefix at the start of buf, without the CRLF, and its encoded size.
+func readLine(buf []byte, prefix byte) ([]byte, int, error) {
+	if len(buf) < 1 {
+		return nil, 0, encode.ErrShortBuffer
+	}
+	if buf[0] != prefix {
+		return nil, 0, ErrType
+	}
+	end := bytes.Index(buf, crlf)
+	if end < 0 {
+		if bytes.IndexByte(buf, '\n') >= 0 {
+			return nil, 0, ErrSyntax
+		}
+		return nil, 0, encode.ErrShortBuffer
+	}
+	line := buf[1:end]
+	if bytes.IndexByte(line, '\n') >= 0 || bytes.IndexByte(line, '\r') >= 0 {
+		return nil, 0, ErrSyntax
+	}
+	return line, end + len(crlf), nil
+}
+
+// Returns the integer on the line after prefix at the start of buf, and its encoded size.
+func readInteger(buf []byte, prefix byte) (int64, int, error) {
+	line, n, err := readLine(buf, prefix)
+	if err != nil {
+		return 0, 0, err
+	}
+	x, err := strconv.ParseInt(string(line), 10, 64)
+	if err != nil || intLen(x) != len(line) {
+		return 0, 0, ErrSyntax
+	}
+	return x, n, nil
+}
+
+func intLen(x int64) int {
+	var b [20]byte
+	return len(strconv.AppendInt(b[:0], x, 10))
+}
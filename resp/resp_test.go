@@ -0,0 +1,69 @@
+package resp
+
+import (
+	"io"
+	"testing"
+
+	"github.com/bradenaw/encode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoding(t *testing.T) {
+	s := "OK"
+	require.Equal(t, []byte("+OK\r\n"), encode.New(SimpleString(&s)).Encode())
+	var s2 string
+	require.NoError(t, encode.New(SimpleString(&s2)).Decode([]byte("+OK\r\n")))
+	require.Equal(t, s, s2)
+	require.ErrorIs(t, encode.New(Error(&s2)).Decode([]byte("+OK\r\n")), ErrType)
+	require.NoError(t, encode.New(Error(&s2)).Decode([]byte("-ERR bad\r\n")))
+	require.Equal(t, "ERR bad", s2)
+	s = "a\r\nb"
+	require.Panics(t, func() { encode.New(SimpleString(&s)).Encode() })
+
+	checkInt := func(x int64, expected string) {
+		require.Equal(t, []byte(expected), encode.New(Integer(&x)).Encode())
+		var x2 int64
+		require.NoError(t, encode.New(Integer(&x2)).Decode([]byte(expected)))
+		require.Equal(t, x, x2)
+	}
+	checkInt(0, ":0\r\n")
+	checkInt(1000, ":1000\r\n")
+	checkInt(-42, ":-42\r\n")
+	var x int64
+	for _, bad := range []string{":01\r\n", ":+1\r\n", ":\r\n", ":1\n", ":-0\r\n"} {
+		require.ErrorIs(t, encode.New(Integer(&x)).Decode([]byte(bad)), ErrSyntax, bad)
+	}
+	require.ErrorIs(t, encode.New(Integer(&x)).Decode([]byte(":12")), io.ErrUnexpectedEOF)
+
+	checkBulk := func(b []byte, expected string) {
+		require.Equal(t, []byte(expected), encode.New(BulkString(&b)).Encode())
+		b2 := []byte("garbage")
+		require.NoError(t, encode.New(BulkString(&b2)).Decode([]byte(expected)))
+		require.Equal(t, b == nil, b2 == nil)
+		require.Equal(t, b, b2)
+	}
+	checkBulk([]byte("hello"), "$5\r\nhello\r\n")
+	checkBulk([]byte{}, "$0\r\n\r\n")
+	checkBulk(nil, "$-1\r\n")
+	checkBulk([]byte("a\r\nb"), "$4\r\na\r\nb\r\n")
+	var b []byte
+	require.ErrorIs(t, encode.New(BulkString(&b)).Decode([]byte("$5\r\nhelloXX")), ErrSyntax)
+	require.ErrorIs(t, encode.New(BulkString(&b)).Decode([]byte("$5\r\nhel")), io.ErrUnexpectedEOF)
+
+	cmd := [][]byte{[]byte("SET"), []byte("key"), []byte("value")}
+	expected := []byte("*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nvalue\r\n")
+	require.Equal(t, expected, encode.New(Command(&cmd)).Encode())
+	var cmd2 [][]byte
+	require.NoError(t, encode.New(Command(&cmd2)).Decode(expected))
+	require.Equal(t, cmd, cmd2)
+	for i := 0; i < len(expected); i++ {
+		require.Error(t, encode.New(Command(&cmd2)).Decode(expected[:i]))
+	}
+	require.ErrorIs(t, encode.New(Command(&cmd2)).Decode([]byte("*1\r\n:12345\r\n")), ErrType)
+
+	n := int64(2)
+	key := []byte("k")
+	x = 7
+	enc := encode.New(ArrayHeader(&n), BulkString(&key), Integer(&x))
+	require.Equal(t, []byte("*2\r\n$1\r\nk\r\n:7\r\n"), enc.Encode())
+}
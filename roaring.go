@@ -0,0 +1,309 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+var ErrInvalidRoaring = NewInvalidValueError("encode: invalid roaring bitmap")
+var ErrRoaringRuns = NewInvalidValueError("encode: roaring run containers are not supported")
+
+const (
+	// The cookie that starts a roaring bitmap without run containers.
+	roaringCookie = 12346
+	// The cookie that starts one that may have them, in its low 16 bits.
+	roaringRunCookie = 12347
+	// Containers with more members than this are bitmaps, and others are arrays.
+	roaringMaxArray   = 4096
+	roaringBitmapSize = 8192
+)
+
+// Encode the set of uint32s v, which must be sorted in increasing order without duplicates, in the
+// portable serialization format of Roaring bitmaps, so that it can be exchanged with the Roaring
+// libraries for other languages. Large sparse sets, like posting lists, take much less space than
+// a bitmap or a list of every member, and IntersectRoaring finds the members two encoded sets have
+// in common without decoding either of them in full.
+//
+// The members are split by their upper 16 bits into containers, each written as a sorted array of
+// the lower 16 bits if there are at most 4096 of them, and as a bitmap otherwise. Decode returns
+// ErrRoaringRuns for bitmaps with run containers, which some libraries write after optimizing
+// them, and ErrInvalidRoaring for anything else that isn't in the format. Encode panics if v isn't
+// sorted or has duplicates.
+//
+// See https://github.com/RoaringBitmap/RoaringFormatSpec for the format.
+func Roaring(v *[]uint32) Item {
+	return roaring{v}
+}
+
+type roaring struct{ v *[]uint32 }
+
+// Calls f with each container of *e.v, given as its key and its members.
+func (e roaring) containers(f func(key uint16, members []uint32)) {
+	v := *e.v
+	for i := 1; i < len(v); i++ {
+		if v[i] <= v[i-1] {
+			panic(fmt.Sprintf("encode: roaring set must be sorted without duplicates, has %d "+
+				"after %d", v[i], v[i-1]))
+		}
+	}
+	for i := 0; i < len(v); {
+		j := i + 1
+		for j < len(v) && v[j]>>16 == v[i]>>16 {
+			j++
+		}
+		f(uint16(v[i]>>16), v[i:j])
+		i = j
+	}
+}
+
+func roaringContainerSize(card int) int {
+	if card > roaringMaxArray {
+		return roaringBitmapSize
+	}
+	return 2 * card
+}
+
+func (e roaring) Encode(buf []byte) {
+	n := 0
+	e.containers(func(uint16, []uint32) { n++ })
+	binary.LittleEndian.PutUint32(buf, roaringCookie)
+	binary.LittleEndian.PutUint32(buf[4:], uint32(n))
+	descriptive := buf[8:]
+	offsets := buf[8+4*n:]
+	pos := 8 + 8*n
+	i := 0
+	e.containers(func(key uint16, members []uint32) {
+		binary.LittleEndian.PutUint16(descriptive[4*i:], key)
+		binary.LittleEndian.PutUint16(descriptive[4*i+2:], uint16(len(members)-1))
+		binary.LittleEndian.PutUint32(offsets[4*i:], uint32(pos))
+		container := buf[pos : pos+roaringContainerSize(len(members))]
+		if len(members) > roaringMaxArray {
+			for _, x := range members {
+				low := x & 0xFFFF
+				container[low/8] |= 1 << (low % 8)
+			}
+		} else {
+			for j, x := range members {
+				binary.LittleEndian.PutUint16(container[2*j:], uint16(x))
+			}
+		}
+		pos += len(container)
+		i++
+	})
+}
+func (e roaring) Size() int {
+	size := 8
+	e.containers(func(_ uint16, members []uint32) {
+		size += 8 + roaringContainerSize(len(members))
+	})
+	return size
+}
+func (e roaring) kind() string {
+	return "Roaring"
+}
+func (e roaring) sizeBounds() (min, max int) {
+	return 8, -1
+}
+func (e roaring) skip(buf []byte) (int, error) {
+	r, err := parseRoaring(buf)
+	if err != nil {
+		return 0, err
+	}
+	return r.size, nil
+}
+func (e roaring) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e roaring) decodeLimited(buf []byte, s *decodeState) error {
+	r, err := parseRoaring(buf)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for i := 0; i < r.n; i++ {
+		total += r.card(i)
+	}
+	err = s.checkElements(uint64(total))
+	if err != nil {
+		return err
+	}
+	v := make([]uint32, 0, total)
+	for i := 0; i < r.n; i++ {
+		high := uint32(r.key(i)) << 16
+		r.each(i, func(low uint16) {
+			v = append(v, high|uint32(low))
+		})
+	}
+	*e.v = v
+	return nil
+}
+
+// IntersectRoaring returns the members that the sets encoded by Roaring in a and b have in common,
+// in increasing order. Only the containers whose keys appear in both are expanded, so this is much
+// faster than decoding both sets when they're large and their members are far apart.
+func IntersectRoaring(a []byte, b []byte) ([]uint32, error) {
+	ra, err := parseRoaring(a)
+	if err != nil {
+		return nil, fmt.Errorf("a: %w", err)
+	}
+	rb, err := parseRoaring(b)
+	if err != nil {
+		return nil, fmt.Errorf("b: %w", err)
+	}
+	var result []uint32
+	for i, j := 0, 0; i < ra.n && j < rb.n; {
+		keyA, keyB := ra.key(i), rb.key(j)
+		switch {
+		case keyA < keyB:
+			i++
+		case keyA > keyB:
+			j++
+		default:
+			result = intersectRoaringContainers(result, uint32(keyA)<<16, ra, i, rb, j)
+			i++
+			j++
+		}
+	}
+	return result, nil
+}
+
+func intersectRoaringContainers(
+	dst []uint32,
+	high uint32,
+	a parsedRoaring,
+	i int,
+	b parsedRoaring,
+	j int,
+) []uint32 {
+	ca, cb := a.container(i), b.container(j)
+	aIsBitmap, bIsBitmap := a.card(i) > roaringMaxArray, b.card(j) > roaringMaxArray
+	switch {
+	case aIsBitmap && bIsBitmap:
+		for w := 0; w < roaringBitmapSize; w += 8 {
+			word := binary.LittleEndian.Uint64(ca[w:]) & binary.LittleEndian.Uint64(cb[w:])
+			for ; word != 0; word &= word - 1 {
+				dst = append(dst, high|uint32(w*8+bits.TrailingZeros64(word)))
+			}
+		}
+	case aIsBitmap || bIsBitmap:
+		array, bitmap := ca, cb
+		if aIsBitmap {
+			array, bitmap = cb, ca
+		}
+		for k := 0; k < len(array); k += 2 {
+			low := binary.LittleEndian.Uint16(array[k:])
+			if bitmap[low/8]&(1<<(low%8)) != 0 {
+				dst = append(dst, high|uint32(low))
+			}
+		}
+	default:
+		for k, l := 0, 0; k < len(ca) && l < len(cb); {
+			x, y := binary.LittleEndian.Uint16(ca[k:]), binary.LittleEndian.Uint16(cb[l:])
+			switch {
+			case x < y:
+				k += 2
+			case x > y:
+				l += 2
+			default:
+				dst = append(dst, high|uint32(x))
+				k += 2
+				l += 2
+			}
+		}
+	}
+	return dst
+}
+
+// A roaring bitmap in a buffer, which parseRoaring has checked is valid.
+type parsedRoaring struct {
+	buf []byte
+	// The number of containers.
+	n int
+	// The size of the whole bitmap in bytes.
+	size int
+}
+
+func (r parsedRoaring) key(i int) uint16 {
+	return binary.LittleEndian.Uint16(r.buf[8+4*i:])
+}
+func (r parsedRoaring) card(i int) int {
+	return int(binary.LittleEndian.Uint16(r.buf[8+4*i+2:])) + 1
+}
+func (r parsedRoaring) container(i int) []byte {
+	offset := int(binary.LittleEndian.Uint32(r.buf[8+4*r.n+4*i:]))
+	return r.buf[offset : offset+roaringContainerSize(r.card(i))]
+}
+
+// Calls f with the lower 16 bits of each member of container i, in increasing order.
+func (r parsedRoaring) each(i int, f func(low uint16)) {
+	c := r.container(i)
+	if r.card(i) <= roaringMaxArray {
+		for k := 0; k < len(c); k += 2 {
+			f(binary.LittleEndian.Uint16(c[k:]))
+		}
+		return
+	}
+	for w := 0; w < len(c); w += 8 {
+		for word := binary.LittleEndian.Uint64(c[w:]); word != 0; word &= word - 1 {
+			f(uint16(w*8 + bits.TrailingZeros64(word)))
+		}
+	}
+}
+
+func parseRoaring(buf []byte) (parsedRoaring, error) {
+	if len(buf) < 8 {
+		return parsedRoaring{}, ErrShortBuffer
+	}
+	cookie := binary.LittleEndian.Uint32(buf)
+	if cookie&0xFFFF == roaringRunCookie {
+		return parsedRoaring{}, ErrRoaringRuns
+	}
+	if cookie != roaringCookie {
+		return parsedRoaring{}, ErrInvalidRoaring
+	}
+	count := binary.LittleEndian.Uint32(buf[4:])
+	if count > 1<<16 {
+		return parsedRoaring{}, ErrInvalidRoaring
+	}
+	n := int(count)
+	pos := 8 + 8*n
+	if len(buf) < pos {
+		return parsedRoaring{}, ErrShortBuffer
+	}
+	r := parsedRoaring{buf: buf, n: n}
+	for i := 0; i < n; i++ {
+		if i > 0 && r.key(i) <= r.key(i-1) {
+			return parsedRoaring{}, ErrInvalidRoaring
+		}
+		// Containers must directly follow each other, so that the size of the bitmap matches what
+		// Roaring.Size reports after decoding it.
+		if binary.LittleEndian.Uint32(buf[8+4*n+4*i:]) != uint32(pos) {
+			return parsedRoaring{}, ErrInvalidRoaring
+		}
+		card := r.card(i)
+		size := roaringContainerSize(card)
+		if len(buf[pos:]) < size {
+			return parsedRoaring{}, ErrShortBuffer
+		}
+		c := buf[pos : pos+size]
+		if card > roaringMaxArray {
+			count := 0
+			for w := 0; w < size; w += 8 {
+				count += bits.OnesCount64(binary.LittleEndian.Uint64(c[w:]))
+			}
+			if count != card {
+				return parsedRoaring{}, ErrInvalidRoaring
+			}
+		} else {
+			for k := 2; k < size; k += 2 {
+				if binary.LittleEndian.Uint16(c[k:]) <= binary.LittleEndian.Uint16(c[k-2:]) {
+					return parsedRoaring{}, ErrInvalidRoaring
+				}
+			}
+		}
+		pos += size
+	}
+	r.size = pos
+	return r, nil
+}
@@ -0,0 +1,116 @@
+package encode
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoaring(t *testing.T) {
+	var v []uint32
+	enc := New(Roaring(&v))
+
+	v = []uint32{1, 2, 0x10000}
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{
+			0x3A, 0x30, 0x00, 0x00, // cookie
+			0x02, 0x00, 0x00, 0x00, // containers
+			0x00, 0x00, 0x01, 0x00, // key 0, 2 members
+			0x01, 0x00, 0x00, 0x00, // key 1, 1 member
+			0x18, 0x00, 0x00, 0x00, // offsets
+			0x1C, 0x00, 0x00, 0x00,
+			0x01, 0x00, 0x02, 0x00,
+			0x00, 0x00,
+		},
+		buf,
+	)
+
+	sets := [][]uint32{
+		nil,
+		{0, 0xFFFFFFFF},
+		roaringRange(0, 5000, 1),
+		append(roaringRange(70000, 200000, 3), 1<<31),
+	}
+	for _, set := range sets {
+		v = set
+		buf := enc.Encode()
+		require.Len(t, buf, enc.EncodedSize())
+		v = []uint32{7}
+		require.NoError(t, enc.Decode(buf))
+		if len(set) == 0 {
+			require.Len(t, v, 0)
+		} else {
+			require.Equal(t, set, v)
+		}
+	}
+
+	shared := make([]uint32, 1, 10)
+	shared[0] = 7
+	v = shared
+	require.NoError(t, enc.Decode(New(Roaring(&[]uint32{1, 2, 3})).Encode()))
+	require.Equal(t, []uint32{1, 2, 3}, v)
+	require.Equal(t, []uint32{7, 0, 0}, shared[:3])
+
+	v = []uint32{2, 1}
+	require.Panics(t, func() { enc.Encode() })
+
+	v = roaringRange(0, 5000, 1)
+	buf = enc.Encode()
+	buf[len(buf)-1] = 0xFF
+	require.ErrorIs(t, enc.Decode(buf), ErrInvalidRoaring)
+	require.ErrorIs(t, enc.Decode(buf[:100]), ErrShortBuffer)
+	runs := append([]byte(nil), buf...)
+	binary.LittleEndian.PutUint32(runs, roaringRunCookie)
+	require.ErrorIs(t, enc.Decode(runs), ErrRoaringRuns)
+
+	v = roaringRange(0, 5000, 1)
+	require.ErrorIs(t, enc.DecodeLimited(enc.Encode(), Limits{MaxElements: 10}), ErrLimitExceeded)
+}
+
+func TestIntersectRoaring(t *testing.T) {
+	encodeSet := func(v []uint32) []byte { return New(Roaring(&v)).Encode() }
+	sets := [][]uint32{
+		{3, 5, 7, 70000, 1 << 20},
+		roaringRange(0, 10000, 2),
+		roaringRange(0, 300000, 3),
+		roaringRange(65536, 75000, 1),
+	}
+	for _, a := range sets {
+		for _, b := range sets {
+			got, err := IntersectRoaring(encodeSet(a), encodeSet(b))
+			require.NoError(t, err)
+			require.Equal(t, intersectSorted(a, b), got)
+		}
+	}
+
+	_, err := IntersectRoaring([]byte{0x00}, encodeSet(nil))
+	require.ErrorIs(t, err, ErrShortBuffer)
+}
+
+func roaringRange(start, end, step uint32) []uint32 {
+	var v []uint32
+	for x := start; x < end; x += step {
+		v = append(v, x)
+	}
+	return v
+}
+
+func intersectSorted(a, b []uint32) []uint32 {
+	var result []uint32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
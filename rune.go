@@ -0,0 +1,54 @@
+package encode
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+var ErrInvalidUTF8 = NewInvalidValueError("encode: invalid UTF-8")
+
+// Encode v as UTF-8, taking 1 to 4 bytes. UTF-8 sorts the same way as the code points it
+// encodes, so this preserves ordering.
+//
+// Panics on encode if v isn't a valid code point, such as a surrogate half. Decode returns
+// ErrInvalidUTF8 if buf doesn't start with the valid UTF-8 encoding of a code point.
+func Rune(v *rune) TupleItem {
+	return runeItem{v}
+}
+
+type runeItem struct{ v *rune }
+
+func (e runeItem) EncodeTuple(buf []byte, last bool)       { e.Encode(buf) }
+func (e runeItem) DecodeTuple(buf []byte, last bool) error { return e.Decode(buf) }
+func (e runeItem) SizeTuple(last bool) int                 { return e.Size() }
+func (e runeItem) OrderPreserving()                        {}
+func (e runeItem) Encode(buf []byte) {
+	e.check()
+	utf8.EncodeRune(buf, *e.v)
+}
+func (e runeItem) Size() int {
+	e.check()
+	return utf8.RuneLen(*e.v)
+}
+func (e runeItem) kind() string {
+	return "Rune"
+}
+func (e runeItem) check() {
+	if !utf8.ValidRune(*e.v) {
+		panic(fmt.Sprintf("Rune: %U is not a valid code point", *e.v))
+	}
+}
+func (e runeItem) sizeBounds() (min, max int) {
+	return 1, utf8.UTFMax
+}
+func (e runeItem) Decode(buf []byte) error {
+	if !utf8.FullRune(buf) {
+		return ErrShortBuffer
+	}
+	r, n := utf8.DecodeRune(buf)
+	if r == utf8.RuneError && n <= 1 {
+		return ErrInvalidUTF8
+	}
+	*e.v = r
+	return nil
+}
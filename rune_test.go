@@ -0,0 +1,33 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRune(t *testing.T) {
+	check := func(r rune, expected []byte) {
+		require.Equal(t, expected, New(Rune(&r)).Encode())
+		var r2 rune
+		require.NoError(t, New(Rune(&r2)).Decode(expected))
+		require.Equal(t, r, r2)
+	}
+	check('a', []byte{0x61})
+	check('é', []byte{0xc3, 0xa9})
+	check('€', []byte{0xe2, 0x82, 0xac})
+	check('😀', []byte{0xf0, 0x9f, 0x98, 0x80})
+	check('�', []byte{0xef, 0xbf, 0xbd})
+
+	var r rune
+	require.ErrorIs(t, New(Rune(&r)).Decode(nil), io.ErrUnexpectedEOF)
+	require.ErrorIs(t, New(Rune(&r)).Decode([]byte{0xe2, 0x82}), io.ErrUnexpectedEOF)
+	require.ErrorIs(t, New(Rune(&r)).Decode([]byte{0xff}), ErrInvalidUTF8)
+	require.ErrorIs(t, New(Rune(&r)).Decode([]byte{0xc0, 0x80}), ErrInvalidUTF8)
+	// Surrogate half.
+	require.ErrorIs(t, New(Rune(&r)).Decode([]byte{0xed, 0xa0, 0x80}), ErrInvalidUTF8)
+
+	r = 0xD800
+	require.Panics(t, func() { New(Rune(&r)).Encode() })
+}
@@ -0,0 +1,104 @@
+package encode
+
+import "encoding/binary"
+
+var ErrInvalidRunLength = NewInvalidValueError("encode: invalid run-length encoding")
+
+// Encode v as a uvarint of v's length, followed by each run of repeated bytes in v as a uvarint of
+// the run's length and then the repeated byte:
+//
+//   []byte{0, 0, 0, 0, 0, 1, 1, 0}
+//   08 05 00 02 01 01 00
+//
+// This is much smaller than LengthDelimBytes for payloads dominated by long runs, like occupancy
+// bitmaps, but up to twice as large for payloads that aren't. Decode returns ErrInvalidRunLength
+// if a run is empty, if two consecutive runs repeat the same byte, or if the runs don't add up to
+// the length. Since a small encoding can expand to a very large slice, use DecodeLimited with a
+// MaxLength for untrusted input.
+func RunLengthBytes(v *[]byte) Item {
+	return runLengthBytes{v}
+}
+
+type runLengthBytes struct{ v *[]byte }
+
+func (e runLengthBytes) Encode(buf []byte) {
+	v := *e.v
+	n := binary.PutUvarint(buf, uint64(len(v)))
+	for i := 0; i < len(v); {
+		j := nextRun(v, i)
+		n += binary.PutUvarint(buf[n:], uint64(j-i))
+		buf[n] = v[i]
+		n++
+		i = j
+	}
+}
+func (e runLengthBytes) Size() int {
+	v := *e.v
+	size := uvarintSize(uint64(len(v)))
+	for i := 0; i < len(v); {
+		j := nextRun(v, i)
+		size += uvarintSize(uint64(j-i)) + 1
+		i = j
+	}
+	return size
+}
+func (e runLengthBytes) kind() string {
+	return "RunLengthBytes"
+}
+func (e runLengthBytes) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e runLengthBytes) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e runLengthBytes) decodeLimited(buf []byte, s *decodeState) error {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	err := s.checkLength(l)
+	if err != nil {
+		return err
+	}
+	// Don't trust l for the allocation up front, since it's cheap to claim a huge length.
+	c := uint64(len(buf))
+	if l < c {
+		c = l
+	}
+	out := make([]byte, 0, c)
+	for uint64(len(out)) < l {
+		run, m := binary.Uvarint(buf[n:])
+		if m == 0 {
+			return ErrShortBuffer
+		}
+		if m < 0 {
+			return ErrVarintOverflow
+		}
+		n += m
+		if n >= len(buf) {
+			return ErrShortBuffer
+		}
+		b := buf[n]
+		n++
+		if run == 0 || run > l-uint64(len(out)) || (len(out) > 0 && out[len(out)-1] == b) {
+			return ErrInvalidRunLength
+		}
+		for k := uint64(0); k < run; k++ {
+			out = append(out, b)
+		}
+	}
+	*e.v = out
+	return nil
+}
+
+// Returns the index just past the end of the run starting at v[i].
+func nextRun(v []byte, i int) int {
+	j := i + 1
+	for j < len(v) && v[j] == v[i] {
+		j++
+	}
+	return j
+}
@@ -0,0 +1,49 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLengthBytes(t *testing.T) {
+	v := []byte{0, 0, 0, 0, 0, 1, 1, 0}
+	enc := New(RunLengthBytes(&v))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x08, 0x05, 0x00, 0x02, 0x01, 0x01, 0x00}, buf)
+
+	var v2 []byte
+	require.NoError(t, New(RunLengthBytes(&v2)).Decode(buf))
+	require.Equal(t, v, v2)
+	for i := 0; i < len(buf); i++ {
+		require.ErrorIs(t, New(RunLengthBytes(&v2)).Decode(buf[:i]), io.ErrUnexpectedEOF)
+	}
+
+	v = make([]byte, 1000)
+	v[500] = 0xff
+	buf = enc.Encode()
+	require.Len(t, buf, 10)
+	require.NoError(t, New(RunLengthBytes(&v2)).Decode(buf))
+	require.Equal(t, v, v2)
+
+	v = []byte{}
+	require.Equal(t, []byte{0x00}, enc.Encode())
+
+	for _, b := range [][]byte{
+		{0x02, 0x00, 0x00, 0x02, 0x00},
+		{0x02, 0x03, 0x00},
+		{0x02, 0x01, 0x00, 0x01, 0x00},
+	} {
+		require.ErrorIs(t, New(RunLengthBytes(&v2)).Decode(b), ErrInvalidRunLength)
+	}
+
+	require.ErrorIs(
+		t,
+		New(RunLengthBytes(&v2)).DecodeLimited(
+			[]byte{0xe8, 0x07, 0xe8, 0x07, 0x00},
+			Limits{MaxLength: 100},
+		),
+		ErrLimitExceeded,
+	)
+}
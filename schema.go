@@ -0,0 +1,97 @@
+package encode
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A Schema encodes and decodes values of type T. Unlike an Encoding, whose items are bound to the
+// fields of one value, a Schema is defined once in terms of fields, which return the Item for one
+// field of the value they're given, and can then be used for any number of values, for example:
+//
+//   type Point struct {
+//       X, Y uint64
+//   }
+//   var pointSchema = encode.NewSchema(
+//       func(p *Point) encode.Item { return encode.Uvarint64(&p.X) },
+//       func(p *Point) encode.Item { return encode.Uvarint64(&p.Y) },
+//   )
+//
+//   b := pointSchema.Encode(&Point{X: 1, Y: 2})
+//
+// The items are made afresh for each call, so a Schema can be used from many goroutines at once, as
+// long as they're working on different values. For the same reason, fields must return a new Item
+// every time rather than one made ahead of time, even for items like Align that don't refer to the
+// value, since some items keep state between Size and Encode.
+type Schema[T any] struct {
+	fields []func(v *T) Item
+}
+
+// Returns a Schema with the given fields. Panics if any of fields returns the same Item, holding
+// pointers, for two different values, since that Item would be shared between every use of the
+// Schema. This catches items made ahead of time and captured by fields, though not every way of
+// sharing state between calls.
+func NewSchema[T any](fields ...func(v *T) Item) Schema[T] {
+	a, b := new(T), new(T)
+	for i, field := range fields {
+		if sharedItem(field(a), field(b)) {
+			panic(fmt.Sprintf("field %d returned the same %T for different values", i, field(a)))
+		}
+	}
+	return Schema[T]{fields: fields}
+}
+
+// Returns true if a and b are the same Item and refer to something through a pointer, meaning
+// that any state they hold is shared.
+func sharedItem(a Item, b Item) (shared bool) {
+	// Comparing panics if an interface field holds something that isn't comparable, like a Group.
+	defer func() {
+		if recover() != nil {
+			shared = false
+		}
+	}()
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) || !t.Comparable() || !hasPointers(t) {
+		return false
+	}
+	return a == b
+}
+
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface, reflect.Slice,
+		reflect.UnsafePointer:
+		return true
+	case reflect.Array:
+		return hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Returns an Encoding bound to v, for using v with the rest of Encoding's methods.
+func (c Schema[T]) Encoding(v *T) Encoding {
+	items := make([]Item, len(c.fields))
+	for i, field := range c.fields {
+		items[i] = field(v)
+	}
+	return Encoding{items: items}
+}
+
+func (c Schema[T]) Encode(v *T) []byte {
+	return c.Encoding(v).Encode()
+}
+
+// Append the encoding of v to dst, returning the extended buffer, as in Encoding.Append.
+func (c Schema[T]) Append(dst []byte, v *T) []byte {
+	return c.Encoding(v).Append(dst)
+}
+
+func (c Schema[T]) Decode(buf []byte, v *T) error {
+	return c.Encoding(v).Decode(buf)
+}
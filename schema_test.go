@@ -0,0 +1,62 @@
+package encode
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	type point struct {
+		X, Y uint64
+		Name string
+	}
+	c := NewSchema(
+		func(p *point) Item { return Uvarint64(&p.X) },
+		func(p *point) Item { return Uvarint64(&p.Y) },
+		func(p *point) Item { return Align(4) },
+		func(p *point) Item { return LengthDelimString(&p.Name) },
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p := point{X: uint64(i), Y: uint64(j) << 10, Name: "p"}
+				b := c.Encode(&p)
+				enc := New(Uvarint64(&p.X), Uvarint64(&p.Y), Align(4), LengthDelimString(&p.Name))
+				require.Equal(t, enc.Encode(), b)
+				var p2 point
+				require.NoError(t, c.Decode(b, &p2))
+				require.Equal(t, p, p2)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSchemaSharedItem(t *testing.T) {
+	type record struct{ X uint32 }
+	var shared uint32
+	align := Align(8)
+	require.Panics(t, func() {
+		NewSchema(func(r *record) Item { return FixedUint32(&shared) })
+	})
+	require.Panics(t, func() {
+		NewSchema(
+			func(r *record) Item { return FixedUint32(&r.X) },
+			func(r *record) Item { return align },
+		)
+	})
+
+	// Items made from the value, or without pointers, are fine.
+	NewSchema(
+		func(r *record) Item { return FixedUint32(&r.X) },
+		func(r *record) Item { return Padding(4) },
+		func(r *record) Item { return Named("x", Group(FixedUint32(&r.X))) },
+	)
+}
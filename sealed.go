@@ -0,0 +1,111 @@
+package encode
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+)
+
+var ErrSealedAuthentication = NewInvalidValueError("encode: sealed item failed authentication")
+
+// Encode item, then encrypt and authenticate it using aead, so that secrets can be embedded in an
+// otherwise plaintext encoding. Written as a uvarint of the sealed length, followed by a random
+// nonce and then the ciphertext. A fresh nonce is drawn from crypto/rand on every Encode.
+//
+// Decode returns ErrSealedAuthentication if the ciphertext was tampered with or sealed with a
+// different key.
+//
+// Usually aead is AES-GCM:
+//
+//   block, err := aes.NewCipher(key)
+//   if err != nil {
+//   	return err
+//   }
+//   aead, err := cipher.NewGCM(block)
+//   if err != nil {
+//   	return err
+//   }
+//   enc := encode.New(
+//   	encode.FixedUint64(&e.id),
+//   	encode.Sealed(encode.LengthDelimString(&e.secret), aead),
+//   )
+func Sealed(item Item, aead cipher.AEAD) Item {
+	return sealed{item: item, aead: aead}
+}
+
+type sealed struct {
+	item Item
+	aead cipher.AEAD
+}
+
+func (e sealed) setOffset(offset int) {
+	// item is encoded on its own before sealing.
+	setOffset(e.item, 0)
+}
+func (e sealed) sealedSize() int {
+	return e.aead.NonceSize() + e.item.Size() + e.aead.Overhead()
+}
+func (e sealed) Encode(buf []byte) {
+	sealedSize := e.sealedSize()
+	n := binary.PutUvarint(buf, uint64(sealedSize))
+	nonce := buf[n : n+e.aead.NonceSize()]
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		panic(err)
+	}
+	plaintext := make([]byte, e.item.Size())
+	e.item.Encode(plaintext)
+	e.aead.Seal(nonce[len(nonce):len(nonce)], nonce, plaintext, nil)
+}
+func (e sealed) Size() int {
+	sealedSize := e.sealedSize()
+	return uvarintSize(uint64(sealedSize)) + sealedSize
+}
+func (e sealed) kind() string {
+	return "Sealed"
+}
+func (e sealed) withByteOrder(order binary.ByteOrder) Item {
+	return sealed{item: withByteOrder(e.item, order), aead: e.aead}
+}
+func (e sealed) sizeBounds() (min, max int) {
+	overhead := e.aead.NonceSize() + e.aead.Overhead()
+	itemMin, _ := sizeBounds(e.item)
+	return uvarintSize(uint64(overhead+itemMin)) + overhead + itemMin, -1
+}
+func (e sealed) dependsOnEarlier() bool {
+	return dependsOnEarlier(e.item)
+}
+func (e sealed) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e sealed) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e sealed) decodeLimited(buf []byte, s *decodeState) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	if uint64(len(buf[n:])) < l {
+		return ErrShortBuffer
+	}
+	if l < uint64(e.aead.NonceSize()+e.aead.Overhead()) {
+		return ErrSealedAuthentication
+	}
+	block := buf[n : n+int(l)]
+	nonce := block[:e.aead.NonceSize()]
+	plaintext, err := e.aead.Open(nil, nonce, block[len(nonce):], nil)
+	if err != nil {
+		return ErrSealedAuthentication
+	}
+	return decodeItem(e.item, plaintext, s)
+}
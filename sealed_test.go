@@ -0,0 +1,37 @@
+package encode
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealed(t *testing.T) {
+	newAEAD := func(key byte) cipher.AEAD {
+		block, err := aes.NewCipher(append(make([]byte, 31), key))
+		require.NoError(t, err)
+		aead, err := cipher.NewGCM(block)
+		require.NoError(t, err)
+		return aead
+	}
+
+	id := uint64(12345)
+	secret := [16]byte{0xDE, 0xAD, 0xBE, 0xEF}
+	b := New(FixedUint64(&id), Sealed(Bytes16(&secret), newAEAD(1))).Encode()
+	require.NotEqual(t, b, New(FixedUint64(&id), Sealed(Bytes16(&secret), newAEAD(1))).Encode())
+
+	var id2 uint64
+	var secret2 [16]byte
+	require.NoError(t, New(FixedUint64(&id2), Sealed(Bytes16(&secret2), newAEAD(1))).Decode(b))
+	require.Equal(t, id, id2)
+	require.Equal(t, secret, secret2)
+
+	err := New(FixedUint64(&id2), Sealed(Bytes16(&secret2), newAEAD(2))).Decode(b)
+	require.ErrorIs(t, err, ErrSealedAuthentication)
+
+	b[len(b)-1] ^= 0x01
+	err = New(FixedUint64(&id2), Sealed(Bytes16(&secret2), newAEAD(1))).Decode(b)
+	require.ErrorIs(t, err, ErrSealedAuthentication)
+}
@@ -0,0 +1,63 @@
+package encode
+
+import "fmt"
+
+var ErrShortStringTooLong = NewInvalidValueError("encode: short string length exceeds its capacity")
+
+// Encode v as a byte of its length followed by a field of exactly capacity bytes holding v and then
+// zero padding, like Pascal-style strings and many fixed-layout embedded formats:
+//
+//   "hi", 4
+//   02 68 69 00 00
+//
+// The padding is ignored on decode. Panics on encode if v is longer than capacity. Decode returns
+// ErrShortStringTooLong if the length byte is greater than capacity. Panics if capacity is not in
+// [0, 255].
+func ShortString(v *string, capacity int) Item {
+	if capacity < 0 || capacity > 255 {
+		panic(fmt.Sprintf("invalid capacity=%d, must be in [0, 255]", capacity))
+	}
+	return shortString{v: v, capacity: capacity}
+}
+
+type shortString struct {
+	v        *string
+	capacity int
+}
+
+func (e shortString) Encode(buf []byte) {
+	if len(*e.v) > e.capacity {
+		panic(fmt.Sprintf("ShortString: %q is longer than capacity %d", *e.v, e.capacity))
+	}
+	buf[0] = byte(len(*e.v))
+	n := 1 + copy(buf[1:], *e.v)
+	for i := n; i < e.Size(); i++ {
+		buf[i] = 0
+	}
+}
+func (e shortString) Size() int {
+	return 1 + e.capacity
+}
+func (e shortString) kind() string {
+	return "ShortString"
+}
+func (e shortString) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e shortString) fixedSize() int {
+	return e.Size()
+}
+func (e shortString) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e shortString) decodeLimited(buf []byte, s *decodeState) error {
+	if len(buf) < e.Size() {
+		return ErrShortBuffer
+	}
+	l := int(buf[0])
+	if l > e.capacity {
+		return ErrShortStringTooLong
+	}
+	*e.v = s.string(buf[1 : 1+l])
+	return nil
+}
@@ -0,0 +1,37 @@
+package encode
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortString(t *testing.T) {
+	s := "hi"
+	x := byte(0xff)
+	enc := New(ShortString(&s, 4), Byte(&x))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x02, 'h', 'i', 0x00, 0x00, 0xff}, buf)
+
+	var s2 string
+	var x2 byte
+	enc2 := New(ShortString(&s2, 4), Byte(&x2))
+	require.NoError(t, enc2.Decode(buf))
+	require.Equal(t, s, s2)
+	require.Equal(t, x, x2)
+	require.True(t, enc2.IsFixedSize())
+
+	require.NoError(t, New(ShortString(&s2, 4)).Decode([]byte{0x04, 'a', 'b', 'c', 'd'}))
+	require.Equal(t, "abcd", s2)
+	require.ErrorIs(t, New(ShortString(&s2, 4)).Decode([]byte{0x02, 'h', 'i'}), io.ErrUnexpectedEOF)
+	require.ErrorIs(
+		t,
+		New(ShortString(&s2, 4)).Decode([]byte{0x05, 'a', 'b', 'c', 'd'}),
+		ErrShortStringTooLong,
+	)
+
+	s = "hello"
+	require.Panics(t, func() { enc.Encode() })
+	require.Panics(t, func() { ShortString(&s, 256) })
+}
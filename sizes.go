@@ -0,0 +1,69 @@
+package encode
+
+// EncodedSize returns the number of bytes that Encode would produce for enc's current values,
+// without encoding them, for example to enforce a limit on message size or to size a buffer ahead
+// of time. Items whose size is costly to find, like Compressed, cache their work for a following
+// Encode.
+func (enc Encoding) EncodedSize() int {
+	return sizeItems(enc.items, 0)
+}
+
+// IsFixedSize returns true if every encoding of enc is the same size, no matter the values of the
+// items, so that for example records can be laid out in an array and found by index.
+func (enc Encoding) IsFixedSize() bool {
+	max, ok := enc.MaxSize()
+	return ok && max == enc.MinSize()
+}
+
+// MinSize returns the size of the smallest encoding of enc, and the smallest buffer Decode could
+// accept.
+func (enc Encoding) MinSize() int {
+	min, _ := sizeBounds(Group(enc.items...))
+	return min
+}
+
+// MaxSize returns the size of the largest encoding of enc, or false if enc's size is unbounded
+// because it contains variable-length items like LengthDelimBytes.
+func (enc Encoding) MaxSize() (int, bool) {
+	_, max := sizeBounds(Group(enc.items...))
+	return max, max >= 0
+}
+
+// Implemented by items that can report bounds on their size without looking at their values. max
+// is -1 if the item can be arbitrarily large. Items that implement fixedItem don't also need to
+// implement this.
+type boundedItem interface {
+	sizeBounds() (min, max int)
+}
+
+func sizeBounds(item Item) (min, max int) {
+	if f, ok := item.(fixedItem); ok {
+		n := f.fixedSize()
+		return n, n
+	}
+	if b, ok := item.(boundedItem); ok {
+		return b.sizeBounds()
+	}
+	return 0, -1
+}
+
+// Returns a+b, where either may be -1 for unbounded.
+func addMaxSize(a, b int) int {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	return a + b
+}
+
+// Returns bounds that cover both of the given bounds. min1 may be -1 before there are any bounds
+// to cover.
+func unionSizeBounds(min1, max1, min2, max2 int) (min, max int) {
+	min, max = min1, max1
+	if min < 0 || min2 < min {
+		min = min2
+	}
+	if max >= 0 && (max2 < 0 || max2 > max) {
+		max = max2
+	}
+	return min, max
+}
@@ -0,0 +1,50 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeBounds(t *testing.T) {
+	check := func(enc Encoding, min int, max int, bounded bool) {
+		t.Helper()
+		require.Equal(t, min, enc.MinSize())
+		actualMax, actualBounded := enc.MaxSize()
+		require.Equal(t, bounded, actualBounded)
+		if bounded {
+			require.Equal(t, max, actualMax)
+		}
+		require.Equal(t, bounded && min == max, enc.IsFixedSize())
+	}
+
+	var a uint32
+	var b uint64
+	var c []byte
+	var cond bool
+	var tag uint8
+	var version uint64
+	var x, y bool
+
+	check(New(), 0, 0, true)
+	check(New(FixedUint32(&a), Padding(3), Bitpacked(Bit(&x), Bit(&y))), 8, 8, true)
+	check(New(FixedUint32(&a), Uvarint64(&b)), 5, 14, true)
+	check(New(FixedUint32(&a), LengthDelimBytes(&c)), 5, 0, false)
+	check(New(Group(FixedUint32(&a), Align(8))), 4, 11, true)
+	check(New(If(&cond, FixedUint32(&a))), 0, 4, true)
+	check(New(Switch(&tag, map[uint8]Item{0: FixedUint32(&a), 1: Uvarint64(&b)})), 1, 10, true)
+	check(New(Switch(&tag, map[uint8]Item{0: FixedUint32(&a), 1: LengthDelimBytes(&c)})), 1, 0, false)
+	check(New(Versioned(&version, map[uint64]Item{1: FixedUint32(&a), 300: Bool(&x)})), 3, 5, true)
+	check(New(Trailing(FixedUint32(&a), func() {})), 0, 4, true)
+}
+
+func TestEncodedSize(t *testing.T) {
+	var a uint32
+	var b []byte
+	enc := New(FixedUint32(&a), LengthDelimBytes(&b), Align(8))
+	require.Equal(t, 0, New().EncodedSize())
+	for _, l := range []int{0, 3, 200} {
+		b = make([]byte, l)
+		require.Equal(t, len(enc.Encode()), enc.EncodedSize())
+	}
+}
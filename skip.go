@@ -0,0 +1,55 @@
+package encode
+
+import (
+	"encoding/binary"
+)
+
+// Skip over a length-delimited field, in the same format as LengthDelimBytes, on decode without
+// copying or keeping it. Useful for reading only the fields of interest from a foreign format.
+//
+// Encodes as a field of zeroes the same length as the one last skipped, so that an encoding that's
+// decoded and then re-encoded keeps its layout, or as an empty field if nothing has been skipped.
+func SkipLengthDelim() Item {
+	return skipLengthDelimItem{n: new(int)}
+}
+
+type skipLengthDelimItem struct {
+	// The length of the last field skipped, not including its length prefix.
+	n *int
+}
+
+func (e skipLengthDelimItem) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(*e.n))
+	for i := n; i < n+*e.n; i++ {
+		buf[i] = 0
+	}
+}
+func (e skipLengthDelimItem) Size() int {
+	return uvarintSize(uint64(*e.n)) + *e.n
+}
+func (e skipLengthDelimItem) kind() string {
+	return "SkipLengthDelim"
+}
+func (e skipLengthDelimItem) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e skipLengthDelimItem) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e skipLengthDelimItem) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e skipLengthDelimItem) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
+	}
+	*e.n = len(b)
+	return nil
+}
+
+// Skip over n bytes on decode, and write n zeroes on encode. This is the same as Padding, but reads
+// better where the bytes are a field being ignored rather than unused space.
+func SkipFixed(n int) TupleItem {
+	return padding{n: n}
+}
@@ -0,0 +1,29 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkip(t *testing.T) {
+	var a uint16
+	var b bool
+	enc := New(SkipLengthDelim(), FixedUint16(&a), SkipFixed(3), Bool(&b))
+
+	buf := []byte{0x03, 'f', 'o', 'o', 0x01, 0x02, 0xAA, 0xBB, 0xCC, 0x01}
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint16(0x0102), a)
+	require.True(t, b)
+	require.Equal(t, len(buf), enc.EncodedSize())
+	require.Equal(t, []byte{0x03, 0, 0, 0, 0x01, 0x02, 0, 0, 0, 0x01}, enc.Encode())
+
+	require.ErrorIs(t, enc.Decode([]byte{0x05, 'f', 'o'}), ErrShortBuffer)
+
+	a, b = 7, false
+	require.Equal(
+		t,
+		[]byte{0x00, 0x00, 0x07, 0, 0, 0, 0x00},
+		New(SkipLengthDelim(), FixedUint16(&a), SkipFixed(3), Bool(&b)).Encode(),
+	)
+}
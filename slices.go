@@ -0,0 +1,425 @@
+package encode
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Encode v as a uvarint of its length, followed by each element in big endian order, taking 8
+// bytes each. WithByteOrder changes the order of the elements.
+func FixedUint64s(v *[]uint64) Item {
+	return fixedUint64s{v: v, order: binary.BigEndian}
+}
+
+// Encode v as a uvarint of its length, followed by each element in big endian order, taking 4
+// bytes each. WithByteOrder changes the order of the elements.
+func FixedUint32s(v *[]uint32) Item {
+	return fixedUint32s{v: v, order: binary.BigEndian}
+}
+
+// Encode v as a uvarint of its length, followed by each element as a uvarint. This is smaller than
+// FixedUint64s when most of the elements are small.
+func Uvarint64s(v *[]uint64) Item {
+	return uvarint64s{v}
+}
+
+// Encode v as a uvarint of its length, followed by each element as a uvarint. Decode returns
+// ErrVarintOverflow if an element doesn't fit in a uint32.
+func Uvarint32s(v *[]uint32) Item {
+	return uvarint32s{v}
+}
+
+type fixedUint64s struct {
+	v     *[]uint64
+	order binary.ByteOrder
+}
+
+func (e fixedUint64s) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	for _, x := range *e.v {
+		e.order.PutUint64(buf[n:], x)
+		n += 8
+	}
+}
+func (e fixedUint64s) Size() int {
+	return uvarintSize(uint64(len(*e.v))) + 8*len(*e.v)
+}
+func (e fixedUint64s) kind() string {
+	return "FixedUint64s"
+}
+func (e fixedUint64s) withByteOrder(order binary.ByteOrder) Item {
+	return fixedUint64s{v: e.v, order: order}
+}
+func (e fixedUint64s) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e fixedUint64s) skip(buf []byte) (int, error) {
+	return skipFixedElements(buf, 8)
+}
+func (e fixedUint64s) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e fixedUint64s) decodeLimited(buf []byte, s *decodeState) error {
+	l, n, err := readFixedElements(buf, 8, s)
+	if err != nil {
+		return err
+	}
+	out := make([]uint64, l)
+	for i := range out {
+		out[i] = e.order.Uint64(buf[n+8*i:])
+	}
+	*e.v = out
+	return nil
+}
+
+type fixedUint32s struct {
+	v     *[]uint32
+	order binary.ByteOrder
+}
+
+func (e fixedUint32s) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	for _, x := range *e.v {
+		e.order.PutUint32(buf[n:], x)
+		n += 4
+	}
+}
+func (e fixedUint32s) Size() int {
+	return uvarintSize(uint64(len(*e.v))) + 4*len(*e.v)
+}
+func (e fixedUint32s) kind() string {
+	return "FixedUint32s"
+}
+func (e fixedUint32s) withByteOrder(order binary.ByteOrder) Item {
+	return fixedUint32s{v: e.v, order: order}
+}
+func (e fixedUint32s) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e fixedUint32s) skip(buf []byte) (int, error) {
+	return skipFixedElements(buf, 4)
+}
+func (e fixedUint32s) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e fixedUint32s) decodeLimited(buf []byte, s *decodeState) error {
+	l, n, err := readFixedElements(buf, 4, s)
+	if err != nil {
+		return err
+	}
+	out := make([]uint32, l)
+	for i := range out {
+		out[i] = e.order.Uint32(buf[n+4*i:])
+	}
+	*e.v = out
+	return nil
+}
+
+// Reads the length at the start of buf of a slice whose elements take width bytes each, and checks
+// that they're all in buf. Returns the length and the size of the length itself.
+func readFixedElements(buf []byte, width int, s *decodeState) (int, int, error) {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, 0, ErrShortBuffer
+	}
+	if n < 0 {
+		return 0, 0, ErrVarintOverflow
+	}
+	err := s.checkElements(l)
+	if err != nil {
+		return 0, 0, err
+	}
+	if uint64(len(buf[n:]))/uint64(width) < l {
+		return 0, 0, ErrShortBuffer
+	}
+	return int(l), n, nil
+}
+
+func skipFixedElements(buf []byte, width int) (int, error) {
+	l, n, err := readFixedElements(buf, width, nil)
+	if err != nil {
+		return 0, err
+	}
+	return n + l*width, nil
+}
+
+type uvarint64s struct{ v *[]uint64 }
+
+func (e uvarint64s) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	for _, x := range *e.v {
+		n += binary.PutUvarint(buf[n:], x)
+	}
+}
+func (e uvarint64s) Size() int {
+	size := uvarintSize(uint64(len(*e.v)))
+	for _, x := range *e.v {
+		size += uvarintSize(x)
+	}
+	return size
+}
+func (e uvarint64s) kind() string {
+	return "Uvarint64s"
+}
+func (e uvarint64s) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e uvarint64s) skip(buf []byte) (int, error) {
+	return skipUvarintElements(buf, math.MaxUint64)
+}
+func (e uvarint64s) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e uvarint64s) decodeLimited(buf []byte, s *decodeState) error {
+	l, n, err := readUvarintElementsLength(buf, s)
+	if err != nil {
+		return err
+	}
+	out := make([]uint64, l)
+	for i := range out {
+		x, m, err := readUvarintElement(buf[n:], math.MaxUint64)
+		if err != nil {
+			return err
+		}
+		out[i] = x
+		n += m
+	}
+	*e.v = out
+	return nil
+}
+
+type uvarint32s struct{ v *[]uint32 }
+
+func (e uvarint32s) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	for _, x := range *e.v {
+		n += binary.PutUvarint(buf[n:], uint64(x))
+	}
+}
+func (e uvarint32s) Size() int {
+	size := uvarintSize(uint64(len(*e.v)))
+	for _, x := range *e.v {
+		size += uvarintSize(uint64(x))
+	}
+	return size
+}
+func (e uvarint32s) kind() string {
+	return "Uvarint32s"
+}
+func (e uvarint32s) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e uvarint32s) skip(buf []byte) (int, error) {
+	return skipUvarintElements(buf, math.MaxUint32)
+}
+func (e uvarint32s) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e uvarint32s) decodeLimited(buf []byte, s *decodeState) error {
+	l, n, err := readUvarintElementsLength(buf, s)
+	if err != nil {
+		return err
+	}
+	out := make([]uint32, l)
+	for i := range out {
+		x, m, err := readUvarintElement(buf[n:], math.MaxUint32)
+		if err != nil {
+			return err
+		}
+		out[i] = uint32(x)
+		n += m
+	}
+	*e.v = out
+	return nil
+}
+
+// Reads the length at the start of buf of a slice of uvarints. Returns the length and the size of
+// the length itself.
+func readUvarintElementsLength(buf []byte, s *decodeState) (int, int, error) {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, 0, ErrShortBuffer
+	}
+	if n < 0 {
+		return 0, 0, ErrVarintOverflow
+	}
+	err := s.checkElements(l)
+	if err != nil {
+		return 0, 0, err
+	}
+	// Every element takes at least one byte, so don't trust a length that couldn't possibly fit.
+	if uint64(len(buf[n:])) < l {
+		return 0, 0, ErrShortBuffer
+	}
+	return int(l), n, nil
+}
+
+func readUvarintElement(buf []byte, max uint64) (uint64, int, error) {
+	x, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, 0, ErrShortBuffer
+	}
+	if n < 0 || x > max {
+		return 0, 0, ErrVarintOverflow
+	}
+	return x, n, nil
+}
+
+func skipUvarintElements(buf []byte, max uint64) (int, error) {
+	l, n, err := readUvarintElementsLength(buf, nil)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < l; i++ {
+		_, m, err := readUvarintElement(buf[n:], max)
+		if err != nil {
+			return 0, err
+		}
+		n += m
+	}
+	return n, nil
+}
+
+// Encode v as a uvarint of its length, followed by each element as in LengthDelimString.
+func Strings(v *[]string) Item {
+	return stringsItem{v}
+}
+
+type stringsItem struct{ v *[]string }
+
+func (e stringsItem) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	for _, x := range *e.v {
+		n += binary.PutUvarint(buf[n:], uint64(len(x)))
+		n += copy(buf[n:], x)
+	}
+}
+func (e stringsItem) Size() int {
+	size := uvarintSize(uint64(len(*e.v)))
+	for _, x := range *e.v {
+		size += uvarintSize(uint64(len(x))) + len(x)
+	}
+	return size
+}
+func (e stringsItem) kind() string {
+	return "Strings"
+}
+func (e stringsItem) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e stringsItem) skip(buf []byte) (int, error) {
+	l, n, err := readUvarintElementsLength(buf, nil)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < l; i++ {
+		m, err := skipLengthDelim(buf[n:])
+		if err != nil {
+			return 0, err
+		}
+		n += m
+	}
+	return n, nil
+}
+func (e stringsItem) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e stringsItem) decodeLimited(buf []byte, s *decodeState) error {
+	l, n, err := readUvarintElementsLength(buf, s)
+	if err != nil {
+		return err
+	}
+	out := make([]string, l)
+	for i := range out {
+		m, err := skipLengthDelim(buf[n:])
+		if err != nil {
+			return err
+		}
+		b, err := readLengthDelim(buf[n:n+m], s)
+		if err != nil {
+			return err
+		}
+		out[i] = s.string(b)
+		n += m
+	}
+	*e.v = out
+	return nil
+}
+
+// Encode v as a uvarint of its length, followed by the bools packed 8 to a byte, with (*v)[i] in
+// bit i%8 of byte i/8, as in BoolBitSet. Decode returns ErrInvalidBitSet if any of the unused bits
+// of the last byte are set.
+func Bools(v *[]bool) Item {
+	return boolsItem{v}
+}
+
+type boolsItem struct{ v *[]bool }
+
+func (e boolsItem) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	packed := buf[n : n+(len(*e.v)+7)/8]
+	for i := range packed {
+		packed[i] = 0
+	}
+	for i, x := range *e.v {
+		if x {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+}
+func (e boolsItem) Size() int {
+	return uvarintSize(uint64(len(*e.v))) + (len(*e.v)+7)/8
+}
+func (e boolsItem) kind() string {
+	return "Bools"
+}
+func (e boolsItem) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e boolsItem) skip(buf []byte) (int, error) {
+	_, n, packed, err := readBools(buf, nil)
+	return n + len(packed), err
+}
+func (e boolsItem) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e boolsItem) decodeLimited(buf []byte, s *decodeState) error {
+	l, _, packed, err := readBools(buf, s)
+	if err != nil {
+		return err
+	}
+	out := make([]bool, l)
+	for i := range out {
+		out[i] = packed[i/8]&(1<<uint(i%8)) != 0
+	}
+	*e.v = out
+	return nil
+}
+
+// Reads the length at the start of buf of an encoding from Bools. Returns the length, the size of
+// the length itself, and the packed bools that follow it.
+func readBools(buf []byte, s *decodeState) (int, int, []byte, error) {
+	l, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, 0, nil, ErrShortBuffer
+	}
+	if n < 0 {
+		return 0, 0, nil, ErrVarintOverflow
+	}
+	err := s.checkElements(l)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	size := l / 8
+	if l%8 != 0 {
+		size++
+	}
+	if uint64(len(buf[n:])) < size {
+		return 0, 0, nil, ErrShortBuffer
+	}
+	packed := buf[n : n+int(size)]
+	if l%8 != 0 && packed[len(packed)-1]>>uint(l%8) != 0 {
+		return 0, 0, nil, ErrInvalidBitSet
+	}
+	return int(l), n, packed, nil
+}
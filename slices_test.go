@@ -0,0 +1,108 @@
+package encode
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedUints(t *testing.T) {
+	v64 := []uint64{1, math.MaxUint64}
+	v32 := []uint32{0x01020304}
+	enc := New(FixedUint64s(&v64), FixedUint32s(&v32))
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{
+			0x02,
+			0, 0, 0, 0, 0, 0, 0, 1,
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			0x01,
+			0x01, 0x02, 0x03, 0x04,
+		},
+		buf,
+	)
+	require.Equal(
+		t,
+		[]byte{0x00, 0x01, 0x04, 0x03, 0x02, 0x01},
+		New(FixedUint64s(new([]uint64)), FixedUint32s(&v32)).
+			WithByteOrder(binary.LittleEndian).Encode(),
+	)
+
+	v64, v32 = nil, nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, []uint64{1, math.MaxUint64}, v64)
+	require.Equal(t, []uint32{0x01020304}, v32)
+
+	require.ErrorIs(t, enc.Decode(buf[:len(buf)-1]), ErrShortBuffer)
+	require.ErrorIs(t, enc.Decode([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0x0F}), ErrShortBuffer)
+	require.ErrorIs(t, enc.DecodeLimited(buf, Limits{MaxElements: 1}), ErrLimitExceeded)
+}
+
+func TestUvarints(t *testing.T) {
+	v64 := []uint64{1, 300, math.MaxUint64}
+	v32 := []uint32{math.MaxUint32}
+	enc := New(Uvarint64s(&v64), Uvarint32s(&v32))
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{
+			0x03, 0x01, 0xAC, 0x02, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01,
+			0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0x0F,
+		},
+		buf,
+	)
+
+	v64, v32 = nil, nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, []uint64{1, 300, math.MaxUint64}, v64)
+	require.Equal(t, []uint32{math.MaxUint32}, v32)
+
+	require.ErrorIs(
+		t,
+		New(Uvarint32s(&v32)).Decode([]byte{0x01, 0x80, 0x80, 0x80, 0x80, 0x10}),
+		ErrVarintOverflow,
+	)
+	require.ErrorIs(t, enc.Decode(buf[:len(buf)-1]), ErrShortBuffer)
+	require.ErrorIs(t, enc.DecodeLimited(buf, Limits{MaxElements: 2}), ErrLimitExceeded)
+}
+
+func TestStrings(t *testing.T) {
+	v := []string{"abc", "", "de"}
+	enc := New(Strings(&v))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x03, 0x03, 'a', 'b', 'c', 0x00, 0x02, 'd', 'e'}, buf)
+
+	v = nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, []string{"abc", "", "de"}, v)
+
+	require.ErrorIs(t, enc.Decode(buf[:len(buf)-1]), ErrShortBuffer)
+	require.ErrorIs(t, enc.Decode([]byte{0x05, 0x00, 0x00}), ErrShortBuffer)
+	require.ErrorIs(t, enc.DecodeLimited(buf, Limits{MaxElements: 2}), ErrLimitExceeded)
+	require.ErrorIs(t, enc.DecodeLimited(buf, Limits{MaxLength: 2}), ErrLimitExceeded)
+}
+
+func TestBools(t *testing.T) {
+	v := []bool{true, false, false, true, false, false, false, false, false, true}
+	enc := New(Bools(&v))
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x0A, 0x09, 0x02}, buf)
+
+	v = nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(
+		t,
+		[]bool{true, false, false, true, false, false, false, false, false, true},
+		v,
+	)
+
+	empty := []bool{}
+	require.Equal(t, []byte{0x00}, New(Bools(&empty)).Encode())
+
+	require.ErrorIs(t, enc.Decode(buf[:len(buf)-1]), ErrShortBuffer)
+	require.ErrorIs(t, enc.Decode([]byte{0x0A, 0x09, 0x06}), ErrInvalidBitSet)
+	require.ErrorIs(t, enc.DecodeLimited(buf, Limits{MaxElements: 9}), ErrLimitExceeded)
+}
@@ -0,0 +1,85 @@
+package encode
+
+import "math/bits"
+
+// Encode v as a varint in the format used by SQLite's file format. Like VLQ, groups of 7 bits are
+// written most significant first with the high bit of every byte but the last set, except that the
+// encoding is never more than 9 bytes: values that need more than 56 bits use all 8 bits of the
+// 9th byte.
+//
+//   0                      00
+//   240                    81 70
+//   16384                  81 80 00
+//   0xffffffffffffffff     ff ff ff ff ff ff ff ff ff
+//
+// Decode returns ErrInvalidVarint if the encoding is longer than necessary.
+func SQLiteVarint(v *uint64) Item {
+	return sqliteVarint{v}
+}
+
+type sqliteVarint struct{ v *uint64 }
+
+func (e sqliteVarint) Encode(buf []byte) {
+	x := *e.v
+	n := e.Size()
+	i := n - 1
+	if n == 9 {
+		buf[8] = byte(x)
+		x >>= 8
+		i--
+	} else {
+		buf[i] = byte(x & 0x7f)
+		x >>= 7
+		i--
+	}
+	for ; i >= 0; i-- {
+		buf[i] = byte(x&0x7f) | 0x80
+		x >>= 7
+	}
+}
+func (e sqliteVarint) Size() int {
+	l := bits.Len64(*e.v)
+	if l > 56 {
+		return 9
+	}
+	if l == 0 {
+		return 1
+	}
+	return (l + 6) / 7
+}
+func (e sqliteVarint) kind() string {
+	return "SQLiteVarint"
+}
+func (e sqliteVarint) sizeBounds() (min, max int) {
+	return 1, 9
+}
+func (e sqliteVarint) skip(buf []byte) (int, error) {
+	for i := 0; i < len(buf) && i < 8; i++ {
+		if buf[i]&0x80 == 0 {
+			return i + 1, nil
+		}
+	}
+	return skipFixed(9, buf)
+}
+func (e sqliteVarint) Decode(buf []byte) error {
+	n, err := e.skip(buf)
+	if err != nil {
+		return err
+	}
+	var x uint64
+	if n == 9 {
+		for _, b := range buf[:8] {
+			x = x<<7 | uint64(b&0x7f)
+		}
+		x = x<<8 | uint64(buf[8])
+	} else {
+		for _, b := range buf[:n] {
+			x = x<<7 | uint64(b&0x7f)
+		}
+	}
+	if (sqliteVarint{&x}).Size() != n {
+		return ErrInvalidVarint
+	}
+	*e.v = x
+	return nil
+}
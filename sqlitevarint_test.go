@@ -0,0 +1,39 @@
+package encode
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteVarint(t *testing.T) {
+	check := func(x uint64, expected []byte) {
+		require.Equal(t, expected, New(SQLiteVarint(&x)).Encode())
+		var x2 uint64
+		require.NoError(t, New(SQLiteVarint(&x2)).Decode(expected))
+		require.Equal(t, x, x2)
+	}
+	check(0, []byte{0x00})
+	check(0x7f, []byte{0x7f})
+	check(240, []byte{0x81, 0x70})
+	check(16384, []byte{0x81, 0x80, 0x00})
+	check(1<<56-1, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f})
+	check(1<<56, []byte{0x80, 0xc0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00})
+	check(math.MaxUint64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	var x uint64
+	require.ErrorIs(t, New(SQLiteVarint(&x)).Decode([]byte{0x81}), io.ErrUnexpectedEOF)
+	require.ErrorIs(
+		t,
+		New(SQLiteVarint(&x)).Decode([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}),
+		io.ErrUnexpectedEOF,
+	)
+	require.ErrorIs(t, New(SQLiteVarint(&x)).Decode([]byte{0x80, 0x01}), ErrInvalidVarint)
+	require.ErrorIs(
+		t,
+		New(SQLiteVarint(&x)).Decode([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}),
+		ErrInvalidVarint,
+	)
+}
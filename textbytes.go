@@ -0,0 +1,101 @@
+package encode
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+var ErrInvalidBase64 = NewInvalidValueError("encode: invalid base64")
+var ErrInvalidHex = NewInvalidValueError("encode: invalid hex")
+
+// Encode v as a uvarint of the length of its standard, padded base64 encoding, followed by that
+// text. This is a third larger than LengthDelimBytes, but keeps the field readable (and
+// greppable) for formats that are otherwise text, or that pass through tools that mangle
+// arbitrary bytes. Decode returns ErrInvalidBase64 for anything other than exactly the text Encode
+// writes, including line breaks and nonzero padding bits.
+func Base64Bytes(v *[]byte) Item {
+	return base64Bytes{v}
+}
+
+type base64Bytes struct{ v *[]byte }
+
+func (e base64Bytes) Encode(buf []byte) {
+	l := base64.StdEncoding.EncodedLen(len(*e.v))
+	n := binary.PutUvarint(buf, uint64(l))
+	base64.StdEncoding.Encode(buf[n:], *e.v)
+}
+func (e base64Bytes) Size() int {
+	l := base64.StdEncoding.EncodedLen(len(*e.v))
+	return uvarintSize(uint64(l)) + l
+}
+func (e base64Bytes) kind() string {
+	return "Base64Bytes"
+}
+func (e base64Bytes) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e base64Bytes) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e base64Bytes) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e base64Bytes) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
+	}
+	v := s.bytes(base64.StdEncoding.DecodedLen(len(b)))
+	n, err := base64.StdEncoding.Strict().Decode(v, b)
+	// Decode skips newlines, which would leave the text longer than Size says.
+	if err != nil || base64.StdEncoding.EncodedLen(n) != len(b) {
+		return ErrInvalidBase64
+	}
+	*e.v = v[:n]
+	return nil
+}
+
+// Encode v as a uvarint of the length of its lowercase hex encoding, followed by that text. This
+// is twice as large as LengthDelimBytes, but the field can be read straight out of a hexdump or
+// log line. Decode accepts either case.
+func HexBytes(v *[]byte) Item {
+	return hexBytes{v}
+}
+
+type hexBytes struct{ v *[]byte }
+
+func (e hexBytes) Encode(buf []byte) {
+	l := hex.EncodedLen(len(*e.v))
+	n := binary.PutUvarint(buf, uint64(l))
+	hex.Encode(buf[n:], *e.v)
+}
+func (e hexBytes) Size() int {
+	l := hex.EncodedLen(len(*e.v))
+	return uvarintSize(uint64(l)) + l
+}
+func (e hexBytes) kind() string {
+	return "HexBytes"
+}
+func (e hexBytes) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e hexBytes) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e hexBytes) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e hexBytes) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
+	}
+	v := s.bytes(hex.DecodedLen(len(b)))
+	_, err = hex.Decode(v, b)
+	if err != nil {
+		return ErrInvalidHex
+	}
+	*e.v = v
+	return nil
+}
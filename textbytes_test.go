@@ -0,0 +1,47 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase64Bytes(t *testing.T) {
+	b := []byte{0xde, 0xad, 0xbe, 0xef}
+	buf := New(Base64Bytes(&b)).Encode()
+	require.Equal(t, append([]byte{8}, "3q2+7w=="...), buf)
+
+	var b2 []byte
+	require.NoError(t, New(Base64Bytes(&b2)).Decode(buf))
+	require.Equal(t, b, b2)
+
+	empty := []byte{}
+	buf = New(Base64Bytes(&empty)).Encode()
+	require.Equal(t, []byte{0}, buf)
+	require.NoError(t, New(Base64Bytes(&b2)).Decode(buf))
+	require.Len(t, b2, 0)
+
+	require.ErrorIs(t, New(Base64Bytes(&b2)).Decode(append([]byte{4}, "3q2$"...)), ErrInvalidBase64)
+
+	var id uint16
+	enc := New(Base64Bytes(&b2), FixedUint16(&id))
+	require.ErrorIs(t, enc.Decode([]byte("\x05QQ==\n\x01\x02")), ErrInvalidBase64)
+	require.ErrorIs(t, enc.Decode([]byte("\x05Q\nQ==\x01\x02")), ErrInvalidBase64)
+	require.ErrorIs(t, enc.Decode([]byte("\x04QR==\x01\x02")), ErrInvalidBase64)
+	require.NoError(t, enc.Decode([]byte("\x04QQ==\x01\x02")))
+	require.Equal(t, []byte("A"), b2)
+	require.Equal(t, uint16(0x0102), id)
+}
+
+func TestHexBytes(t *testing.T) {
+	b := []byte{0xde, 0xad, 0xbe, 0xef}
+	buf := New(HexBytes(&b)).Encode()
+	require.Equal(t, append([]byte{8}, "deadbeef"...), buf)
+
+	var b2 []byte
+	require.NoError(t, New(HexBytes(&b2)).Decode(append([]byte{8}, "DEADBEEF"...)))
+	require.Equal(t, b, b2)
+
+	require.ErrorIs(t, New(HexBytes(&b2)).Decode(append([]byte{3}, "dea"...)), ErrInvalidHex)
+	require.ErrorIs(t, New(HexBytes(&b2)).Decode(append([]byte{2}, "zz"...)), ErrInvalidHex)
+}
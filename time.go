@@ -0,0 +1,281 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTime = NewInvalidValueError("encode: invalid time")
+
+// The NTP epoch, 1900-01-01, in seconds relative to the Unix epoch.
+const ntpEpochOffset = -2208988800
+
+// Encode v as a 64-bit NTP timestamp, as used by NTP, RTP/RTCP, and SNTP: a big endian uint32 of
+// whole seconds since 1900-01-01 UTC, followed by a big endian uint32 of the fraction of a second
+// in units of 2^-32 seconds, taking 8 bytes.
+//
+// The seconds wrap in 2036, so as RFC 4330 suggests, timestamps with the top bit of the seconds
+// clear are read as being after 2036, making the range that round-trips 1968-01-20 to 2104-02-26.
+// Sub-second precision is about a quarter of a nanosecond, so times round-trip exactly, though the
+// decoded time is always in UTC. The zero time.Time is written as all zeroes, which NTP uses to
+// mean an unknown time, and all zeroes decodes to the zero time.Time.
+func NTPTimestamp(v *time.Time) Item {
+	return ntpTimestamp{v}
+}
+
+type ntpTimestamp struct{ v *time.Time }
+
+func (e ntpTimestamp) Encode(buf []byte) {
+	if e.v.IsZero() {
+		binary.BigEndian.PutUint64(buf, 0)
+		return
+	}
+	secs := uint32(e.v.Unix() - ntpEpochOffset)
+	frac := uint32((uint64(e.v.Nanosecond()) << 32) / uint64(time.Second))
+	binary.BigEndian.PutUint32(buf, secs)
+	binary.BigEndian.PutUint32(buf[4:], frac)
+}
+func (e ntpTimestamp) Size() int {
+	return 8
+}
+func (e ntpTimestamp) kind() string {
+	return "NTPTimestamp"
+}
+func (e ntpTimestamp) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e ntpTimestamp) fixedSize() int {
+	return e.Size()
+}
+func (e ntpTimestamp) Decode(buf []byte) error {
+	if len(buf) < 8 {
+		return ErrShortBuffer
+	}
+	secs := binary.BigEndian.Uint32(buf)
+	frac := binary.BigEndian.Uint32(buf[4:])
+	if secs == 0 && frac == 0 {
+		*e.v = time.Time{}
+		return nil
+	}
+	unix := int64(secs) + ntpEpochOffset
+	if secs&0x80000000 == 0 {
+		// In the era that starts in 2036.
+		unix += 1 << 32
+	}
+	// Round to the nearest nanosecond so that encoded times round-trip.
+	nsec := (uint64(frac)*uint64(time.Second) + 1<<31) >> 32
+	*e.v = time.Unix(unix, int64(nsec)).UTC()
+	return nil
+}
+
+// Encode v in the layout of C's struct timespec: seconds since the Unix epoch, then nanoseconds,
+// each a signed integer of width bytes, which must be 4 or 8. Written in big endian order, which
+// WithByteOrder changes, for example to binary.NativeEndian for structures from the local kernel.
+//
+// Encode panics if width is 4 and v is outside the range of a 32-bit time_t, 1901 to 2038. Decode
+// returns ErrInvalidTime if the nanoseconds aren't in [0, 999999999]. The decoded time is in UTC.
+func Timespec(v *time.Time, width int) Item {
+	return newUnixTime(v, width, time.Nanosecond)
+}
+
+// Encode v in the layout of C's struct timeval, as Timespec but with microseconds rather than
+// nanoseconds, as in pcap record headers. v is truncated to the microsecond.
+func Timeval(v *time.Time, width int) Item {
+	return newUnixTime(v, width, time.Microsecond)
+}
+
+func newUnixTime(v *time.Time, width int, unit time.Duration) Item {
+	if width != 4 && width != 8 {
+		panic(fmt.Sprintf("invalid width=%d, must be 4 or 8", width))
+	}
+	return unixTime{v: v, width: width, unit: unit, order: binary.BigEndian}
+}
+
+type unixTime struct {
+	v     *time.Time
+	width int
+	// The unit of the second field.
+	unit  time.Duration
+	order binary.ByteOrder
+}
+
+func (e unixTime) Encode(buf []byte) {
+	secs := e.v.Unix()
+	sub := int64(e.v.Nanosecond()) / int64(e.unit)
+	if e.width == 4 {
+		if secs < math.MinInt32 || secs > math.MaxInt32 {
+			panic(fmt.Sprintf("encode: %s doesn't fit in a 32-bit time_t", e.v))
+		}
+		e.order.PutUint32(buf, uint32(secs))
+		e.order.PutUint32(buf[4:], uint32(sub))
+		return
+	}
+	e.order.PutUint64(buf, uint64(secs))
+	e.order.PutUint64(buf[8:], uint64(sub))
+}
+func (e unixTime) Size() int {
+	return 2 * e.width
+}
+func (e unixTime) kind() string {
+	if e.unit == time.Microsecond {
+		return "Timeval"
+	}
+	return "Timespec"
+}
+func (e unixTime) withByteOrder(order binary.ByteOrder) Item {
+	return unixTime{v: e.v, width: e.width, unit: e.unit, order: order}
+}
+func (e unixTime) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e unixTime) fixedSize() int {
+	return e.Size()
+}
+func (e unixTime) Decode(buf []byte) error {
+	if len(buf) < e.Size() {
+		return ErrShortBuffer
+	}
+	var secs, sub int64
+	if e.width == 4 {
+		secs = int64(int32(e.order.Uint32(buf)))
+		sub = int64(int32(e.order.Uint32(buf[4:])))
+	} else {
+		secs = int64(e.order.Uint64(buf))
+		sub = int64(e.order.Uint64(buf[8:]))
+	}
+	if sub < 0 || sub >= int64(time.Second/e.unit) {
+		return ErrInvalidTime
+	}
+	*e.v = time.Unix(secs, sub*int64(e.unit)).UTC()
+	return nil
+}
+
+// Encode v as an MS-DOS date and time, as in ZIP file headers and FAT directory entries: a 16-bit
+// time holding the hour, minute, and seconds divided by two, followed by a 16-bit date holding the
+// year since 1980, month, and day, both little endian, taking 4 bytes.
+//
+// The format has no time zone, so v's wall clock in its own location is written, and the decoded
+// time is in UTC. Seconds are truncated to an even number. Times before 1980 are written as
+// 1980-01-01 00:00:00 and times after 2107 as 2107-12-31 23:59:58, the limits of the format, except
+// that the zero time.Time is written as all zeroes, which decodes to the zero time.Time. Decode
+// returns ErrInvalidTime for a date or time that doesn't exist, like a month of 13.
+func DOSDateTime(v *time.Time) Item {
+	return dosDateTime{v}
+}
+
+type dosDateTime struct{ v *time.Time }
+
+func (e dosDateTime) Encode(buf []byte) {
+	if e.v.IsZero() {
+		binary.LittleEndian.PutUint32(buf, 0)
+		return
+	}
+	t := *e.v
+	switch {
+	case t.Year() < 1980:
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	case t.Year() > 2107:
+		t = time.Date(2107, 12, 31, 23, 59, 58, 0, time.UTC)
+	}
+	dosTime := t.Hour()<<11 | t.Minute()<<5 | t.Second()/2
+	dosDate := (t.Year()-1980)<<9 | int(t.Month())<<5 | t.Day()
+	binary.LittleEndian.PutUint16(buf, uint16(dosTime))
+	binary.LittleEndian.PutUint16(buf[2:], uint16(dosDate))
+}
+func (e dosDateTime) Size() int {
+	return 4
+}
+func (e dosDateTime) kind() string {
+	return "DOSDateTime"
+}
+func (e dosDateTime) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e dosDateTime) fixedSize() int {
+	return e.Size()
+}
+func (e dosDateTime) Decode(buf []byte) error {
+	if len(buf) < 4 {
+		return ErrShortBuffer
+	}
+	dosTime := int(binary.LittleEndian.Uint16(buf))
+	dosDate := int(binary.LittleEndian.Uint16(buf[2:]))
+	if dosTime == 0 && dosDate == 0 {
+		*e.v = time.Time{}
+		return nil
+	}
+	hour, min, sec := dosTime>>11, dosTime>>5&0x3F, dosTime&0x1F*2
+	year, month, day := dosDate>>9+1980, dosDate>>5&0xF, dosDate&0x1F
+	t := time.Date(year, time.Month(month), day, hour, min, sec, 0, time.UTC)
+	// time.Date normalizes out-of-range fields, so they only survive if they were valid.
+	if t.Month() != time.Month(month) || t.Day() != day || t.Hour() != hour ||
+		t.Minute() != min || t.Second() != sec {
+		return ErrInvalidTime
+	}
+	*e.v = t
+	return nil
+}
+
+// Encode v as text in the RFC 3339 profile of ISO 8601, in UTC with the given number of digits of
+// fractional seconds, which must be 0, 3, 6, or 9, for example "2006-01-02T15:04:05Z" in 20 bytes
+// or "2006-01-02T15:04:05.000Z" in 24 bytes. The width never changes, so this is suitable for
+// otherwise binary records that need human-readable timestamps, like log and tape headers.
+//
+// v is truncated to the precision given. Encode panics if v's year in UTC isn't in [0, 9999].
+// Decode returns ErrInvalidTime if buf doesn't hold a time in exactly this form.
+func ISO8601(v *time.Time, fractionDigits int) Item {
+	layout := "2006-01-02T15:04:05"
+	switch fractionDigits {
+	case 0:
+	case 3, 6, 9:
+		layout += "." + strings.Repeat("0", fractionDigits)
+	default:
+		panic(fmt.Sprintf("invalid fractionDigits=%d, must be 0, 3, 6, or 9", fractionDigits))
+	}
+	return iso8601{v: v, layout: layout + "Z"}
+}
+
+type iso8601 struct {
+	v      *time.Time
+	layout string
+}
+
+func (e iso8601) Encode(buf []byte) {
+	t := e.v.UTC()
+	if t.Year() < 0 || t.Year() > 9999 {
+		panic(fmt.Sprintf("encode: %s is outside of the years ISO8601 can write", t))
+	}
+	// AppendFormat may write past the end of the timestamp while formatting the fraction, so buf
+	// can't be used directly.
+	var scratch [40]byte
+	copy(buf, t.AppendFormat(scratch[:0], e.layout))
+}
+func (e iso8601) Size() int {
+	return len(e.layout)
+}
+func (e iso8601) kind() string {
+	return "ISO8601"
+}
+func (e iso8601) skip(buf []byte) (int, error) {
+	return skipFixed(e.Size(), buf)
+}
+func (e iso8601) fixedSize() int {
+	return e.Size()
+}
+func (e iso8601) Decode(buf []byte) error {
+	if len(buf) < len(e.layout) {
+		return ErrShortBuffer
+	}
+	s := string(buf[:len(e.layout)])
+	t, err := time.Parse(e.layout, s)
+	// Parse accepts some variations, like a fraction with the wrong number of digits, that wouldn't
+	// be written the same way again.
+	if err != nil || t.Format(e.layout) != s {
+		return ErrInvalidTime
+	}
+	*e.v = t
+	return nil
+}
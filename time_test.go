@@ -0,0 +1,160 @@
+package encode
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNTPTimestamp(t *testing.T) {
+	var v time.Time
+	enc := New(NTPTimestamp(&v))
+
+	v = time.Date(1970, 1, 1, 0, 0, 0, 500000000, time.UTC)
+	require.Equal(t, []byte{0x83, 0xAA, 0x7E, 0x80, 0x80, 0x00, 0x00, 0x00}, enc.Encode())
+
+	for _, ts := range []time.Time{
+		time.Date(1968, 1, 20, 3, 14, 8, 0, time.UTC),
+		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 30, 12, 34, 56, 123456789, time.UTC),
+		time.Date(2036, 2, 7, 6, 28, 16, 1, time.UTC),
+		time.Date(2104, 2, 26, 9, 42, 23, 999999999, time.UTC),
+		{},
+	} {
+		v = ts
+		buf := enc.Encode()
+		v = time.Now()
+		require.NoError(t, enc.Decode(buf))
+		require.True(t, ts.Equal(v), "%s != %s", ts, v)
+	}
+
+	// The first second of the 2036 era.
+	require.NoError(t, enc.Decode([]byte{0, 0, 0, 0, 0, 0, 0, 1}))
+	require.Equal(t, time.Date(2036, 2, 7, 6, 28, 16, 0, time.UTC), v)
+
+	require.ErrorIs(t, enc.Decode(make([]byte, 7)), ErrShortBuffer)
+}
+
+func TestTimespec(t *testing.T) {
+	var v time.Time
+	v = time.Date(2001, 9, 9, 1, 46, 40, 123456789, time.UTC)
+
+	require.Equal(
+		t,
+		[]byte{0x3B, 0x9A, 0xCA, 0x00, 0x07, 0x5B, 0xCD, 0x15},
+		New(Timespec(&v, 4)).Encode(),
+	)
+	require.Equal(
+		t,
+		[]byte{
+			0x00, 0xCA, 0x9A, 0x3B, 0x00, 0x00, 0x00, 0x00,
+			0x40, 0xE2, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00,
+		},
+		New(Timeval(&v, 8)).WithByteOrder(binary.LittleEndian).Encode(),
+	)
+
+	for _, item := range []Item{Timespec(&v, 4), Timespec(&v, 8)} {
+		ts := v
+		buf := New(item).Encode()
+		v = time.Time{}
+		require.NoError(t, New(item).Decode(buf))
+		require.Equal(t, ts, v)
+	}
+
+	v = time.Date(1960, 1, 1, 0, 0, 0, 500000000, time.UTC)
+	buf := New(Timeval(&v, 8)).Encode()
+	v = time.Time{}
+	require.NoError(t, New(Timeval(&v, 8)).Decode(buf))
+	require.Equal(t, time.Date(1960, 1, 1, 0, 0, 0, 500000000, time.UTC), v)
+
+	tooManyMicros := []byte{0, 0, 0, 0, 0, 0x0F, 0x42, 0x40}
+	require.ErrorIs(t, New(Timeval(&v, 4)).Decode(tooManyMicros), ErrInvalidTime)
+	require.ErrorIs(t, New(Timespec(&v, 8)).Decode(make([]byte, 15)), ErrShortBuffer)
+
+	v = time.Date(2038, 1, 19, 3, 14, 8, 0, time.UTC)
+	require.Panics(t, func() { New(Timespec(&v, 4)).Encode() })
+	require.Panics(t, func() { Timespec(&v, 2) })
+}
+
+func TestDOSDateTime(t *testing.T) {
+	var v time.Time
+	enc := New(DOSDateTime(&v))
+
+	v = time.Date(2009, 11, 10, 23, 0, 31, 500, time.UTC)
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x0F, 0xB8, 0x6A, 0x3B}, buf)
+	v = time.Time{}
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, time.Date(2009, 11, 10, 23, 0, 30, 0, time.UTC), v)
+
+	// The wall clock is kept, whatever the location.
+	v = time.Date(2009, 11, 10, 23, 0, 30, 0, time.FixedZone("", -8*60*60))
+	require.Equal(t, buf, enc.Encode())
+
+	v = time.Date(1979, 12, 31, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, []byte{0x00, 0x00, 0x21, 0x00}, enc.Encode())
+	v = time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, enc.Decode(enc.Encode()))
+	require.Equal(t, time.Date(2107, 12, 31, 23, 59, 58, 0, time.UTC), v)
+
+	v = time.Time{}
+	require.Equal(t, []byte{0, 0, 0, 0}, enc.Encode())
+	v = time.Now()
+	require.NoError(t, enc.Decode([]byte{0, 0, 0, 0}))
+	require.True(t, v.IsZero())
+
+	// February 30th.
+	require.ErrorIs(t, enc.Decode([]byte{0x00, 0x00, 0x5E, 0x3A}), ErrInvalidTime)
+	// 24:00.
+	require.ErrorIs(t, enc.Decode([]byte{0x00, 0xC0, 0x6A, 0x3B}), ErrInvalidTime)
+	require.ErrorIs(t, enc.Decode([]byte{0x00, 0x00, 0x00}), ErrShortBuffer)
+}
+
+func TestISO8601(t *testing.T) {
+	var v time.Time
+	ts := time.Date(2024, 2, 29, 1, 2, 3, 456789012, time.FixedZone("", 60*60))
+
+	for _, tc := range []struct {
+		digits   int
+		expected string
+	}{
+		{0, "2024-02-29T00:02:03Z"},
+		{3, "2024-02-29T00:02:03.456Z"},
+		{6, "2024-02-29T00:02:03.456789Z"},
+		{9, "2024-02-29T00:02:03.456789012Z"},
+	} {
+		enc := New(ISO8601(&v, tc.digits))
+		v = ts
+		buf := enc.Encode()
+		require.Equal(t, tc.expected, string(buf))
+		v = time.Time{}
+		require.NoError(t, enc.Decode(buf))
+		require.True(t, ts.Truncate(time.Duration(pow10(9-tc.digits))).Equal(v))
+		require.Equal(t, time.UTC, v.Location())
+	}
+
+	enc := New(ISO8601(&v, 3))
+	for _, bad := range []string{
+		"2024-02-30T00:02:03.456Z",
+		"2024-02-29T00:02:03,456Z",
+		"2024-02-29 00:02:03.456Z",
+		"2024-02-29T00:02:03.456+",
+	} {
+		require.ErrorIs(t, enc.Decode([]byte(bad)), ErrInvalidTime, bad)
+	}
+	require.ErrorIs(t, enc.Decode([]byte("2024-02-29T00:02:03")), ErrShortBuffer)
+
+	v = time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Panics(t, func() { enc.Encode() })
+	require.Panics(t, func() { ISO8601(&v, 2) })
+}
+
+func pow10(n int) int64 {
+	x := int64(1)
+	for i := 0; i < n; i++ {
+		x *= 10
+	}
+	return x
+}
@@ -0,0 +1,311 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+var ErrUnknownTag = NewInvalidValueError("encode: unknown TLV tag")
+var ErrInvalidTLVLength = NewInvalidValueError("encode: TLV length does not match its value")
+
+// What decoding TLVs does with a record whose tag has no item.
+type TLVPolicy int
+
+const (
+	// Skip the record.
+	SkipUnknownTags TLVPolicy = iota
+	// Return ErrUnknownTag.
+	RejectUnknownTags
+)
+
+// How the records of TLVs and TLVRecord are written.
+type TLVFormat struct {
+	// The size of each tag in bytes, written big endian, or 0 to write tags as uvarints. Must be 0,
+	// 1, 2, 4, or 8.
+	TagSize int
+	// The size of each length in bytes, written big endian, or 0 to write lengths as uvarints. Must
+	// be 0, 1, 2, 4, or 8.
+	LengthSize int
+	// What decoding TLVs does with records it has no item for.
+	Unknown TLVPolicy
+}
+
+func (f TLVFormat) check() {
+	for _, size := range []int{f.TagSize, f.LengthSize} {
+		switch size {
+		case 0, 1, 2, 4, 8:
+		default:
+			panic(fmt.Sprintf("invalid TLV field size %d, must be 0, 1, 2, 4, or 8", size))
+		}
+	}
+}
+
+// Encode a type-length-value record for each of items, in increasing order of tag: the tag, the
+// size of the item's encoding, and then the item, with the tag and size written as format says.
+// Records for items that encode to nothing, like an If whose condition is false, are left out.
+//
+// On decode, records are read until the end of the buffer and each is decoded by the item for its
+// tag, so they may appear in any order. Items whose tags don't appear are left untouched. Records
+// with tags that have no item are handled as format.Unknown says. Returns ErrInvalidTLVLength if an
+// item doesn't decode exactly as many bytes as its record's length says, so items that hold
+// variable-length values should be ones that take the rest of the buffer, like Remainder.
+//
+// Because the records run to the end of the buffer, TLVs is usually the last item of an Encoding.
+// Panics if format is invalid.
+func TLVs(items map[uint64]Item, format TLVFormat) Item {
+	format.check()
+	tags := make([]uint64, 0, len(items))
+	for tag := range items {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	return tlvs{items: items, tags: tags, format: format}
+}
+
+type tlvs struct {
+	items map[uint64]Item
+	// The keys of items in increasing order.
+	tags   []uint64
+	format TLVFormat
+}
+
+func (e tlvs) setOffset(offset int) {
+	// Each value is delimited by its record.
+	for _, item := range e.items {
+		setOffset(item, 0)
+	}
+}
+func (e tlvs) Encode(buf []byte) {
+	i := 0
+	for _, tag := range e.tags {
+		item := e.items[tag]
+		if item.Size() == 0 {
+			continue
+		}
+		i += encodeTLVRecord(buf[i:], tag, item, e.format)
+	}
+}
+func (e tlvs) Size() int {
+	size := 0
+	for _, tag := range e.tags {
+		itemSize := e.items[tag].Size()
+		if itemSize == 0 {
+			continue
+		}
+		size += tlvHeaderSize(tag, itemSize, e.format) + itemSize
+	}
+	return size
+}
+func (e tlvs) kind() string {
+	return "TLVs"
+}
+func (e tlvs) withByteOrder(order binary.ByteOrder) Item {
+	items := make(map[uint64]Item, len(e.items))
+	for tag, item := range e.items {
+		items[tag] = withByteOrder(item, order)
+	}
+	return tlvs{items: items, tags: e.tags, format: e.format}
+}
+func (e tlvs) sizeBounds() (min, max int) {
+	return 0, -1
+}
+func (e tlvs) dependsOnEarlier() bool {
+	for _, item := range e.items {
+		if dependsOnEarlier(item) {
+			return true
+		}
+	}
+	return false
+}
+func (e tlvs) skip(buf []byte) (int, error) {
+	for i := 0; i < len(buf); {
+		_, value, n, err := readTLVRecord(buf[i:], e.format)
+		if err != nil {
+			return 0, err
+		}
+		i += n + len(value)
+	}
+	return len(buf), nil
+}
+func (e tlvs) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e tlvs) decodeLimited(buf []byte, s *decodeState) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+	for i := 0; i < len(buf); {
+		tag, value, n, err := readTLVRecord(buf[i:], e.format)
+		if err != nil {
+			return err
+		}
+		i += n + len(value)
+		item, ok := e.items[tag]
+		if !ok {
+			if e.format.Unknown == RejectUnknownTags {
+				return ErrUnknownTag
+			}
+			continue
+		}
+		err = decodeTLVValue(item, value, s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Encode item as a single type-length-value record with the given tag, written the same as a
+// record of TLVs. Unlike TLVs, it's only one record, so it can go anywhere in an Encoding, for
+// protocols that give their fields a fixed order. Decode returns ErrUnknownTag if the record has a
+// different tag. Panics if format is invalid.
+func TLVRecord(tag uint64, item Item, format TLVFormat) Item {
+	format.check()
+	return tlvRecord{tag: tag, item: item, format: format}
+}
+
+type tlvRecord struct {
+	tag    uint64
+	item   Item
+	format TLVFormat
+}
+
+func (e tlvRecord) setOffset(offset int) {
+	// The value is delimited by the record.
+	setOffset(e.item, 0)
+}
+func (e tlvRecord) Encode(buf []byte) {
+	encodeTLVRecord(buf, e.tag, e.item, e.format)
+}
+func (e tlvRecord) Size() int {
+	itemSize := e.item.Size()
+	return tlvHeaderSize(e.tag, itemSize, e.format) + itemSize
+}
+func (e tlvRecord) kind() string {
+	return "TLVRecord"
+}
+func (e tlvRecord) withByteOrder(order binary.ByteOrder) Item {
+	return tlvRecord{tag: e.tag, item: withByteOrder(e.item, order), format: e.format}
+}
+func (e tlvRecord) sizeBounds() (min, max int) {
+	itemMin, itemMax := sizeBounds(e.item)
+	min = tlvHeaderSize(e.tag, itemMin, e.format) + itemMin
+	if itemMax < 0 || e.format.LengthSize == 0 {
+		return min, -1
+	}
+	return min, tlvHeaderSize(e.tag, itemMax, e.format) + itemMax
+}
+func (e tlvRecord) dependsOnEarlier() bool {
+	return dependsOnEarlier(e.item)
+}
+func (e tlvRecord) skip(buf []byte) (int, error) {
+	_, value, n, err := readTLVRecord(buf, e.format)
+	if err != nil {
+		return 0, err
+	}
+	return n + len(value), nil
+}
+func (e tlvRecord) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e tlvRecord) decodeLimited(buf []byte, s *decodeState) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+	tag, value, _, err := readTLVRecord(buf, e.format)
+	if err != nil {
+		return err
+	}
+	if tag != e.tag {
+		return ErrUnknownTag
+	}
+	return decodeTLVValue(e.item, value, s)
+}
+
+func decodeTLVValue(item Item, value []byte, s *decodeState) error {
+	err := decodeItem(item, value, s)
+	if err != nil {
+		return err
+	}
+	if item.Size() != len(value) {
+		return ErrInvalidTLVLength
+	}
+	return nil
+}
+
+// Writes a record for item to the start of buf, returning its size.
+func encodeTLVRecord(buf []byte, tag uint64, item Item, format TLVFormat) int {
+	itemSize := item.Size()
+	i := putTLVNumber(buf, tag, format.TagSize)
+	i += putTLVNumber(buf[i:], uint64(itemSize), format.LengthSize)
+	item.Encode(buf[i : i+itemSize])
+	return i + itemSize
+}
+
+func tlvHeaderSize(tag uint64, itemSize int, format TLVFormat) int {
+	return tlvNumberSize(tag, format.TagSize) + tlvNumberSize(uint64(itemSize), format.LengthSize)
+}
+
+func tlvNumberSize(x uint64, size int) int {
+	if size == 0 {
+		return uvarintSize(x)
+	}
+	return size
+}
+
+func putTLVNumber(buf []byte, x uint64, size int) int {
+	if size == 0 {
+		return binary.PutUvarint(buf, x)
+	}
+	if size < 8 && x>>(8*size) != 0 {
+		panic(fmt.Sprintf("encode: %d doesn't fit in a %d-byte TLV field", x, size))
+	}
+	for i := 0; i < size; i++ {
+		buf[i] = byte(x >> (8 * (size - 1 - i)))
+	}
+	return size
+}
+
+// Returns the tag and value of the record at the start of buf, and the size of its header.
+func readTLVRecord(buf []byte, format TLVFormat) (uint64, []byte, int, error) {
+	tag, n, err := readTLVNumber(buf, format.TagSize)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	l, m, err := readTLVNumber(buf[n:], format.LengthSize)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	n += m
+	if uint64(len(buf[n:])) < l {
+		return 0, nil, 0, ErrShortBuffer
+	}
+	end := n + int(l)
+	return tag, buf[n:end:end], n, nil
+}
+
+func readTLVNumber(buf []byte, size int) (uint64, int, error) {
+	if size == 0 {
+		x, n := binary.Uvarint(buf)
+		if n == 0 {
+			return 0, 0, ErrShortBuffer
+		}
+		if n < 0 {
+			return 0, 0, ErrVarintOverflow
+		}
+		return x, n, nil
+	}
+	if len(buf) < size {
+		return 0, 0, ErrShortBuffer
+	}
+	x := uint64(0)
+	for _, b := range buf[:size] {
+		x = x<<8 | uint64(b)
+	}
+	return x, size, nil
+}
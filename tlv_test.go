@@ -0,0 +1,68 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLVs(t *testing.T) {
+	var flags byte
+	var name []byte
+	var power uint16
+	hasPower := false
+	items := map[uint64]Item{
+		0x01: Byte(&flags),
+		0x09: Remainder(&name),
+		0x0A: If(&hasPower, FixedUint16(&power)),
+	}
+	enc := New(TLVs(items, TLVFormat{TagSize: 1, LengthSize: 1}))
+
+	flags, name = 0x06, []byte("hi")
+	buf := enc.Encode()
+	require.Equal(t, []byte{0x01, 0x01, 0x06, 0x09, 0x02, 'h', 'i'}, buf)
+	require.Equal(t, len(buf), enc.EncodedSize())
+
+	flags, name = 0, nil
+	require.NoError(t, enc.Decode([]byte{0x09, 0x02, 'h', 'i', 0x07, 0x00, 0x01, 0x01, 0x06}))
+	require.Equal(t, byte(0x06), flags)
+	require.Equal(t, []byte("hi"), name)
+
+	hasPower = true
+	require.NoError(t, enc.Decode([]byte{0x0A, 0x02, 0x01, 0x02}))
+	require.Equal(t, uint16(0x0102), power)
+
+	require.ErrorIs(t, enc.Decode([]byte{0x01, 0x02, 0x06, 0x00}), ErrInvalidTLVLength)
+	require.ErrorIs(t, enc.Decode([]byte{0x01, 0x02, 0x06}), ErrShortBuffer)
+
+	strict := New(TLVs(items, TLVFormat{Unknown: RejectUnknownTags}))
+	require.ErrorIs(t, strict.Decode([]byte{0x07, 0x00}), ErrUnknownTag)
+	require.ErrorIs(t, strict.Decode([]byte{0x07, 0x00}), ErrInvalidValue)
+
+	require.Panics(t, func() { TLVs(items, TLVFormat{TagSize: 3}) })
+}
+
+func TestTLVRecord(t *testing.T) {
+	var id uint32
+	var payload []byte
+	enc := New(
+		TLVRecord(0x0100, FixedUint32(&id), TLVFormat{TagSize: 2, LengthSize: 2}),
+		TLVRecord(7, Remainder(&payload), TLVFormat{}),
+	)
+
+	id, payload = 5, []byte{0xAB}
+	buf := enc.Encode()
+	require.Equal(
+		t,
+		[]byte{0x01, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x05, 0x07, 0x01, 0xAB},
+		buf,
+	)
+
+	id, payload = 0, nil
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, uint32(5), id)
+	require.Equal(t, []byte{0xAB}, payload)
+
+	buf[1] = 0x01
+	require.ErrorIs(t, enc.Decode(buf), ErrUnknownTag)
+}
@@ -0,0 +1,206 @@
+package encode
+
+import "encoding/binary"
+
+// Encode item as usual, but if the buffer ends before item on decode, call setDefault instead of
+// returning ErrShortBuffer. If setDefault is nil, the value bound to item is left untouched.
+//
+// This allows a format to evolve by appending fields: wrap every newly added field in Trailing, and
+// data written before the field existed still decodes. Only the end of the buffer is detected, so
+// Trailing is only meaningful for the last items in an Encoding, and every item after a Trailing
+// must also be Trailing.
+func Trailing(item Item, setDefault func()) Item {
+	return trailing{item: item, setDefault: setDefault}
+}
+
+// Trailing, except that when the Encoding is encoded with OmitDefaults and isDefault returns true
+// for this item and every TrailingDefault after it, they are all left off the end of the encoding.
+// This shrinks messages where most of the newer fields are usually unset. setDefault must set the
+// value to one that isDefault returns true for, so that the omitted fields decode to the same
+// values they were encoded from.
+func TrailingDefault(item Item, isDefault func() bool, setDefault func()) Item {
+	return trailing{item: item, setDefault: setDefault, isDefault: isDefault}
+}
+
+type trailing struct {
+	item       Item
+	setDefault func()
+	// Only set for TrailingDefault.
+	isDefault func() bool
+}
+
+func (e trailing) setOffset(offset int) {
+	setOffset(e.item, offset)
+}
+func (e trailing) Encode(buf []byte) {
+	e.item.Encode(buf)
+}
+func (e trailing) Size() int {
+	return e.item.Size()
+}
+func (e trailing) kind() string {
+	if e.isDefault != nil {
+		return "TrailingDefault"
+	}
+	return "Trailing"
+}
+func (e trailing) withByteOrder(order binary.ByteOrder) Item {
+	return trailing{
+		item:       withByteOrder(e.item, order),
+		setDefault: e.setDefault,
+		isDefault:  e.isDefault,
+	}
+}
+func (e trailing) dependsOnEarlier() bool {
+	return dependsOnEarlier(e.item)
+}
+func (e trailing) skip(buf []byte) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	return skipItem(e.item, buf)
+}
+func (e trailing) sizeBounds() (int, int) {
+	_, max := sizeBounds(e.item)
+	return 0, max
+}
+func (e trailing) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e trailing) decodeLimited(buf []byte, s *decodeState) error {
+	if len(buf) == 0 {
+		if e.setDefault != nil {
+			e.setDefault()
+		}
+		return nil
+	}
+	return decodeItem(e.item, buf, s)
+}
+
+// OmitDefaults returns an Encoding that encodes the same as enc, except that the TrailingDefault
+// items at the end of enc are left off while they hold their default values. Only a run of
+// TrailingDefault items at the very end can be omitted, since a reader finds them absent by
+// reaching the end of the buffer. The result decodes the same as enc.
+func (enc Encoding) OmitDefaults() Encoding {
+	i := len(enc.items)
+	for i > 0 {
+		if t, ok := enc.items[i-1].(trailing); !ok || t.isDefault == nil {
+			break
+		}
+		i--
+	}
+	if i == len(enc.items) {
+		return enc
+	}
+	items := append(enc.items[:i:i], omitDefaults{items: enc.items[i:], offset: new(int)})
+	return Encoding{items: items}
+}
+
+// The TrailingDefault items at the end of an Encoding, leaving off those that are default.
+type omitDefaults struct {
+	items []Item
+	// The offset of the first item, so that the rest can be given theirs.
+	offset *int
+}
+
+// Returns the items that need to be encoded.
+func (e omitDefaults) kept() []Item {
+	n := len(e.items)
+	for n > 0 && e.items[n-1].(trailing).isDefault() {
+		n--
+	}
+	return e.items[:n]
+}
+func (e omitDefaults) setOffset(offset int) {
+	*e.offset = offset
+	// The rest are given theirs as they're reached, since that depends on which are kept.
+	setOffset(e.items[0], offset)
+}
+func (e omitDefaults) Encode(buf []byte) {
+	i := 0
+	for _, item := range e.kept() {
+		setOffset(item, *e.offset+i)
+		size := item.Size()
+		item.Encode(buf[i : i+size])
+		i += size
+	}
+}
+func (e omitDefaults) Size() int {
+	size := 0
+	for _, item := range e.kept() {
+		setOffset(item, *e.offset+size)
+		size += item.Size()
+	}
+	return size
+}
+func (e omitDefaults) kind() string {
+	return "OmitDefaults"
+}
+func (e omitDefaults) withByteOrder(order binary.ByteOrder) Item {
+	return omitDefaults{items: itemsWithByteOrder(e.items, order), offset: new(int)}
+}
+func (e omitDefaults) dependsOnEarlier() bool {
+	return anyDependsOnEarlier(e.items)
+}
+func (e omitDefaults) skip(buf []byte) (int, error) {
+	i := 0
+	for _, item := range e.items {
+		setOffset(item, *e.offset+i)
+		n, err := skipItem(item, buf[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	return i, nil
+}
+func (e omitDefaults) sizeBounds() (int, int) {
+	return sizeBounds(Group(e.items...))
+}
+func (e omitDefaults) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e omitDefaults) decodeLimited(buf []byte, s *decodeState) error {
+	i := 0
+	for _, item := range e.items {
+		setOffset(item, *e.offset+i)
+		err := decodeItem(item, buf[i:], s)
+		if err != nil {
+			return err
+		}
+		i = minInt(i+item.Size(), len(buf))
+	}
+	return nil
+}
+
+// Capture all of the remaining bytes in the buffer into v on decode, and write v back verbatim on
+// encode.
+//
+// Placed at the end of an Encoding after its Trailing fields, this lets a reader round-trip data
+// from a newer writer that appended fields the reader doesn't know about: the unknown fields land
+// in v, and are re-emitted unchanged the next time the record is encoded.
+func Remainder(v *[]byte) Item {
+	return remainder{v}
+}
+
+type remainder struct{ v *[]byte }
+
+func (e remainder) Encode(buf []byte) {
+	copy(buf, *e.v)
+}
+func (e remainder) Size() int {
+	return len(*e.v)
+}
+func (e remainder) kind() string {
+	return "Remainder"
+}
+func (e remainder) skip(buf []byte) (int, error) {
+	return len(buf), nil
+}
+func (e remainder) sizeBounds() (min, max int) {
+	return 0, -1
+}
+func (e remainder) Decode(buf []byte) error {
+	*e.v = append((*e.v)[:0], buf...)
+	return nil
+}
@@ -0,0 +1,121 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrailing(t *testing.T) {
+	a := uint16(5)
+	old := New(FixedUint16(&a)).Encode()
+
+	var a2 uint16
+	var b2 uint32
+	var c2 bool
+	enc := New(
+		FixedUint16(&a2),
+		Trailing(FixedUint32(&b2), func() { b2 = 42 }),
+		Trailing(Bool(&c2), nil),
+	)
+	require.NoError(t, enc.Decode(old))
+	require.Equal(t, uint16(5), a2)
+	require.Equal(t, uint32(42), b2)
+	require.Equal(t, false, c2)
+
+	b2 = 7
+	c2 = true
+	current := enc.Encode()
+	require.Equal(t, []byte{0x00, 0x05, 0x00, 0x00, 0x00, 0x07, 0x01}, current)
+
+	a2, b2, c2 = 0, 0, false
+	require.NoError(t, enc.Decode(current))
+	require.Equal(t, uint16(5), a2)
+	require.Equal(t, uint32(7), b2)
+	require.Equal(t, true, c2)
+
+	// A field that's only partially present is still an error.
+	require.ErrorIs(t, enc.Decode(current[:4]), io.ErrUnexpectedEOF)
+}
+
+func TestRemainder(t *testing.T) {
+	a := uint16(5)
+	b := uint32(6)
+	c := true
+	newer := New(FixedUint16(&a), Trailing(FixedUint32(&b), nil), Trailing(Bool(&c), nil)).Encode()
+
+	var a2 uint16
+	var b2 uint32
+	var unknown []byte
+	older := New(FixedUint16(&a2), Trailing(FixedUint32(&b2), nil), Remainder(&unknown))
+	require.NoError(t, older.Decode(newer))
+	require.Equal(t, uint16(5), a2)
+	require.Equal(t, uint32(6), b2)
+	require.Equal(t, []byte{0x01}, unknown)
+
+	a2 = 9
+	var c3 bool
+	require.NoError(t, New(FixedUint16(&a), Trailing(FixedUint32(&b), nil), Trailing(Bool(&c3), nil)).Decode(older.Encode()))
+	require.Equal(t, uint16(9), a)
+	require.Equal(t, true, c3)
+}
+
+func TestOmitDefaults(t *testing.T) {
+	var a uint16
+	var b uint32
+	var c bool
+	enc := New(
+		FixedUint16(&a),
+		TrailingDefault(FixedUint32(&b), func() bool { return b == 42 }, func() { b = 42 }),
+		TrailingDefault(Bool(&c), func() bool { return !c }, func() { c = false }),
+	)
+	omit := enc.OmitDefaults()
+
+	check := func(expected []byte) {
+		a2, b2, c2 := a, b, c
+		require.Equal(t, expected, omit.Encode())
+		a, b, c = 0, 0, true
+		require.NoError(t, omit.Decode(expected))
+		require.Equal(t, a2, a)
+		require.Equal(t, b2, b)
+		require.Equal(t, c2, c)
+	}
+
+	a, b, c = 5, 42, false
+	require.Equal(t, []byte{0x00, 0x05, 0x00, 0x00, 0x00, 0x2a, 0x00}, enc.Encode())
+	check([]byte{0x00, 0x05})
+	a, b, c = 5, 7, false
+	check([]byte{0x00, 0x05, 0x00, 0x00, 0x00, 0x07})
+	// A default field can't be left off before one that isn't.
+	a, b, c = 5, 42, true
+	check([]byte{0x00, 0x05, 0x00, 0x00, 0x00, 0x2a, 0x01})
+
+	// Nothing can be omitted if the encoding doesn't end in TrailingDefault items.
+	var rest []byte
+	enc = New(
+		TrailingDefault(Bool(&c), func() bool { return !c }, func() { c = false }),
+		Remainder(&rest),
+	)
+	c = false
+	require.Equal(t, []byte{0x00}, enc.OmitDefaults().Encode())
+
+	// WithByteOrder applies the same before or after OmitDefaults.
+	enc = New(
+		FixedUint16(&a),
+		TrailingDefault(FixedUint32(&b), func() bool { return b == 42 }, func() { b = 42 }),
+	)
+	a, b = 5, 0x05060708
+	le := []byte{0x05, 0x00, 0x08, 0x07, 0x06, 0x05}
+	require.Equal(t, le, enc.OmitDefaults().WithByteOrder(binary.LittleEndian).Encode())
+	require.Equal(t, le, enc.WithByteOrder(binary.LittleEndian).OmitDefaults().Encode())
+
+	// Items after omitted ones are aligned from the start of the encoding.
+	enc = New(
+		Bool(&c),
+		TrailingDefault(Align(4), func() bool { return false }, nil),
+		TrailingDefault(FixedUint16(&a), func() bool { return a == 0 }, func() { a = 0 }),
+	)
+	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x05}, enc.OmitDefaults().Encode())
+}
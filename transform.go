@@ -0,0 +1,70 @@
+package encode
+
+import "encoding/binary"
+
+// Encode item, which is bound to a wire-format value, calling toWire first to fill it from the
+// in-memory value. On decode, item is decoded and then fromWire is called to convert the wire
+// value back into the in-memory one. fromWire may return an error to reject a wire value that has
+// no in-memory equivalent.
+//
+// This keeps conversions inside the Encoding rather than scattered around its callers:
+//
+//   var wire uint8
+//   encode.Transform(
+//   	encode.Byte(&wire),
+//   	func() { wire = colorToByte[e.color] },
+//   	func() error {
+//   		var ok bool
+//   		e.color, ok = byteToColor[wire]
+//   		if !ok {
+//   			return errUnknownColor
+//   		}
+//   		return nil
+//   	},
+//   )
+func Transform(item Item, toWire func(), fromWire func() error) Item {
+	return transform{item: item, toWire: toWire, fromWire: fromWire}
+}
+
+type transform struct {
+	item     Item
+	toWire   func()
+	fromWire func() error
+}
+
+func (e transform) setOffset(offset int) {
+	setOffset(e.item, offset)
+}
+func (e transform) Encode(buf []byte) {
+	e.toWire()
+	e.item.Encode(buf)
+}
+func (e transform) Size() int {
+	e.toWire()
+	return e.item.Size()
+}
+func (e transform) kind() string {
+	return "Transform"
+}
+func (e transform) withByteOrder(order binary.ByteOrder) Item {
+	return transform{item: withByteOrder(e.item, order), toWire: e.toWire, fromWire: e.fromWire}
+}
+func (e transform) dependsOnEarlier() bool {
+	return dependsOnEarlier(e.item)
+}
+func (e transform) skip(buf []byte) (int, error) {
+	return skipItem(e.item, buf)
+}
+func (e transform) sizeBounds() (min, max int) {
+	return sizeBounds(e.item)
+}
+func (e transform) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e transform) decodeLimited(buf []byte, s *decodeState) error {
+	err := decodeItem(e.item, buf, s)
+	if err != nil {
+		return err
+	}
+	return e.fromWire()
+}
@@ -0,0 +1,42 @@
+package encode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform(t *testing.T) {
+	errUnknown := errors.New("unknown")
+	colors := []string{"red", "green", "blue"}
+	transformed := func(color *string) Item {
+		var wire uint8
+		return Transform(
+			Byte(&wire),
+			func() {
+				for i := range colors {
+					if colors[i] == *color {
+						wire = uint8(i)
+					}
+				}
+			},
+			func() error {
+				if int(wire) >= len(colors) {
+					return errUnknown
+				}
+				*color = colors[wire]
+				return nil
+			},
+		)
+	}
+
+	color := "blue"
+	buf := New(transformed(&color)).Encode()
+	require.Equal(t, []byte{0x02}, buf)
+
+	var color2 string
+	require.NoError(t, New(transformed(&color2)).Decode(buf))
+	require.Equal(t, "blue", color2)
+	require.ErrorIs(t, New(transformed(&color2)).Decode([]byte{0x03}), errUnknown)
+}
@@ -0,0 +1,177 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+var ErrInvalidUTF16 = NewInvalidValueError("encode: invalid UTF-16")
+
+// Options for UTF16String.
+type UTF16Flags int
+
+const (
+	// Start with a byte order mark. On decode, the byte order mark is required, and decides the
+	// byte order instead of the order given to UTF16String.
+	UTF16BOM UTF16Flags = 1 << iota
+	// End with a 0x0000 code unit instead of starting with a length. The string may not contain
+	// U+0000.
+	UTF16NullTerminated
+)
+
+// Encode v as UTF-16 in the given byte order, as used by Windows and protocols like SMB. By
+// default the code units are preceded by a uvarint of their length in bytes, including the byte
+// order mark if there is one. flags change this as described on UTF16BOM and UTF16NullTerminated.
+//
+//   "hi", binary.LittleEndian, UTF16NullTerminated
+//   68 00 69 00 00 00
+//
+// Invalid UTF-8 in v is encoded as U+FFFD. Panics on encode if v contains U+0000 and
+// UTF16NullTerminated is set. Decode returns ErrInvalidUTF16 if there is an unpaired surrogate, an
+// odd number of bytes, or a missing byte order mark.
+func UTF16String(v *string, order binary.ByteOrder, flags UTF16Flags) Item {
+	return utf16String{v: v, order: order, flags: flags}
+}
+
+type utf16String struct {
+	v     *string
+	order binary.ByteOrder
+	flags UTF16Flags
+}
+
+const utf16BOM = 0xfeff
+
+func (e utf16String) Encode(buf []byte) {
+	if e.flags&UTF16NullTerminated != 0 && strings.IndexByte(*e.v, 0) >= 0 {
+		panic(fmt.Sprintf("UTF16String: %q contains U+0000, which can't be null-terminated", *e.v))
+	}
+	n := 0
+	if e.flags&UTF16NullTerminated == 0 {
+		n = binary.PutUvarint(buf, uint64(e.unitsSize()))
+	}
+	if e.flags&UTF16BOM != 0 {
+		e.order.PutUint16(buf[n:], utf16BOM)
+		n += 2
+	}
+	for _, r := range *e.v {
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			e.order.PutUint16(buf[n:], uint16(r1))
+			e.order.PutUint16(buf[n+2:], uint16(r2))
+			n += 4
+		} else {
+			e.order.PutUint16(buf[n:], uint16(r))
+			n += 2
+		}
+	}
+	if e.flags&UTF16NullTerminated != 0 {
+		e.order.PutUint16(buf[n:], 0)
+	}
+}
+
+// The number of bytes of code units, including the byte order mark but not the length or null
+// terminator.
+func (e utf16String) unitsSize() int {
+	n := 0
+	if e.flags&UTF16BOM != 0 {
+		n += 2
+	}
+	for _, r := range *e.v {
+		if r >= 0x10000 {
+			n += 4
+		} else {
+			n += 2
+		}
+	}
+	return n
+}
+func (e utf16String) Size() int {
+	n := e.unitsSize()
+	if e.flags&UTF16NullTerminated != 0 {
+		return n + 2
+	}
+	return uvarintSize(uint64(n)) + n
+}
+func (e utf16String) kind() string {
+	return "UTF16String"
+}
+func (e utf16String) sizeBounds() (min, max int) {
+	min = 1
+	if e.flags&UTF16NullTerminated != 0 {
+		min = 2
+	}
+	if e.flags&UTF16BOM != 0 {
+		min += 2
+	}
+	return min, -1
+}
+func (e utf16String) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e utf16String) decodeLimited(buf []byte, s *decodeState) error {
+	var b []byte
+	if e.flags&UTF16NullTerminated != 0 {
+		end := -1
+		for i := 0; i+1 < len(buf); i += 2 {
+			if buf[i] == 0 && buf[i+1] == 0 {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return ErrShortBuffer
+		}
+		err := s.checkLength(uint64(end))
+		if err != nil {
+			return err
+		}
+		b = buf[:end]
+	} else {
+		var err error
+		b, err = readLengthDelim(buf, s)
+		if err != nil {
+			return err
+		}
+	}
+	if len(b)%2 != 0 {
+		return ErrInvalidUTF16
+	}
+
+	order := e.order
+	if e.flags&UTF16BOM != 0 {
+		if len(b) < 2 {
+			return ErrInvalidUTF16
+		}
+		switch {
+		case binary.BigEndian.Uint16(b) == utf16BOM:
+			order = binary.BigEndian
+		case binary.LittleEndian.Uint16(b) == utf16BOM:
+			order = binary.LittleEndian
+		default:
+			return ErrInvalidUTF16
+		}
+		b = b[2:]
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(b) / 2)
+	for i := 0; i < len(b); i += 2 {
+		r := rune(order.Uint16(b[i:]))
+		if utf16.IsSurrogate(r) {
+			if i+3 >= len(b) {
+				return ErrInvalidUTF16
+			}
+			r = utf16.DecodeRune(r, rune(order.Uint16(b[i+2:])))
+			if r == unicode.ReplacementChar {
+				return ErrInvalidUTF16
+			}
+			i += 2
+		}
+		sb.WriteRune(r)
+	}
+	*e.v = sb.String()
+	return nil
+}
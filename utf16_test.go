@@ -0,0 +1,49 @@
+package encode
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUTF16String(t *testing.T) {
+	check := func(s string, order binary.ByteOrder, flags UTF16Flags, expected []byte) {
+		item := UTF16String(&s, order, flags)
+		require.Equal(t, expected, New(item).Encode())
+		require.Equal(t, len(expected), item.Size())
+		var s2 string
+		require.NoError(t, New(UTF16String(&s2, order, flags)).Decode(expected))
+		require.Equal(t, s, s2)
+	}
+	check("hi", binary.LittleEndian, 0, []byte{0x04, 'h', 0x00, 'i', 0x00})
+	check("hi", binary.BigEndian, 0, []byte{0x04, 0x00, 'h', 0x00, 'i'})
+	check("hi", binary.LittleEndian, UTF16NullTerminated, []byte{'h', 0x00, 'i', 0x00, 0x00, 0x00})
+	check("hi", binary.BigEndian, UTF16BOM, []byte{0x06, 0xfe, 0xff, 0x00, 'h', 0x00, 'i'})
+	check(
+		"a😀",
+		binary.LittleEndian,
+		UTF16BOM|UTF16NullTerminated,
+		[]byte{0xff, 0xfe, 'a', 0x00, 0x3d, 0xd8, 0x00, 0xde, 0x00, 0x00},
+	)
+	check("", binary.LittleEndian, UTF16NullTerminated, []byte{0x00, 0x00})
+
+	var s string
+	// The byte order mark overrides the given order.
+	require.NoError(
+		t,
+		New(UTF16String(&s, binary.BigEndian, UTF16BOM)).Decode([]byte{0x04, 0xff, 0xfe, 'h', 0x00}),
+	)
+	require.Equal(t, "h", s)
+
+	le := func(flags UTF16Flags) Encoding { return New(UTF16String(&s, binary.LittleEndian, flags)) }
+	require.ErrorIs(t, le(UTF16BOM).Decode([]byte{0x02, 'h', 0x00}), ErrInvalidUTF16)
+	require.ErrorIs(t, le(0).Decode([]byte{0x03, 'h', 0x00, 'i'}), ErrInvalidUTF16)
+	require.ErrorIs(t, le(0).Decode([]byte{0x02, 0x3d, 0xd8}), ErrInvalidUTF16)
+	require.ErrorIs(t, le(0).Decode([]byte{0x04, 0x3d, 0xd8, 'h', 0x00}), ErrInvalidUTF16)
+	require.ErrorIs(t, le(UTF16NullTerminated).Decode([]byte{'h', 0x00, 0x00}), io.ErrUnexpectedEOF)
+
+	s = "a\x00b"
+	require.Panics(t, func() { le(UTF16NullTerminated).Encode() })
+}
@@ -0,0 +1,36 @@
+package encode
+
+// Takes no space in the encoding, but on decode calls check and fails with its error, if any. This
+// checks invariants that the items before it must satisfy once they're decoded, for example
+//
+//   encode.Validate(func() error {
+//   	if int(e.count) != len(e.items) {
+//   		return fmt.Errorf("count %d doesn't match %d items", e.count, len(e.items))
+//   	}
+//   	return nil
+//   })
+//
+// so that Decode reports a bad record rather than producing one. check isn't called on encode, or
+// when Validate is skipped by DecodeFields.
+func Validate(check func() error) Item {
+	return validate{check}
+}
+
+type validate struct{ check func() error }
+
+func (e validate) Encode(buf []byte) {}
+func (e validate) Size() int {
+	return 0
+}
+func (e validate) kind() string {
+	return "Validate"
+}
+func (e validate) fixedSize() int {
+	return 0
+}
+func (e validate) skip(buf []byte) (int, error) {
+	return 0, nil
+}
+func (e validate) Decode(buf []byte) error {
+	return e.check()
+}
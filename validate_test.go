@@ -0,0 +1,37 @@
+package encode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	errMismatch := errors.New("count mismatch")
+	var count uint64
+	var items []uint64
+	var after bool
+	enc := New(
+		Uvarint64(&count),
+		DeltaUvarints(&items),
+		Validate(func() error {
+			if int(count) != len(items) {
+				return errMismatch
+			}
+			return nil
+		}),
+		Bool(&after),
+	)
+
+	count, items, after = 2, []uint64{1, 5}, true
+	buf := enc.Encode()
+	require.Equal(t, New(Uvarint64(&count), DeltaUvarints(&items), Bool(&after)).Encode(), buf)
+	count, items, after = 0, nil, false
+	require.NoError(t, enc.Decode(buf))
+	require.True(t, after)
+
+	count, items = 3, []uint64{1, 5}
+	require.ErrorIs(t, enc.Decode(enc.Encode()), errMismatch)
+	require.NoError(t, enc.DecodeFields(enc.Encode(), 3))
+}
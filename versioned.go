@@ -0,0 +1,125 @@
+package encode
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+var ErrUnknownVersion = NewInvalidValueError("encode: unknown version")
+
+// Encode *version as a uvarint, followed by versions[*version]. On decode, the version is read
+// first and then used to choose which item decodes the rest, so that a format can change over time
+// while still reading everything written by older versions. Use Group to give a version several
+// items.
+//
+// Decode returns ErrUnknownVersion if the version read isn't in versions. Encode panics if
+// *version isn't in versions.
+func Versioned(version *uint64, versions map[uint64]Item) Item {
+	return versioned{version: version, versions: versions, offset: new(int)}
+}
+
+type versioned struct {
+	version  *uint64
+	versions map[uint64]Item
+	// Where the version is in the encoding, as of the last call to setOffset.
+	offset *int
+}
+
+func (e versioned) item() Item {
+	item, ok := e.versions[*e.version]
+	if !ok {
+		panic(fmt.Sprintf("encode: no item for version %d", *e.version))
+	}
+	return item
+}
+func (e versioned) setOffset(offset int) {
+	*e.offset = offset
+	// On decode, the item isn't known until the version is read, so it's given its offset then.
+	if item, ok := e.versions[*e.version]; ok {
+		setOffset(item, offset+uvarintSize(*e.version))
+	}
+}
+func (e versioned) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, *e.version)
+	e.item().Encode(buf[n:])
+}
+func (e versioned) Size() int {
+	return uvarintSize(*e.version) + e.item().Size()
+}
+func (e versioned) kind() string {
+	return "Versioned"
+}
+func (e versioned) withByteOrder(order binary.ByteOrder) Item {
+	versions := make(map[uint64]Item, len(e.versions))
+	for version, item := range e.versions {
+		versions[version] = withByteOrder(item, order)
+	}
+	return Versioned(e.version, versions)
+}
+func (e versioned) sizeBounds() (int, int) {
+	min, max := -1, 0
+	for version, item := range e.versions {
+		itemMin, itemMax := sizeBounds(item)
+		min, max = unionSizeBounds(
+			min,
+			max,
+			uvarintSize(version)+itemMin,
+			addMaxSize(uvarintSize(version), itemMax),
+		)
+	}
+	if min < 0 {
+		return 0, 0
+	}
+	return min, max
+}
+func (e versioned) dependsOnEarlier() bool {
+	for _, item := range e.versions {
+		if dependsOnEarlier(item) {
+			return true
+		}
+	}
+	return false
+}
+func (e versioned) skip(buf []byte) (int, error) {
+	version, n := binary.Uvarint(buf)
+	if n == 0 {
+		return 0, ErrShortBuffer
+	}
+	if n < 0 {
+		return 0, ErrVarintOverflow
+	}
+	item, ok := e.versions[version]
+	if !ok {
+		return 0, ErrUnknownVersion
+	}
+	setOffset(item, *e.offset+n)
+	m, err := skipItem(item, buf[n:])
+	if err != nil {
+		return 0, err
+	}
+	return n + m, nil
+}
+func (e versioned) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e versioned) decodeLimited(buf []byte, s *decodeState) error {
+	err := s.enter()
+	if err != nil {
+		return err
+	}
+	defer s.leave()
+	version, n := binary.Uvarint(buf)
+	if n == 0 {
+		return ErrShortBuffer
+	}
+	if n < 0 {
+		return ErrVarintOverflow
+	}
+	item, ok := e.versions[version]
+	if !ok {
+		return ErrUnknownVersion
+	}
+	*e.version = version
+	setOffset(item, *e.offset+n)
+	return decodeItem(item, buf[n:], s)
+}
@@ -0,0 +1,42 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersioned(t *testing.T) {
+	type record struct {
+		a uint16
+		b uint32
+	}
+	encoding := func(version *uint64, r *record) Encoding {
+		return New(
+			Versioned(version, map[uint64]Item{
+				1: FixedUint16(&r.a),
+				2: Group(FixedUint16(&r.a), FixedUint32(&r.b)),
+			}),
+			Const([]byte{0xEE}),
+		)
+	}
+
+	v1 := uint64(1)
+	old := record{a: 7, b: 8}
+	b := encoding(&v1, &old).Encode()
+	require.Equal(t, []byte{0x01, 0x00, 0x07, 0xEE}, b)
+
+	var version uint64
+	var r record
+	require.NoError(t, encoding(&version, &r).Decode(b))
+	require.Equal(t, uint64(1), version)
+	require.Equal(t, record{a: 7}, r)
+
+	v2 := uint64(2)
+	b = encoding(&v2, &old).Encode()
+	require.NoError(t, encoding(&version, &r).Decode(b))
+	require.Equal(t, uint64(2), version)
+	require.Equal(t, old, r)
+
+	require.ErrorIs(t, encoding(&version, &r).Decode([]byte{0x03, 0x00}), ErrUnknownVersion)
+}
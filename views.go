@@ -0,0 +1,90 @@
+package encode
+
+import (
+	"encoding/binary"
+	"unsafe"
+)
+
+// Encoded the same as LengthDelimBytes, but on decode *v aliases the decoded buffer rather than
+// being a copy of it. This avoids an allocation and copy per field, but *v is only valid for as
+// long as the decoded buffer is, and sees any later changes made to it.
+//
+// Appending to *v never writes into the rest of the decoded buffer.
+func BytesView(v *[]byte) Item {
+	return bytesView{v}
+}
+
+type bytesView struct{ v *[]byte }
+
+func (e bytesView) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	copy(buf[n:], *e.v)
+}
+func (e bytesView) Size() int {
+	return uvarintSize(uint64(len(*e.v))) + len(*e.v)
+}
+func (e bytesView) kind() string {
+	return "BytesView"
+}
+func (e bytesView) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e bytesView) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e bytesView) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e bytesView) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
+	}
+	*e.v = b
+	return nil
+}
+
+// Encoded the same as LengthDelimString, but on decode *v aliases the decoded buffer rather than
+// being a copy of it.
+//
+// This is unsafe: Go assumes that strings are immutable, so the decoded buffer must never be
+// modified while *v is in use, or programs may misbehave in ways that are very hard to debug.
+// Prefer BytesView unless the string is known not to outlive an immutable buffer, for example one
+// that is read-only mmap'd.
+func UnsafeStringView(v *string) Item {
+	return unsafeStringView{v}
+}
+
+type unsafeStringView struct{ v *string }
+
+func (e unsafeStringView) Encode(buf []byte) {
+	n := binary.PutUvarint(buf, uint64(len(*e.v)))
+	copy(buf[n:], *e.v)
+}
+func (e unsafeStringView) Size() int {
+	return uvarintSize(uint64(len(*e.v))) + len(*e.v)
+}
+func (e unsafeStringView) kind() string {
+	return "UnsafeStringView"
+}
+func (e unsafeStringView) sizeBounds() (min, max int) {
+	return 1, -1
+}
+func (e unsafeStringView) skip(buf []byte) (int, error) {
+	return skipLengthDelim(buf)
+}
+func (e unsafeStringView) Decode(buf []byte) error {
+	return e.decodeLimited(buf, nil)
+}
+func (e unsafeStringView) decodeLimited(buf []byte, s *decodeState) error {
+	b, err := readLengthDelim(buf, s)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		*e.v = ""
+		return nil
+	}
+	*e.v = unsafe.String(&b[0], len(b))
+	return nil
+}
@@ -0,0 +1,31 @@
+package encode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestViews(t *testing.T) {
+	b := []byte("hello")
+	s := "world"
+	empty := ""
+	buf := New(BytesView(&b), UnsafeStringView(&s), UnsafeStringView(&empty)).Encode()
+	copied := New(LengthDelimBytes(&b), LengthDelimString(&s), LengthDelimString(&empty)).Encode()
+	require.Equal(t, copied, buf)
+
+	var b2 []byte
+	var s2 string
+	empty2 := "x"
+	enc := New(BytesView(&b2), UnsafeStringView(&s2), UnsafeStringView(&empty2))
+	require.NoError(t, enc.Decode(buf))
+	require.Equal(t, b, b2)
+	require.Equal(t, s, s2)
+	require.Equal(t, "", empty2)
+
+	// b2 aliases buf, but appending to it must not clobber the rest of buf.
+	buf[1] = 'j'
+	require.Equal(t, []byte("jello"), b2)
+	_ = append(b2, '!')
+	require.Equal(t, "world", s2)
+}
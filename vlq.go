@@ -0,0 +1,74 @@
+package encode
+
+import "math/bits"
+
+// Encode v as a variable-length quantity, as used by MIDI files and some font formats. This is
+// like Uvarint64, except that the groups of 7 bits are written most significant first:
+//
+//   0      00
+//   127    7f
+//   128    81 00
+//   16384  81 80 00
+//
+// Decode returns ErrInvalidVarint if the first byte is 0x80, which would be a redundant leading
+// zero group, and ErrVarintOverflow if the value doesn't fit in 64 bits.
+func VLQ(v *uint64) Item {
+	return vlq{v}
+}
+
+type vlq struct{ v *uint64 }
+
+func (e vlq) Encode(buf []byte) {
+	n := e.Size()
+	x := *e.v
+	for i := n - 1; i >= 0; i-- {
+		buf[i] = byte(x & 0x7f)
+		if i != n-1 {
+			buf[i] |= 0x80
+		}
+		x >>= 7
+	}
+}
+func (e vlq) Size() int {
+	l := bits.Len64(*e.v)
+	if l == 0 {
+		return 1
+	}
+	return (l + 6) / 7
+}
+func (e vlq) kind() string {
+	return "VLQ"
+}
+func (e vlq) sizeBounds() (min, max int) {
+	return 1, 10
+}
+func (e vlq) skip(buf []byte) (int, error) {
+	for i := 0; i < len(buf) && i < 10; i++ {
+		if buf[i]&0x80 == 0 {
+			return i + 1, nil
+		}
+	}
+	if len(buf) < 10 {
+		return 0, ErrShortBuffer
+	}
+	return 0, ErrVarintOverflow
+}
+func (e vlq) Decode(buf []byte) error {
+	if len(buf) > 0 && buf[0] == 0x80 {
+		return ErrInvalidVarint
+	}
+	var x uint64
+	for i := 0; ; i++ {
+		if i >= len(buf) {
+			return ErrShortBuffer
+		}
+		if x>>57 != 0 {
+			return ErrVarintOverflow
+		}
+		x = x<<7 | uint64(buf[i]&0x7f)
+		if buf[i]&0x80 == 0 {
+			*e.v = x
+			return nil
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package encode
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVLQ(t *testing.T) {
+	check := func(x uint64, expected []byte) {
+		require.Equal(t, expected, New(VLQ(&x)).Encode())
+		var x2 uint64
+		require.NoError(t, New(VLQ(&x2)).Decode(expected))
+		require.Equal(t, x, x2)
+	}
+	// Examples from the Standard MIDI File specification.
+	check(0x00, []byte{0x00})
+	check(0x40, []byte{0x40})
+	check(0x7f, []byte{0x7f})
+	check(0x80, []byte{0x81, 0x00})
+	check(0x2000, []byte{0xc0, 0x00})
+	check(0x3fff, []byte{0xff, 0x7f})
+	check(0x4000, []byte{0x81, 0x80, 0x00})
+	check(0x1fffff, []byte{0xff, 0xff, 0x7f})
+	check(0x200000, []byte{0x81, 0x80, 0x80, 0x00})
+	check(0x0fffffff, []byte{0xff, 0xff, 0xff, 0x7f})
+	check(math.MaxUint64, []byte{0x81, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f})
+
+	var x uint64
+	require.ErrorIs(t, New(VLQ(&x)).Decode([]byte{0x81, 0x80}), io.ErrUnexpectedEOF)
+	require.ErrorIs(t, New(VLQ(&x)).Decode([]byte{0x80, 0x01}), ErrInvalidVarint)
+	require.ErrorIs(
+		t,
+		New(VLQ(&x)).Decode([]byte{0x82, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}),
+		ErrOverflowVarint,
+	)
+}